@@ -37,6 +37,10 @@ var (
 
 	commands = []string{
 
+		// node
+
+		"id ",
+
 		// feeds
 
 		"share feed ",
@@ -64,6 +68,59 @@ var (
 
 		"udp address ",
 
+		// unix
+
+		"unix connect ",
+		"unix disconnect ",
+
+		"unix subscribe ",
+		"unix unsubscribe ",
+
+		"unix address ",
+
+		// ws
+
+		"ws connect ",
+		"ws disconnect ",
+
+		"ws subscribe ",
+		"ws unsubscribe ",
+
+		"ws address ",
+
+		// access control
+
+		"peers allow ",
+		"peers deny ",
+		"peers remove ",
+		"peers list ",
+
+		// subscription policy
+
+		"policy allow ",
+		"policy disallow ",
+		"policy list ",
+		"policy reload ",
+
+		// persistent peers
+
+		"persistent add ",
+		"persistent remove ",
+		"persistent list ",
+
+		// bandwidth
+
+		"bandwidth ",
+		"bandwidth node ",
+		"bandwidth conn ",
+		"bandwidth of ",
+
+		// retention
+
+		"retention ",
+		"retention set ",
+		"retention apply ",
+
 		// all connections
 
 		"connections ",
@@ -74,11 +131,16 @@ var (
 		"root info ",
 		"root tree ",
 		"last root ",
+		"verify chain ",
 
 		// stat
 
 		"stat ",
 
+		// filling
+
+		"filling ",
+
 		// help
 
 		"help",
@@ -260,6 +322,8 @@ func (c *client) mapping() map[string]func(in []string) (err error) {
 	}
 
 	c.m = map[string]func(in []string) (err error){
+		"id": c.id,
+
 		"share feed":       c.share,
 		"don't share feed": c.dontShare,
 		"list feeds":       c.listFeeds,
@@ -277,6 +341,41 @@ func (c *client) mapping() map[string]func(in []string) (err error) {
 		"udp unsubscribe": c.udpUnsubscribe,
 		"udp address":     c.udpAddress,
 
+		"unix connect":     c.unixConnect,
+		"unix disconnect":  c.unixDisconnet,
+		"unix subscribe":   c.unixSubscribe,
+		"unix unsubscribe": c.unixUnsubscribe,
+		"unix address":     c.unixAddress,
+
+		"ws connect":     c.wsConnect,
+		"ws disconnect":  c.wsDisconnet,
+		"ws subscribe":   c.wsSubscribe,
+		"ws unsubscribe": c.wsUnsubscribe,
+		"ws address":     c.wsAddress,
+
+		"peers allow":  c.peersAllow,
+		"peers deny":   c.peersDeny,
+		"peers remove": c.peersRemove,
+		"peers list":   c.peersList,
+
+		"policy allow":    c.policyAllow,
+		"policy disallow": c.policyDisallow,
+		"policy list":     c.policyList,
+		"policy reload":   c.policyReload,
+
+		"persistent add":    c.persistentAdd,
+		"persistent remove": c.persistentRemove,
+		"persistent list":   c.persistentList,
+
+		"bandwidth":      c.bandwidth,
+		"bandwidth node": c.bandwidthNode,
+		"bandwidth conn": c.bandwidthConn,
+		"bandwidth of":   c.bandwidthOf,
+
+		"retention":       c.retention,
+		"retention set":   c.retentionSet,
+		"retention apply": c.retentionApply,
+
 		"connections":         c.connections,
 		"connections of feed": c.connectionsOfFeed,
 
@@ -284,8 +383,12 @@ func (c *client) mapping() map[string]func(in []string) (err error) {
 		"root tree": c.rootTree,
 		"last root": c.lastRoot,
 
+		"verify chain": c.verifyChain,
+
 		"stat": c.stat,
 
+		"filling": c.filling,
+
 		"help": c.help,
 
 		"quit": c.quit,
@@ -429,6 +532,26 @@ func (c *client) argsRoot(in []string) (rs node.RootSelector, err error) {
 
 }
 
+func (c *client) argsHead(in []string) (hs node.HeadSelector, err error) {
+
+	const expected = "expected public key and nonce"
+
+	switch len(in) {
+	case 0, 1:
+		err = errors.New("missing arguments: " + expected)
+	case 2:
+		if hs.Feed, err = pubKeyFromHex(in[0]); err != nil {
+			return
+		}
+		hs.Nonce, err = strconv.ParseUint(in[1], 10, 64)
+	default:
+		err = errors.New("too many arguments: " + expected)
+	}
+
+	return
+
+}
+
 func (c *client) argsNo(in []string) (err error) {
 	if len(in) != 0 {
 		err = errors.New("unexpected arguments, expected nothing")
@@ -436,6 +559,22 @@ func (c *client) argsNo(in []string) (err error) {
 	return
 }
 
+//
+// node
+//
+
+func (c *client) id(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var id cipher.PubKey
+	if id, err = c.r.Node().ID(); err != nil {
+		return
+	}
+	fmt.Fprintln(out, " ", id.Hex())
+	return
+}
+
 //
 // feeds
 //
@@ -595,6 +734,443 @@ func (c *client) udpAddress(in []string) (err error) {
 	return
 }
 
+//
+// unix
+//
+
+func (c *client) unixConnect(in []string) (err error) {
+	var address string
+	if address, err = c.argsAddress(in); err != nil {
+		return
+	}
+	return c.r.Unix().Connect(address)
+}
+
+func (c *client) unixDisconnet(in []string) (err error) {
+	var address string
+	if address, err = c.argsAddress(in); err != nil {
+		return
+	}
+	return c.r.Unix().Disconnect(address)
+}
+
+func (c *client) unixSubscribe(in []string) (err error) {
+	var cf node.ConnFeed
+	if cf, err = c.argsConnFeed(in); err != nil {
+		return
+	}
+	return c.r.Unix().Subscribe(cf.Address, cf.Feed)
+}
+
+func (c *client) unixUnsubscribe(in []string) (err error) {
+	var cf node.ConnFeed
+	if cf, err = c.argsConnFeed(in); err != nil {
+		return
+	}
+	return c.r.Unix().Unsubscribe(cf.Address, cf.Feed)
+}
+
+func (c *client) unixAddress(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var address string
+	if address, err = c.r.Unix().Address(); err != nil {
+		return
+	}
+	if address == "" {
+		fmt.Fprintln(out, "  doesn't listen")
+		return
+	}
+	fmt.Fprintln(out, " "+address)
+	return
+}
+
+//
+// ws
+//
+
+func (c *client) wsConnect(in []string) (err error) {
+	var address string
+	if address, err = c.argsAddress(in); err != nil {
+		return
+	}
+	return c.r.WS().Connect(address)
+}
+
+func (c *client) wsDisconnet(in []string) (err error) {
+	var address string
+	if address, err = c.argsAddress(in); err != nil {
+		return
+	}
+	return c.r.WS().Disconnect(address)
+}
+
+func (c *client) wsSubscribe(in []string) (err error) {
+	var cf node.ConnFeed
+	if cf, err = c.argsConnFeed(in); err != nil {
+		return
+	}
+	return c.r.WS().Subscribe(cf.Address, cf.Feed)
+}
+
+func (c *client) wsUnsubscribe(in []string) (err error) {
+	var cf node.ConnFeed
+	if cf, err = c.argsConnFeed(in); err != nil {
+		return
+	}
+	return c.r.WS().Unsubscribe(cf.Address, cf.Feed)
+}
+
+func (c *client) wsAddress(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var address string
+	if address, err = c.r.WS().Address(); err != nil {
+		return
+	}
+	if address == "" {
+		fmt.Fprintln(out, "  doesn't listen")
+		return
+	}
+	fmt.Fprintln(out, " "+address)
+	return
+}
+
+//
+// access control
+//
+
+func (c *client) argsRule(in []string) (rule string, err error) {
+	return c.argsOne(in, "public key or CIDR")
+}
+
+func (c *client) peersAllow(in []string) (err error) {
+	var rule string
+	if rule, err = c.argsRule(in); err != nil {
+		return
+	}
+	return c.r.Peers().Allow(rule)
+}
+
+func (c *client) peersDeny(in []string) (err error) {
+	var rule string
+	if rule, err = c.argsRule(in); err != nil {
+		return
+	}
+	return c.r.Peers().Deny(rule)
+}
+
+func (c *client) peersRemove(in []string) (err error) {
+	var rule string
+	if rule, err = c.argsRule(in); err != nil {
+		return
+	}
+	return c.r.Peers().Remove(rule)
+}
+
+func printAccessList(name string, al node.AccessList) {
+	if al.IsBlank() == true {
+		fmt.Fprintln(out, " ", name+": (empty)")
+		return
+	}
+	fmt.Fprintln(out, " ", name+":")
+	for _, pk := range al.Peers {
+		fmt.Fprintln(out, "    -", pk.Hex())
+	}
+	for _, n := range al.Nets {
+		fmt.Fprintln(out, "    -", n)
+	}
+}
+
+func (c *client) peersList(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var allow, deny node.AccessList
+	if allow, deny, err = c.r.Peers().List(); err != nil {
+		return
+	}
+	printAccessList("allow", allow)
+	printAccessList("deny", deny)
+	return
+}
+
+//
+// subscription policy
+//
+
+func (c *client) argsPolicyRule(in []string) (pr node.PolicyRule, err error) {
+
+	const expected = "expected feed and optional peer"
+
+	switch len(in) {
+	case 0:
+		err = errors.New("missing arguments: " + expected)
+	case 1:
+		pr.Feed, err = pubKeyFromHex(in[0])
+	case 2:
+		if pr.Feed, err = pubKeyFromHex(in[0]); err != nil {
+			return
+		}
+		pr.Peer, err = pubKeyFromHex(in[1])
+	default:
+		err = errors.New("too many arguments: " + expected)
+	}
+
+	return
+}
+
+func (c *client) policyAllow(in []string) (err error) {
+	var pr node.PolicyRule
+	if pr, err = c.argsPolicyRule(in); err != nil {
+		return
+	}
+	return c.r.Policy().Allow(pr.Feed, pr.Peer)
+}
+
+func (c *client) policyDisallow(in []string) (err error) {
+	var pr node.PolicyRule
+	if pr, err = c.argsPolicyRule(in); err != nil {
+		return
+	}
+	return c.r.Policy().Disallow(pr.Feed, pr.Peer)
+}
+
+func (c *client) policyList(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var list []node.PolicyRule
+	if list, err = c.r.Policy().List(); err != nil {
+		return
+	}
+	if len(list) == 0 {
+		fmt.Fprintln(out, "  no feeds allowed")
+		return
+	}
+	for _, pr := range list {
+		if pr.Peer == (cipher.PubKey{}) {
+			fmt.Fprintln(out, "  -", pr.Feed.Hex(), "(any peer)")
+			continue
+		}
+		fmt.Fprintln(out, "  -", pr.Feed.Hex(), pr.Peer.Hex())
+	}
+	return
+}
+
+func (c *client) policyReload(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	return c.r.Policy().Reload()
+}
+
+//
+// persistent peers
+//
+
+func (c *client) argsNetAddress(in []string) (na node.NetAddress, err error) {
+
+	const expected = "expected network (tcp, udp, unix or ws) and address"
+
+	switch len(in) {
+	case 0, 1:
+		err = errors.New("missing arguments: " + expected)
+	case 2:
+		na.Network, na.Address = in[0], in[1]
+	default:
+		err = errors.New("too many arguments: " + expected)
+	}
+
+	return
+}
+
+func (c *client) persistentAdd(in []string) (err error) {
+	var na node.NetAddress
+	if na, err = c.argsNetAddress(in); err != nil {
+		return
+	}
+	return c.r.Persistent().Add(na.Network, na.Address)
+}
+
+func (c *client) persistentRemove(in []string) (err error) {
+	var na node.NetAddress
+	if na, err = c.argsNetAddress(in); err != nil {
+		return
+	}
+	return c.r.Persistent().Remove(na.Network, na.Address)
+}
+
+func (c *client) persistentList(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var list []node.PersistentPeer
+	if list, err = c.r.Persistent().List(); err != nil {
+		return
+	}
+	if len(list) == 0 {
+		fmt.Fprintln(out, "  no persistent peers")
+		return
+	}
+	for _, pp := range list {
+		if pp.Connected == true {
+			fmt.Fprintln(out, "  -", pp.Network+"://"+pp.Address, "(connected)")
+		} else {
+			fmt.Fprintln(out, "  -", pp.Network+"://"+pp.Address,
+				"(failed attempts:", pp.Attempts, ")")
+		}
+		for _, feed := range pp.Feeds {
+			fmt.Fprintln(out, "      ", feed.Hex())
+		}
+	}
+	return
+}
+
+//
+// bandwidth
+//
+
+func parseBandwidth(in []string) (bw node.Bandwidth, err error) {
+
+	const expected = "expected upload and download limits"
+
+	switch {
+	case len(in) < 2:
+		err = errors.New("missing arguments: " + expected)
+	case len(in) > 2:
+		err = errors.New("too many arguments: " + expected)
+	default:
+		if bw.Upload, err = strconv.Atoi(in[0]); err != nil {
+			return
+		}
+		bw.Download, err = strconv.Atoi(in[1])
+	}
+
+	return
+}
+
+func bandwidthString(limit int) string {
+	if limit == 0 {
+		return "no limit"
+	}
+	return strconv.Itoa(limit) + " B/s"
+}
+
+func printBandwidth(name string, bw node.Bandwidth) {
+	fmt.Fprintln(out, " ", name+":")
+	fmt.Fprintln(out, "    upload:  ", bandwidthString(bw.Upload))
+	fmt.Fprintln(out, "    download:", bandwidthString(bw.Download))
+}
+
+func (c *client) bandwidth(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var nb, cb node.Bandwidth
+	if nb, err = c.r.Bandwidth().Node(); err != nil {
+		return
+	}
+	if cb, err = c.r.Bandwidth().Conn(); err != nil {
+		return
+	}
+	printBandwidth("node", nb)
+	printBandwidth("connection", cb)
+	return
+}
+
+func (c *client) bandwidthNode(in []string) (err error) {
+	var bw node.Bandwidth
+	if bw, err = parseBandwidth(in); err != nil {
+		return
+	}
+	return c.r.Bandwidth().SetNode(bw)
+}
+
+func (c *client) bandwidthConn(in []string) (err error) {
+	var bw node.Bandwidth
+	if bw, err = parseBandwidth(in); err != nil {
+		return
+	}
+	return c.r.Bandwidth().SetConn(bw)
+}
+
+func (c *client) bandwidthOf(in []string) (err error) {
+	if len(in) == 0 {
+		return errors.New("missing arguments: " +
+			"expected address, upload and download limits")
+	}
+	var bw node.Bandwidth
+	if bw, err = parseBandwidth(in[1:]); err != nil {
+		return
+	}
+	return c.r.Bandwidth().SetConnection(in[0], bw)
+}
+
+//
+// retention
+//
+
+func (c *client) retention(in []string) (err error) {
+	var pk cipher.PubKey
+	if pk, err = c.argsFeed(in); err != nil {
+		return
+	}
+	var rt skyobject.Retention
+	if rt, err = c.r.Retention().Get(pk); err != nil {
+		return
+	}
+	if rt.IsZero() == true {
+		fmt.Fprintln(out, "  keep all")
+		return
+	}
+	if rt.KeepLast > 0 {
+		fmt.Fprintln(out, "  keep last:", rt.KeepLast)
+	}
+	if rt.KeepFor > 0 {
+		fmt.Fprintln(out, "  keep for: ", rt.KeepFor)
+	}
+	return
+}
+
+func (c *client) retentionSet(in []string) (err error) {
+
+	const expected = "expected public key, keep last and keep for"
+
+	switch {
+	case len(in) < 3:
+		return errors.New("missing arguments: " + expected)
+	case len(in) > 3:
+		return errors.New("too many arguments: " + expected)
+	}
+
+	var (
+		pk cipher.PubKey
+		rt skyobject.Retention
+	)
+
+	if pk, err = pubKeyFromHex(in[0]); err != nil {
+		return
+	}
+	if rt.KeepLast, err = strconv.Atoi(in[1]); err != nil {
+		return
+	}
+	if rt.KeepFor, err = time.ParseDuration(in[2]); err != nil {
+		return
+	}
+
+	return c.r.Retention().Set(pk, rt)
+}
+
+func (c *client) retentionApply(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	return c.r.Retention().Apply()
+}
+
 //
 // connections
 //
@@ -714,6 +1290,51 @@ func (c *client) lastRoot(in []string) (err error) {
 	return
 }
 
+func (c *client) verifyChain(in []string) (err error) {
+	var hs node.HeadSelector
+	if hs, err = c.argsHead(in); err != nil {
+		return
+	}
+	var cr *skyobject.ChainReport
+	if cr, err = c.r.Root().VerifyChain(hs.Feed, hs.Nonce); err != nil {
+		return
+	}
+
+	if cr.Roots == 0 {
+		fmt.Fprintln(out, "  blank head")
+		return
+	}
+
+	fmt.Fprintln(out, "  roots:", cr.Roots, "seq", cr.First, "-", cr.Last)
+
+	if cr.Truncated == true {
+		fmt.Fprintln(out, "    truncated: seq 0 -", cr.First-1)
+	}
+
+	for _, g := range cr.Gaps {
+		fmt.Fprintln(out, "    gap:    ", g.From, "-", g.To)
+	}
+
+	for _, f := range cr.Forks {
+		fmt.Fprintln(out, "    fork:   ", f.Seq, "prev", f.Prev.Hex()[:7],
+			"expected", f.Expected.Hex()[:7])
+	}
+
+	for _, e := range cr.Invalid {
+		fmt.Fprintln(out, "    invalid:", e.Seq, e.Err)
+	}
+
+	for _, m := range cr.Missing {
+		fmt.Fprintln(out, "    missing:", m.Seq, len(m.Keys), "objects")
+	}
+
+	if cr.IsValid() == true {
+		fmt.Fprintln(out, "  the chain is valid")
+	}
+
+	return
+}
+
 //
 // stat
 //
@@ -764,6 +1385,35 @@ func (c *client) stat(in []string) (err error) {
 
 	fmt.Fprintln(out, "  new Root objects per second:    ", s.RootsPerSecond)
 
+	fmt.Fprintln(out, "  sent, saved by compression:     ", s.SentSaved, "B")
+	fmt.Fprintln(out, "  received, saved by compression: ", s.ReceivedSaved, "B")
+
+	fmt.Fprintln(out, "  filling, fetched objects:       ", s.FillFetched)
+	fmt.Fprintln(out, "  filling, skipped subtrees:      ", s.FillSkippedSubtrees)
+	fmt.Fprintln(out, "  filling, skipped objects:       ", s.FillSkippedObjects)
+
+	fmt.Fprintln(out, "  upload:                         ", round(s.Upload), "B/s")
+	fmt.Fprintln(out, "  download:                       ", round(s.Download), "B/s")
+
+	for _, cs := range s.Connections {
+		fmt.Fprintln(out, " ", cs.Address)
+		fmt.Fprintln(out, "    upload:  ", round(cs.Upload), "B/s, limit:",
+			bandwidthString(cs.Bandwidth.Upload))
+		fmt.Fprintln(out, "    download:", round(cs.Download), "B/s, limit:",
+			bandwidthString(cs.Bandwidth.Download))
+		fmt.Fprintln(out, "    requested objects:    ", cs.Counters.Objects)
+		fmt.Fprintln(out, "    previews:             ", cs.Counters.Previews)
+		fmt.Fprintln(out, "    subscription requests:", cs.Counters.Subs)
+		fmt.Fprintln(out, "    pushed Root objects:  ", cs.Counters.Roots)
+		fmt.Fprintln(out, "    delayed messages:     ", cs.Counters.Delayed)
+		fmt.Fprintln(out, "    dropped messages:     ", cs.Counters.Dropped)
+		fmt.Fprintln(out, "    score:                ", cs.Score.Score)
+		fmt.Fprintln(out, "    average latency:      ", cs.Score.Latency)
+		fmt.Fprintln(out, "    failed requests:      ", cs.Score.Failures, "of",
+			cs.Score.Requests)
+		fmt.Fprintln(out, "    hedged requests:      ", cs.Score.Hedged)
+	}
+
 	if len(s.Feeds) == 0 {
 		fmt.Fprintln(out, "  no feeds")
 		return
@@ -803,6 +1453,10 @@ func (c *client) stat(in []string) (err error) {
 func (c *client) help(in []string) (err error) {
 	fmt.Fprint(out, `
 
+  id
+    show id (public key) of the node
+
+
   share feed <public key>
     start sharing given feed
   don't share feed <public key>
@@ -832,6 +1486,81 @@ func (c *client) help(in []string) (err error) {
   udp address
     udp listening address
 
+  unix connect <socket path>
+    connect to unix socket
+  unix disconnect <connection address>
+    close unix connection
+  unix subscribe <connection address> <public key>
+    subscribe to feed of peer
+  unix unsubscribe <connection address> <public key>
+    unsubscribe from feed of peer
+  unix address
+    unix socket listening path
+
+  ws connect <url or address>
+    connect to websocket url, e.g. wss://example.com/cxo
+  ws disconnect <connection address>
+    close websocket connection
+  ws subscribe <connection address> <public key>
+    subscribe to feed of peer
+  ws unsubscribe <connection address> <public key>
+    unsubscribe from feed of peer
+  ws address
+    websocket listening address
+
+
+  peers allow <public key or CIDR>
+    allow peer or network, if there are allowed peers,
+    then only allowed peers can be connected
+  peers deny <public key or CIDR>
+    deny peer or network
+  peers remove <public key or CIDR>
+    remove rule from allow and deny lists
+  peers list
+    show allow and deny lists
+
+
+  policy allow <feed> [peer]
+    allow remote subscriptions to given feed for given
+    peer, or for any peer if the peer is omitted
+  policy disallow <feed> [peer]
+    remove rule from subscription policy
+  policy list
+    show subscription policy
+  policy reload
+    reload subscription policy from its file
+
+
+  persistent add <tcp, udp, unix or ws> <address>
+    add persistent peer, the node keeps connection
+    to it and restores subscriptions after reconnect
+  persistent remove <tcp, udp, unix or ws> <address>
+    stop redialing persistent peer
+  persistent list
+    show persistent peers
+
+
+  bandwidth
+    show upload and download limits
+  bandwidth node <upload> <download>
+    set limits of all connections together, bytes
+    per second, 0 - no limit
+  bandwidth conn <upload> <download>
+    set limits of every connection, bytes per
+    second, 0 - no limit
+  bandwidth of <connection address> <upload> <download>
+    set limits of given connection
+
+
+  retention <public key>
+    show retention policy of given feed
+  retention set <public key> <keep last> <keep for>
+    set retention policy of given feed, keep last
+    Root objects of every head and keep Root objects
+    saved during given time (e.g. 1h), 0 - no limit
+  retention apply
+    remove old Root objects now
+
 
   connections
     show all connections
@@ -848,11 +1577,19 @@ func (c *client) help(in []string) (err error) {
   last root <public key>
     show info about last Root of given feed
 
+  verify chain <public key> <nonce>
+    check signatures, links and objects of all
+    Root objects of given head
+
 
   stat
     show statistic of node
 
 
+  filling
+    show progress of all Root objects being filled
+
+
   help
     show this help messege
 
@@ -864,6 +1601,42 @@ func (c *client) help(in []string) (err error) {
 	return
 }
 
+//
+// filling
+//
+
+func (c *client) filling(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var fps []*node.FillingProgress
+	if fps, err = c.r.Node().Filling(); err != nil {
+		return
+	}
+
+	if len(fps) == 0 {
+		fmt.Fprintln(out, "  no Root objects being filled")
+		return
+	}
+
+	for _, fp := range fps {
+		fmt.Fprintln(out, " ", fp.Feed.Hex())
+		fmt.Fprintln(out, "    nonce:   ", fp.Nonce)
+		fmt.Fprintln(out, "    seq:     ", fp.Seq)
+		fmt.Fprintln(out, "    hash:    ", fp.Hash.Hex())
+		fmt.Fprintln(out, "    objects: ", fp.Objects, "of ~", fp.Total)
+		fmt.Fprintln(out, "    received:", fp.Received, "objects,", fp.Bytes,
+			"B")
+		fmt.Fprintln(out, "    skipped: ", fp.SkippedSubtrees, "subtrees, ~",
+			fp.SkippedObjects, "objects")
+		fmt.Fprintln(out, "    requests:", fp.Requests)
+		fmt.Fprintln(out, "    duration:", fp.Duration)
+		fmt.Fprintln(out, "    peers:   ", strings.Join(fp.Peers, ", "))
+	}
+
+	return
+}
+
 func (c *client) quit([]string) (_ error) {
 	fmt.Fprintln(out, "cya")
 	return
@@ -5,12 +5,19 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/cxo/node"
 )
 
+// defaults
+const (
+	KeyFile    = "cxod.key" // key file name (in data directory)
+	PolicyFile = ""         // policy file name (in data directory), opt-in
+)
+
 func waitInterrupt() {
 	var sig = make(chan os.Signal, 1)
 	signal.Notify(sig, os.Interrupt)
@@ -21,8 +28,18 @@ func main() {
 
 	var c = node.NewConfig()
 	c.OnSubscribeRemote = acceptAllSubscriptions
+	c.KeyFile = KeyFile // persistent node id
+
+	var policyFile = PolicyFile
 
 	c.FromFlags()
+
+	flag.StringVar(&policyFile,
+		"policy",
+		policyFile,
+		"subscription policy file, relative to data-dir, "+
+			"blank string to accept all subscriptions (default)")
+
 	flag.Parse()
 
 	var (
@@ -30,6 +47,16 @@ func main() {
 		err error
 	)
 
+	// subscription policy
+	if policyFile != "" {
+		if filepath.IsAbs(policyFile) == false && c.Config != nil {
+			policyFile = filepath.Join(c.Config.DataDir, policyFile)
+		}
+		if c.Policy, err = node.LoadPolicy(policyFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	// create and launch
 	if n, err = node.NewNode(c); err != nil {
 		log.Fatal(err)
@@ -40,7 +67,8 @@ func main() {
 	waitInterrupt()
 }
 
-// accept all incoming subscriptions
+// accept all incoming subscriptions that
+// the subscription policy allows (if any)
 func acceptAllSubscriptions(c *node.Conn, pk cipher.PubKey) (_ error) {
 	if err := c.Node().Share(pk); err != nil {
 		log.Fatal("DB failure:", err) // DB failure
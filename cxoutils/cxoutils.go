@@ -19,6 +19,7 @@ import (
 
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
 )
 
 // RemoveRootObjects removes old Root objects from given
@@ -28,6 +29,9 @@ import (
 //
 // If a feed contains more then one head, then the method
 // keeps last n-th Root objects of every head.
+//
+// See also Retention of skyobject.Config, that removes
+// old Root objects and their objects in background.
 func RemoveRootObjects(c *skyobject.Container, keepLast int) (err error) {
 
 	for _, pk := range c.Feeds() {
@@ -82,17 +86,51 @@ func RemoveRootObjects(c *skyobject.Container, keepLast int) (err error) {
 	return
 }
 
-// RemoveObjects with rc == 0 from CXDS
+// RemoveObjects with rc == 0 from CXDS. Objects of
+// pending Root objects (that can be filled later) are
+// kept. See also RemovePendingRoots
 func RemoveObjects(c *skyobject.Container) (err error) {
 
 	var db = c.DB().CXDS()
 
+	var keep map[cipher.SHA256]struct{}
+	if keep, err = c.ProvisionalObjects(); err != nil {
+		return
+	}
+
 	err = db.IterateDel(
 		func(key cipher.SHA256, rc uint32, _ []byte) (bool, error) {
+			if _, ok := keep[key]; ok == true {
+				return false, nil // provisional
+			}
 			return (rc == 0) && (c.IsCached(key) == false), nil
 		})
 
 	return
 }
 
+// RemovePendingRoots removes all pending Root objects
+// of given *skyobject.Container. Filling of the Root
+// objects can't be continued after. Call RemoveObjects
+// after to free space
+func RemovePendingRoots(c *skyobject.Container) (err error) {
+
+	for _, pk := range c.Feeds() {
+
+		var rs []*registry.Root
+		if rs, err = c.Pending(pk); err != nil {
+			return
+		}
+
+		for _, r := range rs {
+			if err = c.DelPending(r.Pub, r.Nonce, r.Seq); err != nil {
+				return
+			}
+		}
+
+	}
+
+	return
+}
+
 // TODO (kostyarin): RemoveObjects with "down to" or "timeout" feature
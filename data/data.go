@@ -101,3 +101,69 @@ func (r *Root) Encode() (p []byte) {
 func (r *Root) Decode(p []byte) (err error) {
 	return encoder.DeserializeRaw(p, r)
 }
+
+// A SignedRoot represents encoded
+// Root with its signature
+type SignedRoot struct {
+	Value []byte     // encoded Root
+	Sig   cipher.Sig // signature of hash of the Value
+}
+
+// Validate the SignedRoot
+func (s *SignedRoot) Validate() (err error) {
+	if len(s.Value) == 0 {
+		return errors.New("(data.SignedRoot.Validate) empty Value")
+	}
+	if s.Sig == (cipher.Sig{}) {
+		return errors.New("(data.SignedRoot.Validate) empty Sig")
+	}
+	return
+}
+
+// Encode the SignedRoot
+func (s *SignedRoot) Encode() (p []byte) {
+	return encoder.Serialize(s)
+}
+
+// Decode given encoded SignedRoot to this one
+func (s *SignedRoot) Decode(p []byte) (err error) {
+	return encoder.DeserializeRaw(p, s)
+}
+
+// An Equivocation represents two different Root
+// objects of a head with the same seq number, both
+// signed by owner of the feed. The signed Root
+// objects are evidence of the equivocation
+type Equivocation struct {
+	Nonce uint64 // head
+	Seq   uint64 // seq of the Root objects
+
+	Time int64 // detected at
+
+	First  SignedRoot // Root received first
+	Second SignedRoot // conflicting Root
+}
+
+// Validate the Equivocation
+func (e *Equivocation) Validate() (err error) {
+	if len(e.First.Value) == 0 || len(e.Second.Value) == 0 {
+		return errors.New("(data.Equivocation.Validate) empty Value")
+	}
+	if e.First.Sig == (cipher.Sig{}) || e.Second.Sig == (cipher.Sig{}) {
+		return errors.New("(data.Equivocation.Validate) empty Sig")
+	}
+	if string(e.First.Value) == string(e.Second.Value) {
+		return errors.New("(data.Equivocation.Validate) the same Root")
+	}
+	return
+}
+
+// Encode the Equivocation
+func (e *Equivocation) Encode() (p []byte) {
+	return encoder.Serialize(e)
+}
+
+// Decode given encoded Equivocation to this one
+func (e *Equivocation) Decode(p []byte) (err error) {
+	return encoder.DeserializeRaw(p, e)
+}
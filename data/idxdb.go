@@ -32,6 +32,18 @@ type Feeds interface {
 	// if given feed doesn't exist
 	Heads(pk cipher.PubKey) (hs Heads, err error)
 
+	// Pending Root objects of feed. It returns
+	// ErrNoSuchFeed if given feed doesn't exist
+	Pending(pk cipher.PubKey) (ps Pending, err error)
+
+	// Equivocations of feed. It returns ErrNoSuchFeed
+	// if given feed doesn't exist
+	Equivocations(pk cipher.PubKey) (es Equivocations, err error)
+
+	// History of feed. It returns ErrNoSuchFeed
+	// if given feed doesn't exist
+	History(pk cipher.PubKey) (hs History, err error)
+
 	// Len is number of feeds stroed
 	Len() (length int)
 }
@@ -110,6 +122,105 @@ type Roots interface {
 	Len() (length int)
 }
 
+// An IteratePendingFunc represents function for
+// iterating over pending Root objects of a feed
+type IteratePendingFunc func(nonce uint64, r *Root) (err error)
+
+// An IterateKeysFunc represents function for
+// iterating over keys of objects
+type IterateKeysFunc func(key cipher.SHA256) (err error)
+
+// A Pending represents bucket of Root objects that
+// are not filled yet. If filling of a Root breaks,
+// then objects of the Root already received are
+// kept in CXDS (even with zero references counters)
+// and the Root becomes pending. The Pending keeps
+// keys of the objects to mark them provisional. The
+// filling can be continued later using the objects
+type Pending interface {
+	// Set pending Root with keys of objects received.
+	// If the Root is already pending, then the Set
+	// adds given keys to its list
+	Set(nonce uint64, r *Root, keys []cipher.SHA256) (err error)
+	// Get pending Root by nonce and seq
+	Get(nonce, seq uint64) (r *Root, err error)
+	// Del pending Root with all its keys. The Del
+	// never returns ErrNotFound if Root doesn't exist
+	Del(nonce, seq uint64) (err error)
+	// Has returns true if Root with given
+	// nonce and seq is pending
+	Has(nonce, seq uint64) (ok bool, err error)
+	// Iterate all pending Root objects ordered
+	// by nonce and then by seq. Use the
+	// ErrStopIteration to stop iteration
+	Iterate(iterateFunc IteratePendingFunc) (err error)
+	// Keys iterates over keys of objects of a pending
+	// Root. Use ErrStopIteration to stop iteration.
+	// It returns ErrNotFound if the Root is not pending
+	Keys(nonce, seq uint64, iterateFunc IterateKeysFunc) (err error)
+	// Len is number of pending Root objects
+	Len() (length int)
+}
+
+// An IterateEquivocationsFunc represents function
+// for iterating over equivocations of a feed
+type IterateEquivocationsFunc func(e *Equivocation) (err error)
+
+// An Equivocations represents bucket of equivocations
+// of a feed, one per nonce and seq. An equivocation
+// is kept as evidence until its feed deleted, even if
+// its head or Root objects deleted
+type Equivocations interface {
+	// Add equivocation. If there is an equivocation
+	// with the same nonce and seq, then the Add does
+	// nothing (the first evidence is enough)
+	Add(e *Equivocation) (err error)
+	// Get equivocation by nonce and seq
+	Get(nonce, seq uint64) (e *Equivocation, err error)
+	// Has returns true if there is equivocation
+	// with given nonce and seq
+	Has(nonce, seq uint64) (ok bool, err error)
+	// Iterate all equivocations ordered by nonce and
+	// then by seq. Use the ErrStopIteration to stop
+	// iteration
+	Iterate(iterateFunc IterateEquivocationsFunc) (err error)
+	// Len is number of equivocations
+	Len() (length int)
+}
+
+// An IterateHistoryFunc represents function for
+// iterating over historical Root objects of a feed
+type IterateHistoryFunc func(nonce, seq uint64, sr *SignedRoot) (err error)
+
+// A History represents bucket of historical Root objects
+// of a feed. A historical Root is received from a peer
+// and verified, but it's not filled. Roots of a head
+// contain full Root objects only, thus historical Root
+// objects are kept apart. The History keeps encoded Root
+// objects with signatures, thus chain of Root objects
+// of a head can be reconstructed using Prev hashes
+type History interface {
+	// Set historical Root of given head. If there
+	// is a Root with the same nonce and seq, then
+	// the Set does nothing
+	Set(nonce, seq uint64, sr *SignedRoot) (err error)
+	// Get historical Root by nonce and seq
+	Get(nonce, seq uint64) (sr *SignedRoot, err error)
+	// Del historical Root. The Del never returns
+	// ErrNotFound if Root doesn't exist
+	Del(nonce, seq uint64) (err error)
+	// Has returns true if there is historical
+	// Root with given nonce and seq
+	Has(nonce, seq uint64) (ok bool, err error)
+	// Iterate all historical Root objects ordered by
+	// nonce and then by seq. Use the ErrStopIteration
+	// to stop iteration. It's possible to delete Root
+	// objects inside the Iterate
+	Iterate(iterateFunc IterateHistoryFunc) (err error)
+	// Len is number of historical Root objects
+	Len() (length int)
+}
+
 // An IdxDB repesents database that contains
 // meta information: feeds meta information
 // about Root objects. There is data/idxdb
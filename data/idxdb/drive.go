@@ -13,9 +13,15 @@ import (
 )
 
 var (
-	feedsBucket = []byte("f")       // feeds
-	metaBucket  = []byte("m")       // meta information
-	versionKey  = []byte("version") // encoded version in the meta bucket
+	feedsBucket   = []byte("f")       // feeds
+	pendingBucket = []byte("p")       // pending Root objects
+	equivBucket   = []byte("e")       // equivocations
+	historyBucket = []byte("h")       // historical Root objects
+	metaBucket    = []byte("m")       // meta information
+	versionKey    = []byte("version") // encoded version in the meta bucket
+
+	pendingRootKey = []byte("r") // encoded Root in bucket of pending Root
+	pendingKeysKey = []byte("k") // keys of objects of pending Root
 )
 
 type driveDB struct {
@@ -83,7 +89,19 @@ func NewDriveIdxDB(fileName string) (idx data.IdxDB, err error) {
 
 		}
 
-		_, err = tx.CreateBucketIfNotExists(feedsBucket)
+		if _, err = tx.CreateBucketIfNotExists(feedsBucket); err != nil {
+			return
+		}
+
+		if _, err = tx.CreateBucketIfNotExists(pendingBucket); err != nil {
+			return
+		}
+
+		if _, err = tx.CreateBucketIfNotExists(equivBucket); err != nil {
+			return
+		}
+
+		_, err = tx.CreateBucketIfNotExists(historyBucket)
 		return
 	})
 
@@ -99,7 +117,12 @@ func NewDriveIdxDB(fileName string) (idx data.IdxDB, err error) {
 // Tx performs ACID-transaction
 func (d *driveDB) Tx(txFunc func(feeds data.Feeds) (err error)) (err error) {
 	return d.b.Update(func(tx *bolt.Tx) (err error) {
-		return txFunc(&driveFeeds{tx.Bucket(feedsBucket)})
+		return txFunc(&driveFeeds{
+			bk: tx.Bucket(feedsBucket),
+			pb: tx.Bucket(pendingBucket),
+			eb: tx.Bucket(equivBucket),
+			hb: tx.Bucket(historyBucket),
+		})
 	})
 }
 
@@ -109,7 +132,10 @@ func (d *driveDB) Close() (err error) {
 }
 
 type driveFeeds struct {
-	bk *bolt.Bucket
+	bk *bolt.Bucket // feeds
+	pb *bolt.Bucket // pending Root objects
+	eb *bolt.Bucket // equivocations
+	hb *bolt.Bucket // historical Root objects
 }
 
 // Add feed or does nothing if its already exists
@@ -129,6 +155,24 @@ func (d *driveFeeds) Del(pk cipher.PubKey) (err error) {
 
 	}
 
+	if d.pb.Bucket(pk[:]) != nil {
+		if err = d.pb.DeleteBucket(pk[:]); err != nil {
+			return
+		}
+	}
+
+	if d.eb.Bucket(pk[:]) != nil {
+		if err = d.eb.DeleteBucket(pk[:]); err != nil {
+			return
+		}
+	}
+
+	if d.hb.Bucket(pk[:]) != nil {
+		if err = d.hb.DeleteBucket(pk[:]); err != nil {
+			return
+		}
+	}
+
 	return d.bk.DeleteBucket(pk[:])
 }
 
@@ -178,6 +222,50 @@ func (d *driveFeeds) Heads(pk cipher.PubKey) (rs data.Heads, err error) {
 	return &driveHeads{bk}, nil
 }
 
+// Pending returns bucket of pending Root
+// objects of given feed
+func (d *driveFeeds) Pending(pk cipher.PubKey) (ps data.Pending, err error) {
+	if d.bk.Bucket(pk[:]) == nil {
+		return nil, data.ErrNoSuchFeed
+	}
+	var bk *bolt.Bucket
+	if bk, err = d.pb.CreateBucketIfNotExists(pk[:]); err != nil {
+		return
+	}
+	return &drivePending{bk}, nil
+}
+
+// Equivocations returns bucket of
+// equivocations of given feed
+func (d *driveFeeds) Equivocations(
+	pk cipher.PubKey,
+) (
+	es data.Equivocations,
+	err error,
+) {
+	if d.bk.Bucket(pk[:]) == nil {
+		return nil, data.ErrNoSuchFeed
+	}
+	var bk *bolt.Bucket
+	if bk, err = d.eb.CreateBucketIfNotExists(pk[:]); err != nil {
+		return
+	}
+	return &driveEquivocations{bk}, nil
+}
+
+// History returns bucket of historical
+// Root objects of given feed
+func (d *driveFeeds) History(pk cipher.PubKey) (hs data.History, err error) {
+	if d.bk.Bucket(pk[:]) == nil {
+		return nil, data.ErrNoSuchFeed
+	}
+	var bk *bolt.Bucket
+	if bk, err = d.hb.CreateBucketIfNotExists(pk[:]); err != nil {
+		return
+	}
+	return &driveHistory{bk}, nil
+}
+
 func (d *driveFeeds) Len() (length int) {
 	return d.bk.Stats().BucketN - 1
 }
@@ -0,0 +1,96 @@
+package idxdb
+
+import (
+	"github.com/boltdb/bolt"
+
+	"github.com/skycoin/cxo/data"
+)
+
+type driveEquivocations struct {
+	bk *bolt.Bucket
+}
+
+// Add equivocation if there is not
+// an equivocation with the same
+// nonce and seq
+func (d *driveEquivocations) Add(e *data.Equivocation) (err error) {
+
+	if err = e.Validate(); err != nil {
+		return
+	}
+
+	var key = pendingKey(e.Nonce, e.Seq) // the same key
+
+	if d.bk.Get(key) != nil {
+		return // already have
+	}
+
+	return d.bk.Put(key, e.Encode())
+}
+
+// Get equivocation
+func (d *driveEquivocations) Get(
+	nonce uint64,
+	seq uint64,
+) (
+	e *data.Equivocation,
+	err error,
+) {
+
+	var val []byte
+	if val = d.bk.Get(pendingKey(nonce, seq)); val == nil {
+		return nil, data.ErrNotFound
+	}
+
+	e = new(data.Equivocation)
+
+	if err = e.Decode(val); err != nil {
+		panic(err)
+	}
+
+	return
+}
+
+// Has performs presence check
+func (d *driveEquivocations) Has(nonce, seq uint64) (ok bool, _ error) {
+	ok = d.bk.Get(pendingKey(nonce, seq)) != nil
+	return
+}
+
+// Iterate over equivocations
+func (d *driveEquivocations) Iterate(
+	iterateFunc data.IterateEquivocationsFunc,
+) (
+	err error,
+) {
+
+	var c = d.bk.Cursor()
+
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+
+		var e = new(data.Equivocation)
+
+		if err = e.Decode(v); err != nil {
+			panic(err)
+		}
+
+		if err = iterateFunc(e); err != nil {
+			if err == data.ErrStopIteration {
+				err = nil
+			}
+			return
+		}
+
+	}
+
+	return
+}
+
+// Len returns number of equivocations
+func (d *driveEquivocations) Len() (length int) {
+	var c = d.bk.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		length++
+	}
+	return
+}
@@ -0,0 +1,34 @@
+package idxdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/skycoin/cxo/data/tests"
+)
+
+func TestEquivocations_Add(t *testing.T) {
+	// Add(*Equivocation) error
+
+	t.Run("drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+
+		tests.EquivocationsAdd(t, idx)
+	})
+
+}
+
+func TestEquivocations_Iterate(t *testing.T) {
+	// Iterate(IterateEquivocationsFunc) error
+
+	t.Run("drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+
+		tests.EquivocationsIterate(t, idx)
+	})
+
+}
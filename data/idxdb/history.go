@@ -0,0 +1,122 @@
+package idxdb
+
+import (
+	"encoding/binary"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/skycoin/cxo/data"
+)
+
+type driveHistory struct {
+	bk *bolt.Bucket
+}
+
+// Set historical Root if there is not
+// a Root with the same nonce and seq
+func (d *driveHistory) Set(
+	nonce uint64,
+	seq uint64,
+	sr *data.SignedRoot,
+) (
+	err error,
+) {
+
+	if err = sr.Validate(); err != nil {
+		return
+	}
+
+	var key = pendingKey(nonce, seq) // the same key
+
+	if d.bk.Get(key) != nil {
+		return // already have
+	}
+
+	return d.bk.Put(key, sr.Encode())
+}
+
+// Get historical Root
+func (d *driveHistory) Get(
+	nonce uint64,
+	seq uint64,
+) (
+	sr *data.SignedRoot,
+	err error,
+) {
+
+	var val []byte
+	if val = d.bk.Get(pendingKey(nonce, seq)); val == nil {
+		return nil, data.ErrNotFound
+	}
+
+	sr = new(data.SignedRoot)
+
+	if err = sr.Decode(val); err != nil {
+		panic(err)
+	}
+
+	return
+}
+
+// Del historical Root
+func (d *driveHistory) Del(nonce, seq uint64) (err error) {
+	return d.bk.Delete(pendingKey(nonce, seq))
+}
+
+// Has performs presence check
+func (d *driveHistory) Has(nonce, seq uint64) (ok bool, _ error) {
+	ok = d.bk.Get(pendingKey(nonce, seq)) != nil
+	return
+}
+
+// Iterate over historical Root objects
+func (d *driveHistory) Iterate(
+	iterateFunc data.IterateHistoryFunc,
+) (
+	err error,
+) {
+
+	var (
+		c  = d.bk.Cursor()
+		sk = make([]byte, 16)
+	)
+
+	// we have to Seek(next) instead of using Next
+	// because we allows mutations during the iteration
+	for k, v := c.First(); k != nil; k, v = c.Seek(sk) {
+
+		copy(sk, k)
+
+		var sr = new(data.SignedRoot)
+
+		if err = sr.Decode(v); err != nil {
+			panic(err)
+		}
+
+		err = iterateFunc(
+			binary.BigEndian.Uint64(sk),
+			binary.BigEndian.Uint64(sk[8:]),
+			sr,
+		)
+
+		if err != nil {
+			if err == data.ErrStopIteration {
+				err = nil
+			}
+			return
+		}
+
+		incSlice(sk)
+	}
+
+	return
+}
+
+// Len returns number of historical Root objects
+func (d *driveHistory) Len() (length int) {
+	var c = d.bk.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		length++
+	}
+	return
+}
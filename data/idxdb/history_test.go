@@ -0,0 +1,34 @@
+package idxdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/skycoin/cxo/data/tests"
+)
+
+func TestHistory_Set(t *testing.T) {
+	// Set(uint64, uint64, *SignedRoot) error
+
+	t.Run("drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+
+		tests.HistorySet(t, idx)
+	})
+
+}
+
+func TestHistory_Iterate(t *testing.T) {
+	// Iterate(IterateHistoryFunc) error
+
+	t.Run("drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+
+		tests.HistoryIterate(t, idx)
+	})
+
+}
@@ -0,0 +1,176 @@
+package idxdb
+
+import (
+	"encoding/binary"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+type drivePending struct {
+	bk *bolt.Bucket
+}
+
+// nonce and seq of pending Root to key
+func pendingKey(nonce, seq uint64) (p []byte) {
+	p = make([]byte, 16)
+	binary.BigEndian.PutUint64(p, nonce)
+	binary.BigEndian.PutUint64(p[8:], seq)
+	return
+}
+
+// Set pending Root and add given keys to its list
+func (d *drivePending) Set(
+	nonce uint64,
+	r *data.Root,
+	keys []cipher.SHA256,
+) (
+	err error,
+) {
+
+	if err = r.Validate(); err != nil {
+		return
+	}
+
+	var bk *bolt.Bucket
+	if bk, err = d.bk.CreateBucketIfNotExists(pendingKey(nonce, r.Seq)); err != nil {
+		return
+	}
+
+	if err = bk.Put(pendingRootKey, r.Encode()); err != nil {
+		return
+	}
+
+	var kb *bolt.Bucket
+	if kb, err = bk.CreateBucketIfNotExists(pendingKeysKey); err != nil {
+		return
+	}
+
+	for _, key := range keys {
+		if err = kb.Put(key[:], []byte{}); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Get pending Root
+func (d *drivePending) Get(nonce, seq uint64) (r *data.Root, err error) {
+
+	var bk *bolt.Bucket
+	if bk = d.bk.Bucket(pendingKey(nonce, seq)); bk == nil {
+		return nil, data.ErrNotFound
+	}
+
+	r = new(data.Root)
+
+	if err = r.Decode(bk.Get(pendingRootKey)); err != nil {
+		panic(err)
+	}
+
+	return
+}
+
+// Del pending Root
+func (d *drivePending) Del(nonce, seq uint64) (err error) {
+
+	var key = pendingKey(nonce, seq)
+
+	if d.bk.Bucket(key) == nil {
+		return // not found
+	}
+
+	return d.bk.DeleteBucket(key)
+}
+
+// Has performs presence check
+func (d *drivePending) Has(nonce, seq uint64) (ok bool, _ error) {
+	ok = d.bk.Bucket(pendingKey(nonce, seq)) != nil
+	return
+}
+
+// Iterate over pending Root objects
+func (d *drivePending) Iterate(
+	iterateFunc data.IteratePendingFunc,
+) (
+	err error,
+) {
+
+	var (
+		r = new(data.Root)
+		c = d.bk.Cursor()
+
+		sk = make([]byte, 16)
+	)
+
+	// we have to Seek(next) instead of using Next
+	// because we allows mutations during the iteration
+	for k, _ := c.First(); k != nil; k, _ = c.Seek(sk) {
+
+		copy(sk, k)
+
+		var bk = d.bk.Bucket(sk)
+
+		if err = r.Decode(bk.Get(pendingRootKey)); err != nil {
+			panic(err)
+		}
+
+		if err = iterateFunc(binary.BigEndian.Uint64(sk), r); err != nil {
+			if err == data.ErrStopIteration {
+				err = nil
+			}
+			return
+		}
+
+		incSlice(sk)
+	}
+
+	return
+}
+
+// Keys iterates over keys of objects of a pending Root
+func (d *drivePending) Keys(
+	nonce uint64,
+	seq uint64,
+	iterateFunc data.IterateKeysFunc,
+) (
+	err error,
+) {
+
+	var bk *bolt.Bucket
+	if bk = d.bk.Bucket(pendingKey(nonce, seq)); bk == nil {
+		return data.ErrNotFound
+	}
+
+	var kb *bolt.Bucket
+	if kb = bk.Bucket(pendingKeysKey); kb == nil {
+		return // no keys
+	}
+
+	var key cipher.SHA256
+
+	err = kb.ForEach(func(k, _ []byte) (err error) {
+		copy(key[:], k)
+		return iterateFunc(key)
+	})
+
+	if err == data.ErrStopIteration {
+		err = nil
+	}
+
+	return
+}
+
+// Len returns number of pending Root objects
+func (d *drivePending) Len() (length int) {
+	// the Stats().BucketN counts nested buckets too
+	var c = d.bk.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		length++
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package idxdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/skycoin/cxo/data/tests"
+)
+
+func TestPending_Set(t *testing.T) {
+	// Set(uint64, *Root, []cipher.SHA256) error
+
+	t.Run("drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+
+		tests.PendingSet(t, idx)
+	})
+
+}
+
+func TestPending_Del(t *testing.T) {
+	// Del(uint64, uint64) error
+
+	t.Run("drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+
+		tests.PendingDel(t, idx)
+	})
+
+}
+
+func TestPending_Iterate(t *testing.T) {
+	// Iterate(IteratePendingFunc) error
+
+	t.Run("drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+
+		tests.PendingIterate(t, idx)
+	})
+
+}
@@ -255,6 +255,49 @@ func CXDSInc(t *testing.T, ds data.CXDS) {
 
 }
 
+// CXDSDel tests Del method of CXDS
+func CXDSDel(t *testing.T, ds data.CXDS) {
+
+	var key, value = testKeyValue("something")
+
+	t.Run("not exist", func(t *testing.T) {
+		if err := ds.Del(key); err != nil {
+			t.Error(err)
+		}
+		shouldNotExistInCXDS(t, ds, key)
+	})
+
+	if _, err := ds.Set(key, value, 1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("used", func(t *testing.T) {
+		if err := ds.Del(key); err != nil {
+			t.Error(err)
+		}
+		shouldNotExistInCXDS(t, ds, key)
+	})
+
+	if _, err := ds.Set(key, value, 1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := ds.Inc(key, -1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("zero rc", func(t *testing.T) {
+		if err := ds.Del(key); err != nil {
+			t.Error(err)
+		}
+		shouldNotExistInCXDS(t, ds, key)
+	})
+
+}
+
 // CXDSClose tests Close method of CXDS
 func CXDSClose(t *testing.T, ds data.CXDS) {
 	if err := ds.Close(); err != nil {
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+func newEquivocation(
+	nonce uint64,
+	seq uint64,
+	sk cipher.SecKey,
+) (
+	e *data.Equivocation,
+) {
+
+	e = &data.Equivocation{Nonce: nonce, Seq: seq, Time: 1}
+
+	var a, b = []byte("first"), []byte("second")
+
+	e.First.Value = a
+	e.First.Sig = cipher.SignHash(cipher.SumSHA256(a), sk)
+	e.Second.Value = b
+	e.Second.Sig = cipher.SignHash(cipher.SumSHA256(b), sk)
+	return
+}
+
+// EquivocationsAdd is test case for Equivocations.Add
+func EquivocationsAdd(t *testing.T, idx data.IdxDB) {
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	t.Run("no such feed", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			_, err = feeds.Equivocations(pk)
+			return
+		})
+		if err != data.ErrNoSuchFeed {
+			t.Error("wrong error:", err)
+		}
+	})
+
+	if addFeed(t, idx, pk); t.Failed() {
+		return
+	}
+
+	var e = newEquivocation(1, 2, sk)
+
+	t.Run("add", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			var es data.Equivocations
+			if es, err = feeds.Equivocations(pk); err != nil {
+				return
+			}
+			if err = es.Add(e); err != nil {
+				return
+			}
+			// the first is kept
+			var another = newEquivocation(1, 2, sk)
+			another.Time = 2
+			if err = es.Add(another); err != nil {
+				return
+			}
+			if es.Len() != 1 {
+				t.Error("wrong length:", es.Len())
+			}
+			var ge *data.Equivocation
+			if ge, err = es.Get(1, 2); err != nil {
+				return
+			}
+			if ge.Time != e.Time || string(ge.Second.Value) != "second" {
+				t.Error("wrong equivocation")
+			}
+			var ok bool
+			if ok, err = es.Has(1, 2); err != nil {
+				return
+			}
+			if ok == false {
+				t.Error("missing equivocation")
+			}
+			if _, err = es.Get(1, 3); err != data.ErrNotFound {
+				t.Error("wrong error:", err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			var es data.Equivocations
+			if es, err = feeds.Equivocations(pk); err != nil {
+				return
+			}
+			var same = newEquivocation(1, 3, sk)
+			same.Second = same.First
+			return es.Add(same)
+		})
+		if err == nil {
+			t.Error("missing error")
+		}
+	})
+
+	t.Run("feed", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			if err = feeds.Del(pk); err != nil {
+				return
+			}
+			if err = feeds.Add(pk); err != nil {
+				return
+			}
+			var es data.Equivocations
+			if es, err = feeds.Equivocations(pk); err != nil {
+				return
+			}
+			if es.Len() != 0 {
+				t.Error("equivocation of deleted feed")
+			}
+			return
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+}
+
+// EquivocationsIterate is test case for Equivocations.Iterate
+func EquivocationsIterate(t *testing.T, idx data.IdxDB) {
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	if addFeed(t, idx, pk); t.Failed() {
+		return
+	}
+
+	err := idx.Tx(func(feeds data.Feeds) (err error) {
+		var es data.Equivocations
+		if es, err = feeds.Equivocations(pk); err != nil {
+			return
+		}
+		// nonces: 3, 2, 1
+		for nonce := uint64(3); nonce > 0; nonce-- {
+			if err = es.Add(newEquivocation(nonce, 1, sk)); err != nil {
+				return
+			}
+		}
+		var nonces []uint64
+		err = es.Iterate(func(e *data.Equivocation) (err error) {
+			nonces = append(nonces, e.Nonce)
+			if len(nonces) == 2 {
+				return data.ErrStopIteration
+			}
+			return
+		})
+		if err != nil {
+			return
+		}
+		if len(nonces) != 2 || nonces[0] != 1 || nonces[1] != 2 {
+			t.Error("wrong order:", nonces)
+		}
+		return
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+}
@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+func newSignedRoot(seed string, sk cipher.SecKey) (sr *data.SignedRoot) {
+	sr = new(data.SignedRoot)
+	sr.Value = []byte(seed)
+	sr.Sig = cipher.SignHash(cipher.SumSHA256(sr.Value), sk)
+	return
+}
+
+// HistorySet is test case for History.Set
+func HistorySet(t *testing.T, idx data.IdxDB) {
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	t.Run("no such feed", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			_, err = feeds.History(pk)
+			return
+		})
+		if err != data.ErrNoSuchFeed {
+			t.Error("wrong error:", err)
+		}
+	})
+
+	if addFeed(t, idx, pk); t.Failed() {
+		return
+	}
+
+	t.Run("set", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			var hs data.History
+			if hs, err = feeds.History(pk); err != nil {
+				return
+			}
+			if err = hs.Set(1, 2, newSignedRoot("first", sk)); err != nil {
+				return
+			}
+			// the first is kept
+			if err = hs.Set(1, 2, newSignedRoot("second", sk)); err != nil {
+				return
+			}
+			if hs.Len() != 1 {
+				t.Error("wrong length:", hs.Len())
+			}
+			var sr *data.SignedRoot
+			if sr, err = hs.Get(1, 2); err != nil {
+				return
+			}
+			if string(sr.Value) != "first" {
+				t.Error("wrong Root")
+			}
+			var ok bool
+			if ok, err = hs.Has(1, 2); err != nil {
+				return
+			}
+			if ok == false {
+				t.Error("missing Root")
+			}
+			if _, err = hs.Get(1, 3); err != data.ErrNotFound {
+				t.Error("wrong error:", err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			var hs data.History
+			if hs, err = feeds.History(pk); err != nil {
+				return
+			}
+			return hs.Set(1, 3, &data.SignedRoot{Value: []byte("x")})
+		})
+		if err == nil {
+			t.Error("missing error")
+		}
+	})
+
+	t.Run("del", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			var hs data.History
+			if hs, err = feeds.History(pk); err != nil {
+				return
+			}
+			if err = hs.Del(1, 2); err != nil {
+				return
+			}
+			if err = hs.Del(1, 2); err != nil {
+				return // not found is not an error
+			}
+			if hs.Len() != 0 {
+				t.Error("not deleted")
+			}
+			return
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("feed", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			var hs data.History
+			if hs, err = feeds.History(pk); err != nil {
+				return
+			}
+			if err = hs.Set(1, 2, newSignedRoot("first", sk)); err != nil {
+				return
+			}
+			if err = feeds.Del(pk); err != nil {
+				return
+			}
+			if err = feeds.Add(pk); err != nil {
+				return
+			}
+			if hs, err = feeds.History(pk); err != nil {
+				return
+			}
+			if hs.Len() != 0 {
+				t.Error("history of deleted feed")
+			}
+			return
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+}
+
+// HistoryIterate is test case for History.Iterate
+func HistoryIterate(t *testing.T, idx data.IdxDB) {
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	if addFeed(t, idx, pk); t.Failed() {
+		return
+	}
+
+	err := idx.Tx(func(feeds data.Feeds) (err error) {
+		var hs data.History
+		if hs, err = feeds.History(pk); err != nil {
+			return
+		}
+		// seq: 3, 2, 1 of nonces 2 and 1
+		for nonce := uint64(2); nonce > 0; nonce-- {
+			for seq := uint64(3); seq > 0; seq-- {
+				err = hs.Set(nonce, seq, newSignedRoot("r", sk))
+				if err != nil {
+					return
+				}
+			}
+		}
+		// delete during the iteration
+		var seqs []uint64
+		err = hs.Iterate(func(nonce, seq uint64, _ *data.SignedRoot) (err error) {
+			if nonce == 2 {
+				return data.ErrStopIteration
+			}
+			seqs = append(seqs, seq)
+			return hs.Del(nonce, seq)
+		})
+		if err != nil {
+			return
+		}
+		if len(seqs) != 3 || seqs[0] != 1 || seqs[1] != 2 || seqs[2] != 3 {
+			t.Error("wrong order:", seqs)
+		}
+		if hs.Len() != 3 {
+			t.Error("wrong length:", hs.Len())
+		}
+		return
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+}
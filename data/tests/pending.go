@@ -0,0 +1,223 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+func pendingKeys(
+	t *testing.T,
+	ps data.Pending,
+	nonce uint64,
+	seq uint64,
+) (
+	keys map[cipher.SHA256]struct{},
+) {
+
+	keys = make(map[cipher.SHA256]struct{})
+
+	err := ps.Keys(nonce, seq, func(key cipher.SHA256) (_ error) {
+		keys[key] = struct{}{}
+		return
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	return
+}
+
+// PendingSet is test case for Pending.Set
+func PendingSet(t *testing.T, idx data.IdxDB) {
+
+	const nonce = 1
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	t.Run("no such feed", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			_, err = feeds.Pending(pk)
+			return
+		})
+		if err != data.ErrNoSuchFeed {
+			t.Error("wrong error:", err)
+		}
+	})
+
+	if addFeed(t, idx, pk); t.Failed() {
+		return
+	}
+
+	var (
+		r    = newRoot("r", sk)
+		a, _ = testKeyValue("a")
+		b, _ = testKeyValue("b")
+	)
+
+	t.Run("set", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			var ps data.Pending
+			if ps, err = feeds.Pending(pk); err != nil {
+				return
+			}
+			if err = ps.Set(nonce, r, []cipher.SHA256{a}); err != nil {
+				return
+			}
+			if err = ps.Set(nonce, r, []cipher.SHA256{a, b}); err != nil {
+				return
+			}
+			if ps.Len() != 1 {
+				t.Error("wrong length:", ps.Len())
+			}
+			var gr *data.Root
+			if gr, err = ps.Get(nonce, r.Seq); err != nil {
+				return
+			}
+			if gr.Hash != r.Hash {
+				t.Error("wrong Root")
+			}
+			if keys := pendingKeys(t, ps, nonce, r.Seq); len(keys) != 2 {
+				t.Error("wrong keys:", len(keys))
+			}
+			return
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			var ps data.Pending
+			if ps, err = feeds.Pending(pk); err != nil {
+				return
+			}
+			return ps.Set(nonce, &data.Root{}, nil)
+		})
+		if err == nil {
+			t.Error("missing error")
+		}
+	})
+
+}
+
+// PendingDel is test case for Pending.Del
+func PendingDel(t *testing.T, idx data.IdxDB) {
+
+	const nonce = 1
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	if addFeed(t, idx, pk); t.Failed() {
+		return
+	}
+
+	var r = newRoot("r", sk)
+
+	err := idx.Tx(func(feeds data.Feeds) (err error) {
+		var ps data.Pending
+		if ps, err = feeds.Pending(pk); err != nil {
+			return
+		}
+		if err = ps.Del(nonce, r.Seq); err != nil {
+			return // must not return ErrNotFound
+		}
+		if err = ps.Set(nonce, r, nil); err != nil {
+			return
+		}
+		if err = ps.Del(nonce, r.Seq); err != nil {
+			return
+		}
+		var ok bool
+		if ok, err = ps.Has(nonce, r.Seq); err != nil {
+			return
+		}
+		if ok == true {
+			t.Error("not deleted")
+		}
+		if err = ps.Keys(nonce, r.Seq, nil); err != data.ErrNotFound {
+			t.Error("wrong error:", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	t.Run("feed", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			var ps data.Pending
+			if ps, err = feeds.Pending(pk); err != nil {
+				return
+			}
+			if err = ps.Set(nonce, r, nil); err != nil {
+				return
+			}
+			if err = feeds.Del(pk); err != nil {
+				return
+			}
+			if err = feeds.Add(pk); err != nil {
+				return
+			}
+			if ps, err = feeds.Pending(pk); err != nil {
+				return
+			}
+			if ps.Len() != 0 {
+				t.Error("pending Root of deleted feed")
+			}
+			return
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+}
+
+// PendingIterate is test case for Pending.Iterate
+func PendingIterate(t *testing.T, idx data.IdxDB) {
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	if addFeed(t, idx, pk); t.Failed() {
+		return
+	}
+
+	var rs = []*data.Root{newRoot("a", sk), newRoot("b", sk), newRoot("c", sk)}
+
+	err := idx.Tx(func(feeds data.Feeds) (err error) {
+		var ps data.Pending
+		if ps, err = feeds.Pending(pk); err != nil {
+			return
+		}
+		// nonces: 3, 2, 1
+		for i, r := range rs {
+			if err = ps.Set(uint64(len(rs)-i), r, nil); err != nil {
+				return
+			}
+		}
+		var nonces []uint64
+		err = ps.Iterate(func(nonce uint64, r *data.Root) (err error) {
+			nonces = append(nonces, nonce)
+			return ps.Del(nonce, r.Seq) // mutate
+		})
+		if err != nil {
+			return
+		}
+		if len(nonces) != 3 || nonces[0] != 1 || nonces[2] != 3 {
+			t.Error("wrong order:", nonces)
+		}
+		if ps.Len() != 0 {
+			t.Error("not deleted")
+		}
+		return
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+}
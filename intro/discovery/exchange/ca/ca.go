@@ -57,6 +57,10 @@ func main() {
 
 	c.Public = true
 
+	// find peers of feeds using connected peers, it
+	// works without the discovery server too
+	c.PeerExchange = true
+
 	// use DB in memory for the example
 	c.Config.InMemoryDB = true
 
@@ -26,6 +26,7 @@ const (
 	RPC  string = "[::1]:7002" // default RPC address
 
 	Discovery string = "[::1]:8008" // discovery server
+	Peer      string = "[::1]:8001" // the ca, if the discovery is not running
 )
 
 // interest feeds
@@ -54,9 +55,14 @@ func main() {
 
 	c.TCP.Listen = Bind // listen
 	c.TCP.Discovery = node.Addresses{Discovery}
+	c.TCP.Peers = node.Addresses{Peer} // keep connection to the ca
 
 	c.Public = true
 
+	// find peers of feeds using connected peers, it
+	// works without the discovery server too
+	c.PeerExchange = true
+
 	// use DB in memory for the example
 	c.Config.InMemoryDB = true
 
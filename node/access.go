@@ -0,0 +1,323 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// An AccessList represents list of peers by
+// NodeID (public key) and by network address
+// (CIDR notation). The AccessList implements
+// flag.Value interface. A rule is hex-encoded
+// public key or CIDR, e.g. "192.168.0.0/16"
+type AccessList struct {
+	Peers []cipher.PubKey // NodeIDs
+	Nets  []string        // CIDR notation
+}
+
+// String implements flag.Value interface
+func (a *AccessList) String() string {
+	var rules = make([]string, 0, len(a.Peers)+len(a.Nets))
+	for _, pk := range a.Peers {
+		rules = append(rules, pk.Hex())
+	}
+	return fmt.Sprintf("%v", append(rules, a.Nets...))
+}
+
+// Set implements flag.Value interface
+func (a *AccessList) Set(rule string) (err error) {
+	return a.Add(rule)
+}
+
+// IsBlank returns true if the AccessList is empty
+func (a *AccessList) IsBlank() bool {
+	return len(a.Peers) == 0 && len(a.Nets) == 0
+}
+
+// Add given rule to the AccessList. The rule
+// is hex-encoded public key or CIDR. The Add
+// does nothing if the AccessList already has
+// given rule
+func (a *AccessList) Add(rule string) (err error) {
+
+	var (
+		pk    cipher.PubKey
+		ipnet *net.IPNet
+	)
+
+	if pk, ipnet, err = parseAccessRule(rule); err != nil {
+		return
+	}
+
+	if ipnet != nil {
+		for _, n := range a.Nets {
+			if n == ipnet.String() {
+				return // already have
+			}
+		}
+		a.Nets = append(a.Nets, ipnet.String())
+		return
+	}
+
+	for _, p := range a.Peers {
+		if p == pk {
+			return // already have
+		}
+	}
+
+	a.Peers = append(a.Peers, pk)
+	return
+}
+
+// Remove given rule from the AccessList, it
+// returns false if the AccessList doesn't
+// have the rule
+func (a *AccessList) Remove(rule string) (ok bool, err error) {
+
+	var (
+		pk    cipher.PubKey
+		ipnet *net.IPNet
+	)
+
+	if pk, ipnet, err = parseAccessRule(rule); err != nil {
+		return
+	}
+
+	if ipnet != nil {
+		for i, n := range a.Nets {
+			if n == ipnet.String() {
+				a.Nets = append(a.Nets[:i], a.Nets[i+1:]...)
+				return true, nil
+			}
+		}
+		return
+	}
+
+	for i, p := range a.Peers {
+		if p == pk {
+			a.Peers = append(a.Peers[:i], a.Peers[i+1:]...)
+			return true, nil
+		}
+	}
+
+	return
+}
+
+// Validate the AccessList
+func (a *AccessList) Validate() (err error) {
+
+	for _, pk := range a.Peers {
+		if err = pk.Verify(); err != nil {
+			return fmt.Errorf("invalid public key %s: %v", pk.Hex(), err)
+		}
+	}
+
+	for _, n := range a.Nets {
+		if _, _, err = net.ParseCIDR(n); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// has given peer
+func (a *AccessList) has(pk cipher.PubKey, ip net.IP) (ok bool) {
+
+	for _, p := range a.Peers {
+		if p == pk {
+			return true
+		}
+	}
+
+	if ip == nil {
+		return
+	}
+
+	for _, n := range a.Nets {
+		if _, ipnet, err := net.ParseCIDR(n); err == nil {
+			if ipnet.Contains(ip) == true {
+				return true
+			}
+		}
+	}
+
+	return
+}
+
+// copy of the AccessList
+func (a *AccessList) copy() (c AccessList) {
+	c.Peers = append([]cipher.PubKey{}, a.Peers...)
+	c.Nets = append([]string{}, a.Nets...)
+	return
+}
+
+// parse rule, the rule is public key or CIDR
+func parseAccessRule(
+	rule string, //        :
+) (
+	pk cipher.PubKey, //   : if the rule is public key
+	ipnet *net.IPNet, //   : if the rule is CIDR
+	err error, //          :
+) {
+
+	rule = strings.TrimSpace(rule)
+
+	if strings.Contains(rule, "/") == true {
+		_, ipnet, err = net.ParseCIDR(rule)
+		return
+	}
+
+	if pk, err = cipher.PubKeyFromHex(rule); err != nil {
+		err = fmt.Errorf("invalid rule %q: expected public key or CIDR", rule)
+		return
+	}
+
+	if err = pk.Verify(); err != nil {
+		err = fmt.Errorf("invalid public key %q: %v", rule, err)
+	}
+
+	return
+}
+
+// IP of remote peer of given address or nil
+func addrIP(addr net.Addr) (ip net.IP) {
+
+	switch x := addr.(type) {
+	case *net.TCPAddr:
+		return x.IP
+	case *net.UDPAddr:
+		return x.IP
+	}
+
+	var host, _, err = net.SplitHostPort(addr.String())
+
+	if err != nil {
+		return
+	}
+
+	return net.ParseIP(host)
+}
+
+// access control of the Node
+type accessControl struct {
+	mx    sync.Mutex
+	allow AccessList
+	deny  AccessList
+}
+
+func (a *accessControl) init(allow, deny *AccessList) {
+	a.allow = allow.copy()
+	a.deny = deny.copy()
+}
+
+// check given peer, the Deny list has priority;
+// if Allow list is not blank, then only peers
+// from the list are allowed
+func (a *accessControl) check(pk cipher.PubKey, ip net.IP) (err error) {
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	if a.deny.has(pk, ip) == true {
+		return ErrNotAllowed
+	}
+
+	if a.allow.IsBlank() == true || a.allow.has(pk, ip) == true {
+		return
+	}
+
+	return ErrNotAllowed
+}
+
+// checkAccess of remote peer of given Conn
+// with given verified NodeID; use blank NodeID
+// for peers that don't prove it, to check their
+// IP addresses only
+func (c *Conn) checkAccess(peer cipher.PubKey) (err error) {
+	return c.n.ac.check(peer, addrIP(c.GetRemoteAddr()))
+}
+
+// close connections that are not allowed
+func (n *Node) applyAccess() {
+
+	for _, c := range n.Connections() {
+		if err := c.checkAccess(c.verifiedID()); err != nil {
+			c.close(err)
+		}
+	}
+
+}
+
+// Allow peers by given rule. The rule is hex-encoded
+// public key (NodeID) or CIDR. If list of allowed
+// peers is not empty, then only peers from the list
+// can connect to the Node (and the Node can connect
+// to them only). The Deny list has priority.
+func (n *Node) Allow(rule string) (err error) {
+
+	n.ac.mx.Lock()
+	err = n.ac.allow.Add(rule)
+	n.ac.mx.Unlock()
+
+	if err == nil {
+		n.applyAccess()
+	}
+
+	return
+}
+
+// Deny peers by given rule. The rule is hex-encoded
+// public key (NodeID) or CIDR. Connections with
+// denied peers will be closed
+func (n *Node) Deny(rule string) (err error) {
+
+	n.ac.mx.Lock()
+	err = n.ac.deny.Add(rule)
+	n.ac.mx.Unlock()
+
+	if err == nil {
+		n.applyAccess()
+	}
+
+	return
+}
+
+// RemoveAccessRule removes given rule from lists
+// of allowed and denied peers
+func (n *Node) RemoveAccessRule(rule string) (err error) {
+
+	n.ac.mx.Lock()
+
+	var ok, dok bool
+
+	if ok, err = n.ac.allow.Remove(rule); err == nil {
+		dok, err = n.ac.deny.Remove(rule)
+	}
+
+	n.ac.mx.Unlock()
+
+	if err != nil {
+		return
+	}
+
+	if ok == false && dok == false {
+		return fmt.Errorf("no such rule %q", rule)
+	}
+
+	n.applyAccess() // removing from the Allow list can deny peers
+	return
+}
+
+// AccessLists returns copies of lists of
+// allowed and denied peers
+func (n *Node) AccessLists() (allow, deny AccessList) {
+	n.ac.mx.Lock()
+	defer n.ac.mx.Unlock()
+
+	return n.ac.allow.copy(), n.ac.deny.copy()
+}
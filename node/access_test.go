@@ -0,0 +1,107 @@
+package node
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestAccessList_Add(t *testing.T) {
+
+	var (
+		al     AccessList
+		pk, _  = cipher.GenerateKeyPair()
+		ok     bool
+		err    error
+		local  = net.ParseIP("127.0.0.1")
+		remote = net.ParseIP("10.0.0.1")
+	)
+
+	assertNil(t, al.Add(pk.Hex()))
+	assertNil(t, al.Add("127.0.0.0/8"))
+	assertNil(t, al.Add(pk.Hex())) // twice
+
+	if len(al.Peers) != 1 || len(al.Nets) != 1 {
+		t.Fatal("wrong list", al.String())
+	}
+
+	if al.Add("invalid") == nil {
+		t.Error("missing error")
+	}
+
+	assertTrue(t, al.has(pk, nil), "missing peer")
+	assertTrue(t, al.has(cipher.PubKey{}, local), "missing net")
+	assertTrue(t, al.has(cipher.PubKey{}, remote) == false, "has")
+
+	ok, err = al.Remove("127.0.0.1/8")
+	assertNil(t, err)
+	assertTrue(t, ok, "not removed")
+	assertTrue(t, al.has(cipher.PubKey{}, local) == false, "has")
+
+}
+
+func TestNode_Deny(t *testing.T) {
+
+	var (
+		ln = getTestNode("server")
+		cn = getTestNodeNotListen("client")
+
+		err error
+	)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	assertNil(t, ln.Deny(cn.ID().Hex()))
+
+	if _, err = cn.TCP().Connect(ln.TCP().Address()); err == nil {
+		t.Fatal("connected")
+	}
+
+	assertNil(t, ln.RemoveAccessRule(cn.ID().Hex()))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(ln.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	// close existing connection
+
+	assertNil(t, ln.Deny("127.0.0.0/8"))
+
+	select {
+	case <-c.closeq:
+	case <-time.After(TM):
+		t.Error("not closed")
+	}
+
+}
+
+func TestNode_Allow(t *testing.T) {
+
+	var (
+		ln = getTestNode("server")
+		cn = getTestNodeNotListen("client")
+
+		pk, _ = cipher.GenerateKeyPair()
+		err   error
+	)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	assertNil(t, ln.Allow(pk.Hex())) // only pk
+
+	if _, err = cn.TCP().Connect(ln.TCP().Address()); err == nil {
+		t.Fatal("connected")
+	}
+
+	assertNil(t, ln.Allow("127.0.0.0/8"))
+
+	if _, err = cn.TCP().Connect(ln.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+}
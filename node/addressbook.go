@@ -0,0 +1,477 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+var peersBucket = []byte("p") // known peers
+
+// A KnownPeer represents an address from address book
+// of the Node. The Node remembers addresses it connects
+// to, addresses received from discovery servers and
+// feeds of the addresses. The address book used to
+// bootstrap the Node even if discovery servers can't
+// be reached (see Config.PeersFile and Config.Bootstrap)
+type KnownPeer struct {
+	Network  string          // "tcp", "udp", "unix" or "ws"
+	Address  string          // address of the peer
+	Feeds    []cipher.PubKey // feeds of the peer
+	LastSeen time.Time       // last successful connection, can be zero
+	Failures int             // failed attempts to connect in a row
+}
+
+// encoded KnownPeer
+type knownPeer struct {
+	Feeds    []cipher.PubKey
+	LastSeen int64
+	Failures uint32
+}
+
+// address book of the Node
+type addressBook struct {
+	mx    sync.Mutex
+	db    *bolt.DB              // nil if the book is in memory
+	peers map[string]*KnownPeer // network + address -> peer
+
+	maxFailures int // remove an address after
+
+	// the bootstrap and the peer exchange use lock of
+	// the Node, and the Node waits them before the
+	// lock (see Close)
+	await sync.WaitGroup
+}
+
+// peersFilePath returns path to the address book file; a
+// relative path is relative to DataDir of the Container;
+// the path is blank if the address book is in memory
+func (c *Config) peersFilePath() (path string) {
+
+	path = c.PeersFile
+
+	if path == "" {
+		return
+	}
+
+	if c.Config != nil && c.Config.InMemoryDB == true {
+		return "" // in memory
+	}
+
+	if filepath.IsAbs(path) == true {
+		return
+	}
+
+	if c.Config != nil && c.Config.DataDir != "" {
+		path = filepath.Join(c.Config.DataDir, path)
+	}
+
+	return
+}
+
+// open address book using given file, blank
+// path means that the book is in memory
+func (a *addressBook) open(path string, maxFailures int) (err error) {
+
+	a.peers = make(map[string]*KnownPeer)
+	a.maxFailures = maxFailures
+
+	if path == "" {
+		return // in memory
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err = os.MkdirAll(dir, 0700); err != nil {
+			return
+		}
+	}
+
+	var db *bolt.DB
+
+	db, err = bolt.Open(path, 0644, &bolt.Options{
+		Timeout: time.Millisecond * 500,
+	})
+
+	if err != nil {
+		return
+	}
+
+	err = db.Update(func(tx *bolt.Tx) (err error) {
+
+		var bk *bolt.Bucket
+
+		if bk, err = tx.CreateBucketIfNotExists(peersBucket); err != nil {
+			return
+		}
+
+		return bk.ForEach(func(k, v []byte) (err error) {
+
+			var (
+				network, address = splitPeerKey(string(k))
+				kp               knownPeer
+			)
+
+			if err = encoder.DeserializeRaw(v, &kp); err != nil {
+				return bk.Delete(k) // damaged, forget it
+			}
+
+			var p = &KnownPeer{
+				Network:  network,
+				Address:  address,
+				Feeds:    kp.Feeds,
+				Failures: int(kp.Failures),
+			}
+
+			if kp.LastSeen != 0 {
+				p.LastSeen = time.Unix(0, kp.LastSeen)
+			}
+
+			a.peers[string(k)] = p
+			return
+		})
+	})
+
+	if err != nil {
+		db.Close()
+		return
+	}
+
+	a.db = db
+	return
+}
+
+func splitPeerKey(key string) (network, address string) {
+	if i := strings.Index(key, "://"); i >= 0 {
+		return key[:i], key[i+3:]
+	}
+	return "", key
+}
+
+// save or delete (if p is nil) given
+// peer, should be called under lock
+func (a *addressBook) save(key string, p *KnownPeer) (err error) {
+
+	if a.db == nil {
+		return // in memory
+	}
+
+	return a.db.Update(func(tx *bolt.Tx) (err error) {
+
+		var bk = tx.Bucket(peersBucket)
+
+		if p == nil {
+			return bk.Delete([]byte(key))
+		}
+
+		var kp = knownPeer{
+			Feeds:    p.Feeds,
+			Failures: uint32(p.Failures),
+		}
+
+		if p.LastSeen.IsZero() == false {
+			kp.LastSeen = p.LastSeen.UnixNano()
+		}
+
+		return bk.Put([]byte(key), encoder.Serialize(&kp))
+	})
+}
+
+// get or create peer, should be called under lock
+func (a *addressBook) peer(network, address string) (key string,
+	p *KnownPeer) {
+
+	key = persistentKey(network, address)
+
+	var ok bool
+	if p, ok = a.peers[key]; ok == false {
+		p = &KnownPeer{Network: network, Address: address}
+		a.peers[key] = p
+	}
+
+	return
+}
+
+// add feed of given address
+func (a *addressBook) addFeed(
+	network string, //      :
+	address string, //      :
+	feed cipher.PubKey, //  :
+) (
+	err error, //           :
+) {
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	var key, p = a.peer(network, address)
+
+	for _, pk := range p.Feeds {
+		if pk == feed {
+			return // already have
+		}
+	}
+
+	p.Feeds = append(p.Feeds, feed)
+	return a.save(key, p)
+}
+
+// connected to given address
+func (a *addressBook) seen(network, address string) (err error) {
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	var key, p = a.peer(network, address)
+
+	p.LastSeen, p.Failures = time.Now(), 0
+	return a.save(key, p)
+}
+
+// can't connect to given address, the address
+// removed after too many failures in a row
+func (a *addressBook) failed(network, address string) (err error) {
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	var (
+		key   = persistentKey(network, address)
+		p, ok = a.peers[key]
+	)
+
+	if ok == false {
+		return // unknown address
+	}
+
+	if p.Failures++; a.maxFailures > 0 && p.Failures >= a.maxFailures {
+		delete(a.peers, key)
+		return a.save(key, nil)
+	}
+
+	return a.save(key, p)
+}
+
+// number of failures of given address in a row
+func (a *addressBook) failures(network, address string) (failures int) {
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	if p, ok := a.peers[persistentKey(network, address)]; ok == true {
+		failures = p.Failures
+	}
+
+	return
+}
+
+// remove given address
+func (a *addressBook) forget(network, address string) (ok bool, err error) {
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	var key = persistentKey(network, address)
+
+	if _, ok = a.peers[key]; ok == false {
+		return
+	}
+
+	delete(a.peers, key)
+	return true, a.save(key, nil)
+}
+
+// list of known peers, most reliable first
+func (a *addressBook) list() (list []KnownPeer) {
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	for _, p := range a.peers {
+		var kp = *p
+		kp.Feeds = append([]cipher.PubKey{}, p.Feeds...)
+		list = append(list, kp)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Failures != list[j].Failures {
+			return list[i].Failures < list[j].Failures
+		}
+		if list[i].LastSeen.Equal(list[j].LastSeen) == false {
+			return list[i].LastSeen.After(list[j].LastSeen)
+		}
+		return persistentKey(list[i].Network, list[i].Address) <
+			persistentKey(list[j].Network, list[j].Address)
+	})
+
+	return
+}
+
+func (a *addressBook) close() (err error) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	if a.db != nil {
+		err = a.db.Close()
+		a.db = nil // keep in memory after
+	}
+	return
+}
+
+// remember outgoing connection, the address of
+// an incoming connection is not listening address
+// of the peer, and it's never remembered
+func (n *Node) rememberConn(c *Conn) {
+
+	if c.incoming == true {
+		return
+	}
+
+	if err := n.ab.seen(c.Network(), c.Address()); err != nil {
+		n.Printf("[ERR] can't save address %s to address book: %v",
+			c.String(),
+			err)
+	}
+}
+
+// remember feed of outgoing connection
+func (n *Node) rememberFeed(c *Conn, feed cipher.PubKey) {
+
+	if c.incoming == true {
+		return
+	}
+
+	if err := n.ab.addFeed(c.Network(), c.Address(), feed); err != nil {
+		n.Printf("[ERR] can't save feed of %s to address book: %v",
+			c.String(),
+			err)
+	}
+}
+
+// remember address and feed received from a discovery server
+func (n *Node) rememberDiscovered(network, address string, feed cipher.PubKey) {
+	if err := n.ab.addFeed(network, address, feed); err != nil {
+		n.Printf("[ERR] can't save address %s://%s to address book: %v",
+			network,
+			address,
+			err)
+	}
+}
+
+// can't connect to given address; only network failures
+// are counted, a peer that rejects handshake is reachable
+func (n *Node) rememberFailure(network, address string) {
+	if err := n.ab.failed(network, address); err != nil {
+		n.Printf("[ERR] can't save address %s://%s to address book: %v",
+			network,
+			address,
+			err)
+	}
+}
+
+// KnownPeers returns addresses from address book of the
+// Node. Addresses with less failures and most recently
+// seen are first. See KnownPeer for details
+func (n *Node) KnownPeers() (list []KnownPeer) {
+	return n.ab.list()
+}
+
+// ForgetPeer removes given address from address book
+// of the Node. Existing connection is not closed
+func (n *Node) ForgetPeer(network, address string) (err error) {
+
+	var ok bool
+
+	if ok, err = n.ab.forget(network, address); err != nil {
+		return
+	}
+
+	if ok == false {
+		return ErrNoSuchPeer
+	}
+
+	return
+}
+
+// bootstrap connects to known peers that share feeds
+// of the Container, up to Config.Bootstrap peers, and
+// subscribes to the feeds; the bootstrap doesn't
+// require discovery servers; only addresses the Node
+// has connected to before are used
+func (n *Node) bootstrap() {
+
+	var (
+		feeds = make(map[cipher.PubKey]struct{})
+		count int
+	)
+
+	for _, feed := range n.c.Feeds() {
+		feeds[feed] = struct{}{}
+	}
+
+	if len(feeds) == 0 {
+		return // nothing to bootstrap
+	}
+
+	for _, kp := range n.ab.list() {
+
+		if count >= n.config.Bootstrap {
+			return
+		}
+
+		var common []cipher.PubKey
+
+		for _, feed := range kp.Feeds {
+			if _, ok := feeds[feed]; ok == true {
+				common = append(common, feed)
+			}
+		}
+
+		if len(common) == 0 {
+			continue
+		}
+
+		// never connected, the address is received from
+		// a discovery server or from peer exchange and
+		// it can be fake
+		if kp.LastSeen.IsZero() == true {
+			continue
+		}
+
+		select {
+		case <-n.closeq:
+			return
+		default:
+		}
+
+		var c, err = n.dial(kp.Network, kp.Address) // block
+
+		if err != nil {
+			n.Debugf(ConnPin, "[%s://%s] can't bootstrap from known peer: %v",
+				kp.Network,
+				kp.Address,
+				err)
+			continue
+		}
+
+		count++
+
+		for _, feed := range common {
+			if err = c.Subscribe(feed); err != nil {
+				n.Debugf(ConnPin, "[%s] can't Subscribe to %s: %v",
+					c.String(),
+					feed.Hex()[:7],
+					err)
+			}
+		}
+
+	}
+
+}
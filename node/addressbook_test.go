@@ -0,0 +1,221 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func Test_addressBook(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		path  = dir + "/peers.db"
+		pk, _ = cipher.GenerateKeyPair()
+
+		a addressBook
+	)
+
+	assertNil(t, a.open(path, 2))
+
+	assertNil(t, a.seen("tcp", "127.0.0.1:8000"))
+	assertNil(t, a.addFeed("tcp", "127.0.0.1:8000", pk))
+	assertNil(t, a.addFeed("tcp", "127.0.0.1:8000", pk)) // twice
+	assertNil(t, a.addFeed("udp", "127.0.0.1:8001", pk))
+	assertNil(t, a.failed("udp", "127.0.0.1:8001"))
+
+	assertNil(t, a.close())
+
+	// reopen
+
+	a = addressBook{}
+	assertNil(t, a.open(path, 2))
+	defer a.close()
+
+	var list = a.list()
+
+	if len(list) != 2 {
+		t.Fatal("wrong number of known peers:", len(list))
+	}
+
+	if list[0].Network != "tcp" || list[0].Address != "127.0.0.1:8000" {
+		t.Error("wrong order or address:", list[0].Network, list[0].Address)
+	}
+
+	if list[0].LastSeen.IsZero() == true {
+		t.Error("missing last seen time")
+	}
+
+	if len(list[0].Feeds) != 1 || list[0].Feeds[0] != pk {
+		t.Error("wrong feeds:", list[0].Feeds)
+	}
+
+	if list[1].Failures != 1 {
+		t.Error("wrong failures:", list[1].Failures)
+	}
+
+	// too many failures
+
+	assertNil(t, a.failed("udp", "127.0.0.1:8001"))
+
+	if len(a.list()) != 1 {
+		t.Error("address not removed")
+	}
+
+}
+
+func TestNode_bootstrap(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		ln    = getTestNode("server")
+		pk, _ = cipher.GenerateKeyPair()
+
+		cc = getTestConfigNotListen("client")
+		cn *Node
+	)
+
+	defer ln.Close()
+
+	assertNil(t, ln.Share(pk))
+
+	cc.InMemoryDB = false
+	cc.DataDir = dir
+	cc.PeersFile = "peers.db"
+	cc.Bootstrap = 10
+
+	cn, err = NewNode(cc)
+	assertNil(t, err)
+
+	var c *Conn
+	c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	assertNil(t, c.Subscribe(pk))
+
+	var list = cn.KnownPeers()
+
+	if len(list) != 1 || len(list[0].Feeds) != 1 {
+		t.Fatal("wrong known peers:", list)
+	}
+
+	cn.Close()
+
+	// restart
+
+	cc = getTestConfigNotListen("client")
+	cc.InMemoryDB = false
+	cc.DataDir = dir
+	cc.PeersFile = "peers.db"
+	cc.Bootstrap = 10
+
+	cn, err = NewNode(cc)
+	assertNil(t, err)
+	defer cn.Close()
+
+	waitFor(t, func() bool {
+		return len(cn.ConnectionsOfFeed(pk)) == 1
+	})
+
+	assertNil(t, cn.ForgetPeer("tcp", list[0].Address))
+
+	if cn.ForgetPeer("tcp", list[0].Address) != ErrNoSuchPeer {
+		t.Error("missing ErrNoSuchPeer")
+	}
+
+}
+
+func TestNode_rememberFailure(t *testing.T) {
+
+	var (
+		lc = getTestConfig("server")
+		cc = getTestConfigNotListen("client")
+	)
+
+	lc.TCP.Encryption = EncryptionRequired
+	cc.TCP.Encryption = EncryptionOff
+
+	var ln, err = NewNode(lc)
+	assertNil(t, err)
+	defer ln.Close()
+
+	var cn *Node
+	cn, err = NewNode(cc)
+	assertNil(t, err)
+	defer cn.Close()
+
+	var address = ln.TCP().Address()
+
+	assertNil(t, cn.ab.seen("tcp", address))
+
+	var failures = func() int {
+		var list = cn.KnownPeers()
+		if len(list) != 1 {
+			t.Fatal("wrong known peers:", list)
+		}
+		return list[0].Failures
+	}
+
+	// rejected handshake
+
+	if _, err = cn.TCP().Connect(address); err == nil {
+		t.Fatal("missing error")
+	}
+
+	if f := failures(); f != 0 {
+		t.Error("handshake rejection counted as failure:", f)
+	}
+
+	// network failure
+
+	ln.Close()
+
+	if _, err = cn.TCP().Connect(address); err == nil {
+		t.Fatal("missing error")
+	}
+
+	if f := failures(); f != 1 {
+		t.Error("network failure not counted:", f)
+	}
+
+}
+
+func TestNode_bootstrapUnverified(t *testing.T) {
+
+	var (
+		ln    = getTestNode("server")
+		pk, _ = cipher.GenerateKeyPair()
+
+		cc = getTestConfigNotListen("client")
+	)
+
+	defer ln.Close()
+
+	assertNil(t, ln.Share(pk))
+
+	cc.Bootstrap = 10
+
+	// the address is known, but never connected
+	var cn, err = NewNode(cc)
+	assertNil(t, err)
+
+	assertNil(t, cn.ab.addFeed("tcp", ln.TCP().Address(), pk))
+	assertNil(t, cn.Share(pk))
+
+	cn.bootstrap()
+
+	if len(cn.Connections()) != 0 {
+		t.Error("bootstrap dials unverified address")
+	}
+
+	cn.Close()
+
+}
@@ -0,0 +1,260 @@
+package node
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// A Bandwidth represents upload and download
+// limits in bytes per second. Zero means no
+// limit. The Bandwidth used to limit all
+// connections of the Node together and to
+// limit every connection
+type Bandwidth struct {
+	Upload   int // bytes per second
+	Download int // bytes per second
+}
+
+// Validate the Bandwidth
+func (b *Bandwidth) Validate() (err error) {
+	if b.Upload < 0 {
+		return errors.New("negative upload limit")
+	}
+	if b.Download < 0 {
+		return errors.New("negative download limit")
+	}
+	return
+}
+
+// a tokenBucket represents rate limit, the
+// bucket holds one second of traffic and it's
+// full when a limit set; a message larger than the
+// bucket reserves tokens in debt and next
+// messages wait for the debt
+type tokenBucket struct {
+	mx     sync.Mutex
+	rate   float64   // bytes per second, zero is no limit
+	tokens float64   // available tokens, negative is debt
+	last   time.Time // last refill
+}
+
+func (t *tokenBucket) setRate(rate int) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if t.rate == 0 {
+		t.tokens = float64(rate) // full, since it was unlimited
+	}
+
+	t.rate = float64(rate)
+	t.last = time.Now()
+
+	if t.tokens > t.rate {
+		t.tokens = t.rate
+	}
+}
+
+func (t *tokenBucket) getRate() int {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	return int(t.rate)
+}
+
+// reserve n tokens, it returns time to wait
+// before the n bytes can be sent or received
+func (t *tokenBucket) reserve(n int) (wait time.Duration) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if t.rate == 0 {
+		return // no limit
+	}
+
+	var now = time.Now()
+
+	t.tokens += now.Sub(t.last).Seconds() * t.rate
+	t.last = now
+
+	if t.tokens > t.rate {
+		t.tokens = t.rate
+	}
+
+	if t.tokens -= float64(n); t.tokens >= 0 {
+		return
+	}
+
+	return time.Duration(-t.tokens / t.rate * float64(time.Second))
+}
+
+// a meter of throughput, bytes per second
+type meter struct {
+	mx    sync.Mutex
+	bytes int       // bytes of current period
+	start time.Time // start of current period
+	value float64   // bytes per second of last period
+}
+
+// roll the period if it's over, should
+// be called under lock
+func (m *meter) roll(now time.Time) {
+
+	if m.start.IsZero() == true {
+		m.start = now
+		return
+	}
+
+	var elapsed = now.Sub(m.start)
+
+	if elapsed < time.Second {
+		return
+	}
+
+	m.value = float64(m.bytes) / elapsed.Seconds()
+	m.bytes, m.start = 0, now
+}
+
+func (m *meter) add(n int) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.roll(time.Now())
+	m.bytes += n
+}
+
+func (m *meter) rate() float64 {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.roll(time.Now())
+	return m.value
+}
+
+// a limiter represents upload and download
+// limits and throughput of a Conn or a Node
+type limiter struct {
+	up, down         tokenBucket
+	upMeter, dnMeter meter
+}
+
+func (l *limiter) set(b Bandwidth) {
+	l.up.setRate(b.Upload)
+	l.down.setRate(b.Download)
+}
+
+func (l *limiter) get() (b Bandwidth) {
+	b.Upload = l.up.getRate()
+	b.Download = l.down.getRate()
+	return
+}
+
+// throttle sending (upload is true) or receiving
+// of n bytes, it blocks until the n bytes allowed
+// by limits of the Conn and of the Node; it returns
+// ErrClosed if the Conn closed while waiting
+func (c *Conn) throttle(n int, upload bool) (err error) {
+
+	var wait time.Duration
+
+	if upload == true {
+		c.bw.upMeter.add(n)
+		c.n.bw.upMeter.add(n)
+		wait = maxDuration(c.bw.up.reserve(n), c.n.bw.up.reserve(n))
+	} else {
+		c.bw.dnMeter.add(n)
+		c.n.bw.dnMeter.add(n)
+		wait = maxDuration(c.bw.down.reserve(n), c.n.bw.down.reserve(n))
+	}
+
+	if wait <= 0 {
+		return
+	}
+
+	var tm = time.NewTimer(wait)
+	defer tm.Stop()
+
+	select {
+	case <-tm.C:
+	case <-c.closeq:
+		err = ErrClosed
+	}
+
+	return
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Bandwidth returns limits of the Conn
+func (c *Conn) Bandwidth() (b Bandwidth) {
+	return c.bw.get()
+}
+
+// SetBandwidth changes limits of the Conn. Limits
+// of the Node are applied too
+func (c *Conn) SetBandwidth(b Bandwidth) (err error) {
+	if err = b.Validate(); err != nil {
+		return
+	}
+	c.bw.set(b)
+	return
+}
+
+// Throughput of the Conn, bytes per second
+func (c *Conn) Throughput() (upload, download float64) {
+	return c.bw.upMeter.rate(), c.bw.dnMeter.rate()
+}
+
+// Bandwidth returns limits of all
+// connections of the Node together
+func (n *Node) Bandwidth() (b Bandwidth) {
+	return n.bw.get()
+}
+
+// SetBandwidth changes limits of all
+// connections of the Node together
+func (n *Node) SetBandwidth(b Bandwidth) (err error) {
+	if err = b.Validate(); err != nil {
+		return
+	}
+	n.bw.set(b)
+	return
+}
+
+// ConnBandwidth returns default limits
+// of a connection
+func (n *Node) ConnBandwidth() (b Bandwidth) {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	return n.connbw
+}
+
+// SetConnBandwidth changes default limits of a
+// connection. The limits applied to all existing
+// connections and to new connections
+func (n *Node) SetConnBandwidth(b Bandwidth) (err error) {
+	if err = b.Validate(); err != nil {
+		return
+	}
+
+	n.mx.Lock()
+	n.connbw = b
+	n.mx.Unlock()
+
+	for _, c := range n.Connections() {
+		c.bw.set(b)
+	}
+
+	return
+}
+
+// Throughput of the Node, bytes per second
+func (n *Node) Throughput() (upload, download float64) {
+	return n.bw.upMeter.rate(), n.bw.dnMeter.rate()
+}
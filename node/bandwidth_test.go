@@ -0,0 +1,134 @@
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_tokenBucket(t *testing.T) {
+
+	var tb tokenBucket
+
+	if tb.reserve(1000) != 0 {
+		t.Error("limited without limit")
+	}
+
+	tb.setRate(1000)
+
+	// full bucket
+	if wait := tb.reserve(1000); wait != 0 {
+		t.Error("wrong wait:", wait)
+	}
+
+	// empty bucket
+	if wait := tb.reserve(500); wait < 400*time.Millisecond ||
+		wait > 500*time.Millisecond {
+		t.Error("wrong wait:", wait)
+	}
+
+	// in debt
+	if wait := tb.reserve(500); wait < 900*time.Millisecond ||
+		wait > time.Second {
+		t.Error("wrong wait:", wait)
+	}
+
+	tb.setRate(0)
+
+	if tb.reserve(1000) != 0 {
+		t.Error("limited without limit")
+	}
+
+}
+
+func TestConn_throttle(t *testing.T) {
+
+	var (
+		n = getTestNodeNotListen("test")
+		c = &Conn{n: n, closeq: make(chan struct{})}
+	)
+
+	defer n.Close()
+
+	assertNil(t, c.SetBandwidth(Bandwidth{Upload: 10 * 1024}))
+
+	var tp = time.Now()
+
+	for i := 0; i < 14; i++ {
+		assertNil(t, c.throttle(1024, true))
+	}
+
+	assertNil(t, c.throttle(10*1024, false)) // download is not limited
+
+	if dur := time.Since(tp); dur < 300*time.Millisecond {
+		t.Error("not limited:", dur)
+	}
+
+	// node limit
+
+	assertNil(t, c.SetBandwidth(Bandwidth{}))
+	assertNil(t, n.SetBandwidth(Bandwidth{Download: 10 * 1024}))
+
+	tp = time.Now()
+
+	for i := 0; i < 14; i++ {
+		assertNil(t, c.throttle(1024, false))
+	}
+
+	if dur := time.Since(tp); dur < 300*time.Millisecond {
+		t.Error("not limited:", dur)
+	}
+
+	// closed
+
+	close(c.closeq)
+
+	if c.throttle(10*1024, false) != ErrClosed {
+		t.Error("missing ErrClosed")
+	}
+
+}
+
+func TestNode_SetConnBandwidth(t *testing.T) {
+
+	var (
+		ln = getTestNode("server")
+		cn = getTestNodeNotListen("client")
+
+		bw = Bandwidth{Upload: 1024 * 1024, Download: 2 * 1024 * 1024}
+	)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	assertNil(t, cn.SetConnBandwidth(bw))
+
+	if c.Bandwidth() != bw {
+		t.Error("limits of existing connection not changed")
+	}
+
+	if cn.ConnBandwidth() != bw {
+		t.Error("wrong default limits")
+	}
+
+	if cn.SetConnBandwidth(Bandwidth{Upload: -1}) == nil {
+		t.Error("missing error")
+	}
+
+	var s = cn.Stat()
+
+	if len(s.Connections) != 1 {
+		t.Fatal("wrong number of connections:", len(s.Connections))
+	}
+
+	if s.Connections[0].PeerID != ln.ID() {
+		t.Error("wrong PeerID")
+	}
+
+	if s.Connections[0].Bandwidth != bw {
+		t.Error("wrong limits")
+	}
+
+}
@@ -0,0 +1,196 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/golang/snappy"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// compression
+//
+// if both peers support msg.FeatureCompression, then
+// values of Root, Roots, Object and Objects messages are
+// prefixed with one byte
+//
+//     [1 byte] - compression of the value
+//     [ .... ] - the value, compressed or not
+//
+// a value sent compressed only if it's shorter
+// after compression (e.g. short or random data sent
+// as is)
+
+// compression of a value
+const (
+	compressionNone   byte = iota // not compressed
+	compressionSnappy             // snappy compressed
+)
+
+// features of the Node
+func (n *Node) features() (f msg.Features) {
+	f = msg.Supported
+	if n.config.Compression == false {
+		f &^= msg.FeatureCompression
+	}
+	return
+}
+
+// compress given value, the saved is
+// number of bytes saved by the compression
+func compressValue(val []byte) (cv []byte, saved int) {
+
+	var sv = snappy.Encode(nil, val)
+
+	if len(sv) < len(val) {
+		return append([]byte{compressionSnappy}, sv...), len(val) - len(sv)
+	}
+
+	return append([]byte{compressionNone}, val...), 0
+}
+
+// decompress given value, the limit is max size
+// of decompressed value, the saved is number of
+// bytes saved by the compression
+func decompressValue(cv []byte, limit int) (val []byte, saved int, err error) {
+
+	if len(cv) == 0 {
+		return nil, 0, errors.New("missing compression prefix")
+	}
+
+	switch cv[0] {
+
+	case compressionNone:
+
+		return cv[1:], 0, nil
+
+	case compressionSnappy:
+
+		var n int
+
+		if n, err = snappy.DecodedLen(cv[1:]); err != nil {
+			return
+		}
+
+		if n > limit {
+			err = fmt.Errorf("decompressed value too large: %d > %d", n, limit)
+			return
+		}
+
+		if val, err = snappy.Decode(nil, cv[1:]); err != nil {
+			return
+		}
+
+		return val, len(val) - len(cv) + 1, nil
+
+	}
+
+	return nil, 0, fmt.Errorf("unknown compression: %d", cv[0])
+}
+
+// compress returns message with compressed values
+// if the message is Root, Roots, Object or Objects; the
+// original message is not changed
+func (c *Conn) compress(m msg.Msg) msg.Msg {
+
+	var total, saved int
+
+	switch x := m.(type) {
+
+	case *msg.Root:
+
+		var root = *x
+		root.Value, saved = compressValue(x.Value)
+		m, total = &root, saved
+
+	case *msg.Object:
+
+		var obj msg.Object
+		obj.Value, saved = compressValue(x.Value)
+		m, total = &obj, saved
+
+	case *msg.Objects:
+
+		var objs msg.Objects
+		objs.Values = make([][]byte, 0, len(x.Values))
+
+		for _, val := range x.Values {
+			val, saved = compressValue(val)
+			objs.Values = append(objs.Values, val)
+			total += saved
+		}
+
+		m = &objs
+
+	case *msg.Roots:
+
+		var roots = *x
+		roots.Roots = make([]msg.Root, 0, len(x.Roots))
+
+		for _, root := range x.Roots {
+			root.Value, saved = compressValue(root.Value)
+			roots.Roots = append(roots.Roots, root)
+			total += saved
+		}
+
+		m = &roots
+
+	}
+
+	if total > 0 {
+		atomic.AddInt64(&c.n.sentSaved, int64(total))
+	}
+
+	return m
+}
+
+// decompress values of given message if it's
+// Root, Roots, Object or Objects
+func (c *Conn) decompress(m msg.Msg) (err error) {
+
+	var (
+		limit = c.n.c.Config().MaxObjectSize
+		total int
+		saved int
+	)
+
+	switch x := m.(type) {
+
+	case *msg.Root:
+
+		x.Value, total, err = decompressValue(x.Value, limit)
+
+	case *msg.Object:
+
+		x.Value, total, err = decompressValue(x.Value, limit)
+
+	case *msg.Objects:
+
+		for i, val := range x.Values {
+			if x.Values[i], saved, err = decompressValue(val, limit); err != nil {
+				return
+			}
+			total += saved
+		}
+
+	case *msg.Roots:
+
+		for i := range x.Roots {
+			x.Roots[i].Value, saved, err = decompressValue(x.Roots[i].Value,
+				limit)
+			if err != nil {
+				return
+			}
+			total += saved
+		}
+
+	}
+
+	if err == nil && total > 0 {
+		atomic.AddInt64(&c.n.receivedSaved, int64(total))
+	}
+
+	return
+}
@@ -0,0 +1,113 @@
+package node
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func Test_compressValue(t *testing.T) {
+
+	var text = bytes.Repeat([]byte("compressible text "), 100)
+
+	var cv, saved = compressValue(text)
+
+	if cv[0] != compressionSnappy || saved <= 0 {
+		t.Error("not compressed")
+	}
+
+	var val, rsaved, err = decompressValue(cv, len(text))
+	assertNil(t, err)
+
+	if bytes.Equal(val, text) == false {
+		t.Error("wrong decompressed value")
+	}
+
+	if rsaved != saved {
+		t.Errorf("wrong saved %d, want %d", rsaved, saved)
+	}
+
+	// limit
+
+	if _, _, err = decompressValue(cv, len(text)-1); err == nil {
+		t.Error("missing error")
+	}
+
+	// random data sent as is
+
+	var random = cipher.RandByte(128)
+
+	if cv, saved = compressValue(random); cv[0] != compressionNone {
+		t.Error("compressed random data")
+	} else if saved != 0 {
+		t.Error("wrong saved:", saved)
+	}
+
+	if val, _, err = decompressValue(cv, 1024); err != nil {
+		t.Error(err)
+	} else if bytes.Equal(val, random) == false {
+		t.Error("wrong value")
+	}
+
+}
+
+func TestConn_compress(t *testing.T) {
+
+	var (
+		n = getTestNodeNotListen("test")
+		c = &Conn{n: n, features: msg.Supported}
+
+		text = bytes.Repeat([]byte("compressible text "), 100)
+	)
+
+	defer n.Close()
+
+	var raw = c.encodeMsg(1, 0, &msg.Objects{Values: [][]byte{text, text}})
+
+	if len(raw) >= 2*len(text) {
+		t.Error("not compressed")
+	}
+
+	var _, _, m, err = c.decodeRaw(raw)
+	assertNil(t, err)
+
+	var objs, ok = m.(*msg.Objects)
+
+	if ok == false {
+		t.Fatalf("wrong type %T", m)
+	}
+
+	if len(objs.Values) != 2 {
+		t.Fatal("wrong number of values:", len(objs.Values))
+	}
+
+	for _, val := range objs.Values {
+		if bytes.Equal(val, text) == false {
+			t.Error("wrong value")
+		}
+	}
+
+	var s = n.Stat()
+
+	if s.SentSaved <= 0 || s.SentSaved != s.ReceivedSaved {
+		t.Error("wrong stat:", s.SentSaved, s.ReceivedSaved)
+	}
+
+	// turned off
+
+	n.config.Compression = false
+
+	if c.Supports(msg.FeatureCompression) == true {
+		t.Error("compression is turned off")
+	}
+
+	raw = c.encodeMsg(1, 0, &msg.Object{Value: text})
+
+	if len(raw) < len(text) {
+		t.Error("compressed")
+	}
+
+}
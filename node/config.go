@@ -14,16 +14,54 @@ import (
 
 // default configurations
 const (
-	Prefix          string        = "[node] "
-	MaxConnections  int           = 1000 * 1000
-	MaxFillingTime  time.Duration = 10 * time.Minute
-	MaxHeads        int           = 10
-	ListenTCP       string        = ":8870"
-	ListenUDP       string        = "" // don't listen
-	RPCAddress      string        = ":8871"
-	ResponseTimeout time.Duration = 59 * time.Second
-	Pings           time.Duration = 118 * time.Second
-	Public          bool          = false
+	Prefix            string         = "[node] "
+	MaxConnections    int            = 1000 * 1000
+	MaxFillingTime    time.Duration  = 10 * time.Minute
+	HedgeDelay        time.Duration  = 2 * time.Second
+	DeltaSync         bool           = false
+	FillHistory       bool           = false
+	RefuseEquivocated bool           = false
+	MaxHeads          int            = 10
+	ListenTCP         string         = ":8870"
+	ListenUDP         string         = "" // don't listen
+	ListenUnix        string         = "" // don't listen
+	ListenWS          string         = "" // don't listen
+	RPCAddress        string         = ":8871"
+	ResponseTimeout   time.Duration  = 59 * time.Second
+	Pings             time.Duration  = 118 * time.Second
+	Public            bool           = false
+	Encryption        EncryptionMode = EncryptionOptional
+	AcceptLegacy      bool           = false
+	Compression       bool           = true
+	LocalInterval     time.Duration  = 10 * time.Second
+)
+
+// default quotas of a remote peer
+const (
+	QuotaObjects  int           = 0 // no limit (see Bandwidth)
+	QuotaPreviews int           = 10
+	QuotaSubs     int           = 100
+	QuotaRoots    int           = 100
+	QuotaMaxDelay time.Duration = 10 * time.Second
+)
+
+// default backoff of persistent peers
+const (
+	ReconnectMin time.Duration = 1 * time.Second
+	ReconnectMax time.Duration = 5 * time.Minute
+)
+
+// default address book configurations
+const (
+	PeersFile       string = "" // in memory
+	Bootstrap       int    = 0  // don't bootstrap
+	MaxPeerFailures int    = 10
+)
+
+// default peer exchange configurations
+const (
+	PeerExchange      bool = false
+	PeerExchangeLimit int  = 8
 )
 
 // Addresses are discovery addresses
@@ -40,6 +78,72 @@ func (a *Addresses) Set(addr string) error {
 	return nil
 }
 
+// An EncryptionMode represents encryption mode of
+// connections. The mode is one of EncryptionOff,
+// EncryptionOptional and EncryptionRequired. A
+// connection is encrypted if both peers don't
+// turn the encryption off. If one of peers turns
+// the encryption off and another one requires it,
+// then connection will be rejected during handshake
+type EncryptionMode uint8
+
+// possible encryption modes
+const (
+	EncryptionOff      EncryptionMode = iota // never encrypt
+	EncryptionOptional                       // encrypt if peer supports
+	EncryptionRequired                       // reject plain connections
+)
+
+// String implements flag.Value interface
+func (e *EncryptionMode) String() string {
+	switch *e {
+	case EncryptionOff:
+		return "off"
+	case EncryptionOptional:
+		return "optional"
+	case EncryptionRequired:
+		return "required"
+	}
+	return fmt.Sprintf("EncryptionMode<%d>", uint8(*e))
+}
+
+// Set implements flag.Value interface
+func (e *EncryptionMode) Set(mode string) error {
+	switch mode {
+	case "off":
+		*e = EncryptionOff
+	case "optional":
+		*e = EncryptionOptional
+	case "required":
+		*e = EncryptionRequired
+	default:
+		return fmt.Errorf("unknown encryption mode %q", mode)
+	}
+	return nil
+}
+
+// negotiate encryption with mode of remote peer
+func (e EncryptionMode) negotiate(
+	peer EncryptionMode, // : mode of remote peer
+) (
+	encrypt bool, //        : use encryption
+	err error, //           : incompatible modes
+) {
+
+	if e > EncryptionRequired || peer > EncryptionRequired {
+		return false, fmt.Errorf("invalid encryption mode: %d", peer)
+	}
+
+	if e == EncryptionOff || peer == EncryptionOff {
+		if e == EncryptionRequired || peer == EncryptionRequired {
+			return false, ErrEncryptionRequired
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
+
 // OnRootReceivedFunc represents callback that
 // called when new Root objects received. It's
 // possible to reject a receved Root returning
@@ -68,11 +172,54 @@ type OnRootFilledFunc func(n *Node, r *registry.Root)
 // called when a new Root object can't be filled.
 // The callback called with non-full Root (that
 // can't be used), and with filling error.
-// There is no way to resume the filling. If
-// a remote peer push this Root object again,
-// then the Root can be filled (or can be not).
+// Objects of the Root already received are kept
+// and the Root becomes pending (see IsPending and
+// Pending methods of skyobject.Container). The
+// filling continues, requesting missing objects
+// only, when a connection subscribes to the feed
+// (e.g. after restart) or when a remote peer push
+// this Root object again.
 type OnFillingBreaksFunc func(n *Node, r *registry.Root, err error)
 
+// OnFillingProgressFunc represents callback that
+// called every time objects of a filling Root
+// received. The callback called from goroutine
+// that fills the Root and should not block
+type OnFillingProgressFunc func(n *Node, fp *FillingProgress)
+
+// FillPriorityFunc represents callback that called
+// before filling of a Root. It returns elements of
+// Root.Refs that should be filled first or nil to
+// fill the Root in usual order. Objects of the
+// prioritized elements are requested from peers
+// ahead of the rest. See also skyobject.FillPriority
+// for details
+type FillPriorityFunc func(
+	n *Node, //                    : the Node
+	r *registry.Root, //           : the Root to fill
+) (
+	fp *skyobject.FillPriority, // : priority or nil
+)
+
+// OnPriorityFilledFunc represents callback that
+// called when prioritized element of Root.Refs
+// filled. The Root is not full yet, but prioritized
+// objects of the element are in DB and can be used
+// (e.g. using Pack). The callback called from a
+// goroutine of the filling and should not block
+type OnPriorityFilledFunc func(n *Node, r *registry.Root, i int)
+
+// OnEquivocationFunc represents callback that
+// called when owner of a feed signs two different
+// Root objects with the same nonce and seq. The
+// Conn is connection the conflicting Root received
+// from. Both Root objects are saved as evidence
+// (see Equivocations method of skyobject.Container).
+// The callback called once per nonce and seq, when
+// the equivocation saved; use RefuseEquivocated to
+// drop further Root objects of the head
+type OnEquivocationFunc func(n *Node, c *Conn, e *skyobject.Equivocation)
+
 // OnConnectFunc represents callback that called
 // when a connection created and established. It's
 // possible to terminate connection returning error
@@ -103,12 +250,15 @@ type OnSubscribeRemoteFunc func(c *Conn, feed cipher.PubKey) (reject error)
 type OnUnsubscribeRemoteFunc func(c *Conn, feed cipher.PubKey)
 
 // NetConfig represents configurations of
-// a TCP or UDP network
+// a TCP, UDP, Unix or WebSocket network. The
+// Discovery and the LocalDiscovery are used
+// by TCP and UDP only
 type NetConfig struct {
 	// Listen is listening address. Blank string
 	// disables listening. Use ":0" to listen on all
 	// interfaces (and ipv4 + ipv6 if possible) with
-	// OS-choosed ports.
+	// OS-choosed ports. For Unix network it's path
+	// to socket file
 	Listen string
 
 	// Discovery is lsit of addresses of a discovery
@@ -137,6 +287,34 @@ type NetConfig struct {
 	// response for a ping, then connection will be
 	// closed with ErrTimeout.
 	Pings time.Duration
+
+	// LocalDiscovery is UDP multicast address (for
+	// example "239.255.42.99:8872") used to find peers
+	// in local network without a discovery server. If
+	// the network is listening, then the Node announces
+	// its NodeID, listening address and shared feeds
+	// every LocalInterval. And the Node connects to
+	// announced peers that share feeds of the Node and
+	// subscribes to the feeds. Blank string disables
+	// the local discovery
+	LocalDiscovery string
+
+	// LocalInterval is interval of announcements
+	// of the local discovery
+	LocalInterval time.Duration
+
+	// Peers is list of addresses of persistent peers.
+	// The Node keeps connections to the peers, e.g.
+	// it redials a peer if connection closed. See
+	// (*Node).AddPersistentPeer for details
+	Peers Addresses
+
+	// Encryption is encryption mode of connections.
+	// An encrypted connection uses AES-GCM with keys
+	// derived from ECDH shared secret of NodeIDs of
+	// peers and random challenges of the handshake.
+	// See EncryptionMode for details.
+	Encryption EncryptionMode
 }
 
 // A Config represents configurations
@@ -187,10 +365,60 @@ type Config struct {
 	// limit.
 	MaxFillingTime time.Duration
 
+	// HedgeDelay is minimal time to wait for response
+	// for objects requested during filling. If a peer
+	// doesn't respond for the HedgeDelay or for two its
+	// average latencies, then the same request will be
+	// sent to another peer too, and the first response
+	// used. Set it to zero to don't duplicate requests.
+	// See also (*Conn).Score
+	HedgeDelay time.Duration
+
+	// DeltaSync turns on delta sync. The Node compares
+	// a filling Root with last full Root of the same
+	// head and skips branches with the same hashes
+	// without loading them. Number of skipped subtrees,
+	// estimated number of objects of the subtrees and
+	// number of received objects are reported by
+	// (*Node).Stat
+	DeltaSync bool
+
+	// FillHistory turns on filling of historical Root
+	// objects received by (*Conn).RequestHistory. If
+	// it's false, then the RequestHistory verifies
+	// received Root objects and saves them as
+	// historical, but doesn't fill them. Only full
+	// Root objects can be used and sent to other
+	// peers
+	FillHistory bool
+
+	// RefuseEquivocated turns on refusing of Root
+	// objects of equivocated heads. If owner of a
+	// feed signs two different Root objects with
+	// the same nonce and seq, then the Node drops
+	// all further Root objects of the head. Already
+	// saved Root objects are kept
+	RefuseEquivocated bool
+
 	// RPC is RPC listening address. Empty string
 	// disables RPC.
 	RPC string
 
+	// KeyFile is path to file with secret key of
+	// NodeID. If the path is relative, then it's
+	// relative to DataDir of the Container. If the
+	// file doesn't exist, then it will be created
+	// with new random keys. Blank string means that
+	// the Node uses new random NodeID every start.
+	KeyFile string
+
+	// Compression turns on snappy compression of
+	// objects and Root objects for peers that
+	// support it. The compression is negotiated
+	// during handshake. Bytes saved by the
+	// compression are reported by (*Node).Stat
+	Compression bool
+
 	//
 	// Networks
 	//
@@ -201,6 +429,139 @@ type Config struct {
 	// UDP configurations
 	UDP NetConfig
 
+	// Unix configurations, the Unix domain sockets
+	// used by applications of the same host
+	Unix NetConfig
+
+	// WS configurations, the WebSockets used
+	// through HTTP reverse proxies and by light
+	// clients. Addresses of WS peers are URLs
+	// like "wss://example.com/cxo" or host:port
+	WS NetConfig
+
+	// WSOrigins is list of allowed origins of WebSocket
+	// connections from browsers, e.g. "https://example.com".
+	// The "*" allows any origin. Blank list allows the
+	// same origin only (default). Connections without
+	// the Origin header (not from browsers) are allowed
+	WSOrigins Addresses
+
+	//
+	// Persistent peers
+	//
+
+	// ReconnectMin is delay before first attempt to
+	// redial a persistent peer. Every next failed
+	// attempt doubles the delay up to ReconnectMax.
+	// The delay has random jitter
+	ReconnectMin time.Duration
+
+	// ReconnectMax is max delay between
+	// attempts to redial a persistent peer
+	ReconnectMax time.Duration
+
+	//
+	// Address book
+	//
+
+	// PeersFile is path to file of address book
+	// of the Node. The address book keeps known
+	// addresses, their feeds, last seen time and
+	// number of failed attempts to connect. If the
+	// path is relative, then it's relative to
+	// DataDir of the Container. Blank string or
+	// InMemoryDB keep the address book in memory.
+	// It's blank by default, set it to "peers.db",
+	// for example, to keep known addresses on disk.
+	// See (*Node).KnownPeers for details
+	PeersFile string
+
+	// Bootstrap is max number of known peers the
+	// Node connects to on start. The Node connects
+	// to known peers that share feeds of the
+	// Container and subscribes to the feeds. It works even if
+	// discovery servers can't be reached. It's zero
+	// by default, that disables the bootstrap
+	Bootstrap int
+
+	// MaxPeerFailures is number of failed attempts
+	// to connect in a row after which an address
+	// removed from the address book. Set it to zero
+	// to never remove addresses
+	MaxPeerFailures int
+
+	//
+	// Peer exchange
+	//
+
+	// PeerExchange turns on peer exchange. The Node
+	// subscribes outgoing connections to its feeds,
+	// requests addresses of peers that share the
+	// feeds (see (*Conn).RemotePeers) and connects
+	// to some of them, skipping addresses it has
+	// failed to connect to. The peer exchange works
+	// without a discovery server. A Node answers the
+	// requests only if it's public (see Public below)
+	PeerExchange bool
+
+	// PeerExchangeLimit is number of connections of
+	// a feed after which the peer exchange doesn't
+	// connect to new peers of the feed
+	PeerExchangeLimit int
+
+	//
+	// Access control
+	//
+
+	// Allow is list of allowed peers. If the list
+	// is not empty, then only peers from the list
+	// can be connected. A peer is allowed if its
+	// NodeID or its IP address is in the list. The
+	// Allow list can be changed at runtime (see
+	// (*Node).Allow, (*Node).RemoveAccessRule)
+	Allow AccessList
+
+	// Deny is list of denied peers. The Deny list
+	// has priority over the Allow list. The Deny
+	// list can be changed at runtime (see
+	// (*Node).Deny, (*Node).RemoveAccessRule)
+	Deny AccessList
+
+	// AcceptLegacy turns on accepting of peers of
+	// the msg.LegacyVersion. Such peers don't prove
+	// their NodeIDs in handshake, and anyone can
+	// claim any NodeID using the version. Rules of
+	// the Allow and the Deny lists by NodeID and
+	// rules of the Policy for particular peers are
+	// never matched against such peers. See also
+	// (*Conn).IsAuthenticated
+	AcceptLegacy bool
+
+	//
+	// Bandwidth
+	//
+
+	// Bandwidth is upload and download limits of
+	// all connections of the Node together. Zero
+	// values are no limits. The limits can be
+	// changed at runtime (see (*Node).SetBandwidth)
+	Bandwidth Bandwidth
+
+	// ConnBandwidth is default upload and download
+	// limits of a connection. Zero values are no
+	// limits. The limits can be changed at runtime
+	// for all connections (see (*Node).SetConnBandwidth)
+	// and for a connection (see (*Conn).SetBandwidth)
+	ConnBandwidth Bandwidth
+
+	//
+	// Flood protection
+	//
+
+	// Quota is limits of messages a remote peer
+	// can send per second. See Quota for details
+	Quota Quota
+
 	//
 	// Connection callbacks
 	//
@@ -227,9 +588,17 @@ type Config struct {
 	Public bool
 
 	//
-	// Subscription related callbacks
+	// Subscription related
 	//
 
+	// Policy is subscription policy. If the Policy
+	// is not nil, then remote subscriptions the
+	// Policy doesn't allow are rejected before the
+	// OnSubscribeRemote callback. The Policy can
+	// be changed at runtime using RPC. See Policy
+	// for details
+	Policy *Policy
+
 	// OnSubscribeRemote is callback for remote
 	// subscriptions. See OnSubscribeRemote for
 	// details
@@ -263,6 +632,27 @@ type Config struct {
 	// when new Root object filled and can be
 	// used. See OnRootFilledFunc for details.
 	OnFillingBreaks OnFillingBreaksFunc
+
+	// OnFillingProgress is a callback that called
+	// when objects of a filling Root received. See
+	// OnFillingProgressFunc for details.
+	OnFillingProgress OnFillingProgressFunc
+
+	// FillPriority is a callback that used to
+	// get priority of filling of a Root. See
+	// FillPriorityFunc for details.
+	FillPriority FillPriorityFunc
+
+	// OnPriorityFilled is a callback that called
+	// when prioritized element of a filling Root
+	// filled. See OnPriorityFilledFunc for details.
+	OnPriorityFilled OnPriorityFilledFunc
+
+	// OnEquivocation is a callback that called
+	// when two different Root objects with the
+	// same nonce and seq received. See
+	// OnEquivocationFunc for details.
+	OnEquivocation OnEquivocationFunc
 }
 
 // NewConfig returns new Config with
@@ -280,18 +670,54 @@ func NewConfig() (c *Config) {
 	// node
 	c.MaxConnections = MaxConnections
 	c.MaxFillingTime = MaxFillingTime
+	c.HedgeDelay = HedgeDelay
+	c.DeltaSync = DeltaSync
+	c.FillHistory = FillHistory
+	c.RefuseEquivocated = RefuseEquivocated
 	c.MaxHeads = MaxHeads
 
 	c.TCP.Listen = ListenTCP
 	c.TCP.Pings = Pings
 	c.TCP.ResponseTimeout = ResponseTimeout
+	c.TCP.Encryption = Encryption
+	c.TCP.LocalInterval = LocalInterval
 
 	c.UDP.Listen = ListenUDP
 	c.UDP.ResponseTimeout = ResponseTimeout
+	c.UDP.Encryption = Encryption
+	c.UDP.LocalInterval = LocalInterval
+
+	c.Unix.Listen = ListenUnix
+	c.Unix.ResponseTimeout = ResponseTimeout
+	c.Unix.Encryption = Encryption
+
+	c.WS.Listen = ListenWS
+	c.WS.Pings = Pings
+	c.WS.ResponseTimeout = ResponseTimeout
+	c.WS.Encryption = Encryption
 
 	c.RPC = RPCAddress
 	c.Public = Public
 
+	c.ReconnectMin = ReconnectMin
+	c.ReconnectMax = ReconnectMax
+
+	c.PeersFile = PeersFile
+	c.Bootstrap = Bootstrap
+	c.MaxPeerFailures = MaxPeerFailures
+
+	c.PeerExchange = PeerExchange
+	c.PeerExchangeLimit = PeerExchangeLimit
+
+	c.AcceptLegacy = AcceptLegacy
+	c.Compression = Compression
+
+	c.Quota.Objects = QuotaObjects
+	c.Quota.Previews = QuotaPreviews
+	c.Quota.Subs = QuotaSubs
+	c.Quota.Roots = QuotaRoots
+	c.Quota.MaxDelay = QuotaMaxDelay
+
 	return
 
 }
@@ -333,6 +759,26 @@ func (c *Config) FromFlags() {
 		c.MaxFillingTime,
 		"max time to fill a Root")
 
+	flag.DurationVar(&c.HedgeDelay,
+		"hedge-delay",
+		c.HedgeDelay,
+		"duplicate slow object requests after, 0 - don't duplicate")
+
+	flag.BoolVar(&c.DeltaSync,
+		"delta-sync",
+		c.DeltaSync,
+		"compare filling Root with previous one and skip the same branches")
+
+	flag.BoolVar(&c.FillHistory,
+		"fill-history",
+		c.FillHistory,
+		"fill and save historical Root objects requested from peers")
+
+	flag.BoolVar(&c.RefuseEquivocated,
+		"refuse-equivocated",
+		c.RefuseEquivocated,
+		"drop Root objects of heads with equivocations")
+
 	flag.IntVar(&c.MaxHeads,
 		"max-heads",
 		c.MaxHeads,
@@ -343,6 +789,16 @@ func (c *Config) FromFlags() {
 		c.RPC,
 		"RPC listening address")
 
+	flag.StringVar(&c.KeyFile,
+		"key-file",
+		c.KeyFile,
+		"file with secret key of node id, relative to data-dir")
+
+	flag.BoolVar(&c.Compression,
+		"compression",
+		c.Compression,
+		"compress objects for peers that support it")
+
 	// TCP
 
 	flag.StringVar(&c.TCP.Listen,
@@ -360,6 +816,19 @@ func (c *Config) FromFlags() {
 		c.TCP.Pings,
 		"pings interval of TCP connections")
 
+	flag.StringVar(&c.TCP.LocalDiscovery,
+		"tcp-local-discovery",
+		c.TCP.LocalDiscovery,
+		"multicast address to find TCP peers in local network")
+
+	flag.Var(&c.TCP.Peers,
+		"tcp-peer",
+		"address of persistent TCP peer, can be used many times")
+
+	flag.Var(&c.TCP.Encryption,
+		"tcp-encryption",
+		"encryption of TCP connections: off, optional or required")
+
 	// UDP
 
 	flag.StringVar(&c.UDP.Listen,
@@ -377,6 +846,178 @@ func (c *Config) FromFlags() {
 		c.UDP.Pings,
 		"pings interval of UDP connections")
 
+	flag.StringVar(&c.UDP.LocalDiscovery,
+		"udp-local-discovery",
+		c.UDP.LocalDiscovery,
+		"multicast address to find UDP peers in local network")
+
+	flag.Var(&c.UDP.Peers,
+		"udp-peer",
+		"address of persistent UDP peer, can be used many times")
+
+	flag.Var(&c.UDP.Encryption,
+		"udp-encryption",
+		"encryption of UDP connections: off, optional or required")
+
+	// Unix
+
+	flag.StringVar(&c.Unix.Listen,
+		"unix",
+		c.Unix.Listen,
+		"unix socket listening path")
+
+	flag.DurationVar(&c.Unix.ResponseTimeout,
+		"unix-response-timeout",
+		c.Unix.ResponseTimeout,
+		"response timeout of Unix connections")
+
+	flag.DurationVar(&c.Unix.Pings,
+		"unix-pings",
+		c.Unix.Pings,
+		"pings interval of Unix connections")
+
+	flag.Var(&c.Unix.Peers,
+		"unix-peer",
+		"path of persistent Unix peer, can be used many times")
+
+	flag.Var(&c.Unix.Encryption,
+		"unix-encryption",
+		"encryption of Unix connections: off, optional or required")
+
+	// WS
+
+	flag.StringVar(&c.WS.Listen,
+		"ws",
+		c.WS.Listen,
+		"websocket listening address")
+
+	flag.DurationVar(&c.WS.ResponseTimeout,
+		"ws-response-timeout",
+		c.WS.ResponseTimeout,
+		"response timeout of WebSocket connections")
+
+	flag.DurationVar(&c.WS.Pings,
+		"ws-pings",
+		c.WS.Pings,
+		"pings interval of WebSocket connections")
+
+	flag.Var(&c.WS.Peers,
+		"ws-peer",
+		"URL of persistent WebSocket peer, can be used many times")
+
+	flag.Var(&c.WS.Encryption,
+		"ws-encryption",
+		"encryption of WebSocket connections: off, optional or required")
+
+	flag.Var(&c.WSOrigins,
+		"ws-origin",
+		"allowed origin of WebSocket connections, can be used many times")
+
+	// persistent peers
+
+	flag.DurationVar(&c.ReconnectMin,
+		"reconnect-min",
+		c.ReconnectMin,
+		"min delay before redialing a persistent peer")
+
+	flag.DurationVar(&c.ReconnectMax,
+		"reconnect-max",
+		c.ReconnectMax,
+		"max delay before redialing a persistent peer")
+
+	// address book
+
+	flag.StringVar(&c.PeersFile,
+		"peers-file",
+		c.PeersFile,
+		"file of address book, relative to data-dir, blank - in memory (default)")
+
+	flag.IntVar(&c.Bootstrap,
+		"bootstrap",
+		c.Bootstrap,
+		"max known peers to connect to on start, 0 - don't bootstrap (default)")
+
+	flag.IntVar(&c.MaxPeerFailures,
+		"max-peer-failures",
+		c.MaxPeerFailures,
+		"forget an address after the failures in a row, 0 - never")
+
+	// peer exchange
+
+	flag.BoolVar(&c.PeerExchange,
+		"peer-exchange",
+		c.PeerExchange,
+		"find peers of feeds using connected peers")
+
+	flag.IntVar(&c.PeerExchangeLimit,
+		"peer-exchange-limit",
+		c.PeerExchangeLimit,
+		"connections of a feed the peer exchange establishes up to")
+
+	// access control
+
+	flag.Var(&c.Allow,
+		"allow",
+		"allow peer by public key or CIDR, can be used many times")
+
+	flag.Var(&c.Deny,
+		"deny",
+		"deny peer by public key or CIDR, can be used many times")
+
+	flag.BoolVar(&c.AcceptLegacy,
+		"accept-legacy",
+		c.AcceptLegacy,
+		"accept peers of protocol version 3 that don't prove their IDs")
+
+	// bandwidth
+
+	flag.IntVar(&c.Bandwidth.Upload,
+		"upload-limit",
+		c.Bandwidth.Upload,
+		"upload limit of all connections, bytes per second, 0 - no limit")
+
+	flag.IntVar(&c.Bandwidth.Download,
+		"download-limit",
+		c.Bandwidth.Download,
+		"download limit of all connections, bytes per second, 0 - no limit")
+
+	flag.IntVar(&c.ConnBandwidth.Upload,
+		"conn-upload-limit",
+		c.ConnBandwidth.Upload,
+		"upload limit of a connection, bytes per second, 0 - no limit")
+
+	flag.IntVar(&c.ConnBandwidth.Download,
+		"conn-download-limit",
+		c.ConnBandwidth.Download,
+		"download limit of a connection, bytes per second, 0 - no limit")
+
+	// flood protection
+
+	flag.IntVar(&c.Quota.Objects,
+		"quota-objects",
+		c.Quota.Objects,
+		"objects a peer can request per second, 0 - no limit")
+
+	flag.IntVar(&c.Quota.Previews,
+		"quota-previews",
+		c.Quota.Previews,
+		"previews a peer can request per second, 0 - no limit")
+
+	flag.IntVar(&c.Quota.Subs,
+		"quota-subs",
+		c.Quota.Subs,
+		"subscription requests of a peer per second, 0 - no limit")
+
+	flag.IntVar(&c.Quota.Roots,
+		"quota-roots",
+		c.Quota.Roots,
+		"Root objects a peer can push per second, 0 - no limit")
+
+	flag.DurationVar(&c.Quota.MaxDelay,
+		"quota-max-delay",
+		c.Quota.MaxDelay,
+		"disconnect a peer if its messages delayed longer, 0 - never")
+
 	// public
 
 	flag.BoolVar(&c.Public,
@@ -387,7 +1028,7 @@ func (c *Config) FromFlags() {
 }
 
 // Validate configurations. The Validate doesn't
-// validates addresses (TCP, UDP or RPC)
+// validates addresses (TCP, UDP, Unix, WS or RPC)
 func (c *Config) Validate() (err error) {
 
 	// nothing to validate in the Logger configurations
@@ -399,7 +1040,69 @@ func (c *Config) Validate() (err error) {
 		}
 	}
 
-	// nothing to validate in the Node configs
+	if err = c.Allow.Validate(); err != nil {
+		return fmt.Errorf("invalid Allow list: %v", err)
+	}
+
+	if err = c.Deny.Validate(); err != nil {
+		return fmt.Errorf("invalid Deny list: %v", err)
+	}
+
+	if err = c.Bandwidth.Validate(); err != nil {
+		return fmt.Errorf("invalid Bandwidth: %v", err)
+	}
+
+	if err = c.ConnBandwidth.Validate(); err != nil {
+		return fmt.Errorf("invalid ConnBandwidth: %v", err)
+	}
+
+	if c.ReconnectMin < 0 || c.ReconnectMax < c.ReconnectMin {
+		return fmt.Errorf("invalid reconnect delays: %s-%s",
+			c.ReconnectMin,
+			c.ReconnectMax)
+	}
+
+	if c.HedgeDelay < 0 {
+		return fmt.Errorf("negative HedgeDelay: %s", c.HedgeDelay)
+	}
+
+	if c.Bootstrap < 0 {
+		return fmt.Errorf("negative Bootstrap: %d", c.Bootstrap)
+	}
+
+	if c.MaxPeerFailures < 0 {
+		return fmt.Errorf("negative MaxPeerFailures: %d", c.MaxPeerFailures)
+	}
+
+	if c.TCP.LocalInterval < 0 || c.UDP.LocalInterval < 0 {
+		return fmt.Errorf("negative LocalInterval")
+	}
+
+	if c.PeerExchangeLimit < 0 {
+		return fmt.Errorf("negative PeerExchangeLimit: %d",
+			c.PeerExchangeLimit)
+	}
+
+	if err = c.Quota.Validate(); err != nil {
+		return fmt.Errorf("invalid Quota: %v", err)
+	}
+
+	if c.TCP.Encryption > EncryptionRequired {
+		return fmt.Errorf("invalid TCP encryption mode: %d", c.TCP.Encryption)
+	}
+
+	if c.UDP.Encryption > EncryptionRequired {
+		return fmt.Errorf("invalid UDP encryption mode: %d", c.UDP.Encryption)
+	}
+
+	if c.Unix.Encryption > EncryptionRequired {
+		return fmt.Errorf("invalid Unix encryption mode: %d",
+			c.Unix.Encryption)
+	}
+
+	if c.WS.Encryption > EncryptionRequired {
+		return fmt.Errorf("invalid WS encryption mode: %d", c.WS.Encryption)
+	}
 
 	return
 
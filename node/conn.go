@@ -4,31 +4,53 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
-	"github.com/skycoin/net/factory"
-
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/node/msg"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
+// A Connection represents underlying connection
+// of a transport. The *factory.Connection of TCP
+// and UDP transports implements it
+type Connection interface {
+	GetChanIn() <-chan []byte  // received messages
+	GetChanOut() chan<- []byte // messages to send
+	GetRemoteAddr() net.Addr   // address of remote peer
+	IsTCP() bool               // is TCP connection
+	IsUDP() bool               // is UDP connection
+	Close()                    // close the connection
+}
+
 // A Conn represent connection of the Node
 type Conn struct {
-	*factory.Connection
+	Connection
 
 	// lock
 	mx sync.Mutex
 
-	incoming bool // is incoming or not
+	network  string // "tcp", "udp", "unix" or "ws"
+	incoming bool   // is incoming or not
+
+	n        *Node         // back reference
+	peerID   cipher.PubKey // peer id
+	verified bool          // the peerID is verified
+	ss       *session      // encryption or nil
+	bw       limiter       // bandwidth of the connection
+	qt       peerQuota     // counters and quota of the peer
+	sc       peerScore     // quality of the peer as source of objects
+
+	gossiped int // addresses remembered from the peer (see exchange.go)
 
-	n      *Node         // back reference
-	peerID cipher.PubKey // peer id
+	protocol uint16       // protocol version used by the connection
+	features msg.Features // features of the peer
 
 	// request - response
 	seq  uint32                    // messege seq number (for request-response)
@@ -40,7 +62,9 @@ type Conn struct {
 	//
 	// ------
 
-	sendq chan<- []byte // channel from factory.Connection
+	sendq chan<- []byte // channel from the Connection
+
+	limitq chan limitedMsg // limited messages (see quota.go)
 
 	await  sync.WaitGroup // wait for receiving loop
 	closeq chan struct{}  //
@@ -48,7 +72,8 @@ type Conn struct {
 }
 
 func (n *Node) newConnection(
-	fc *factory.Connection,
+	fc Connection,
+	network string,
 	isIncoming bool,
 ) (
 	c *Conn,
@@ -57,13 +82,18 @@ func (n *Node) newConnection(
 	c = new(Conn)
 
 	c.Connection = fc
+	c.network = network
 	c.incoming = isIncoming
 
 	c.n = n
+	c.bw.set(n.ConnBandwidth())
+	c.qt.init(&n.config.Quota)
+	c.sc.init(n.rollAvgSamples)
 
 	c.reqs = make(map[uint32]chan<- msg.Msg)
 
 	c.sendq = fc.GetChanOut()
+	c.limitq = make(chan limitedMsg, limitedQueueSize)
 	c.closeq = make(chan struct{})
 
 	n.addPendingConn(c)
@@ -77,8 +107,9 @@ func (n *Node) newConnection(
 
 // start handling
 func (c *Conn) run() {
-	c.await.Add(1)
+	c.await.Add(2)
 	go c.receiving()
+	go c.limiting()
 }
 
 func (c *Conn) decodeRaw(raw []byte) (seq, rseq uint32, m msg.Msg, err error) {
@@ -94,7 +125,14 @@ func (c *Conn) decodeRaw(raw []byte) (seq, rseq uint32, m msg.Msg, err error) {
 	rseq = binary.LittleEndian.Uint32(raw)
 	raw = raw[4:]
 
-	m, err = msg.Decode(raw)
+	if m, err = msg.Decode(raw); err != nil {
+		return
+	}
+
+	if c.Supports(msg.FeatureCompression) == true {
+		err = c.decompress(m)
+	}
+
 	return
 }
 
@@ -103,11 +141,70 @@ func (c *Conn) decodeRaw(raw []byte) (seq, rseq uint32, m msg.Msg, err error) {
 //
 
 // PeerID is id of remote peer that used
-// for internals and unique
+// for internals and unique. The PeerID of
+// a Conn that is not authenticated is not
+// verified (see IsAuthenticated)
 func (c *Conn) PeerID() (id cipher.PubKey) {
 	return c.peerID
 }
 
+// IsAuthenticated returns true if the remote
+// peer proved its NodeID during handshake. A
+// peer of the msg.LegacyVersion doesn't prove
+// it, and its PeerID can be forged. Such peers
+// are accepted only if AcceptLegacy of Config
+// is true
+func (c *Conn) IsAuthenticated() bool {
+	return c.verified
+}
+
+// verified PeerID or blank public key if the
+// Conn is not authenticated; the blank key
+// never matches an access or policy rule
+func (c *Conn) verifiedID() (id cipher.PubKey) {
+	if c.verified == true {
+		id = c.peerID
+	}
+	return
+}
+
+// Protocol returns protocol version negotiated
+// during handshake
+func (c *Conn) Protocol() uint16 {
+	return c.protocol
+}
+
+// PeerFeatures returns features that the remote
+// peer supports. A peer of the msg.LegacyVersion
+// doesn't support any features
+func (c *Conn) PeerFeatures() msg.Features {
+	return c.features
+}
+
+// Supports returns true if both sides of the
+// Conn support given features
+func (c *Conn) Supports(features msg.Features) bool {
+	return (c.features & c.n.features()).Has(features)
+}
+
+// Network returns network of the Conn,
+// that is "tcp", "udp", "unix" or "ws"
+func (c *Conn) Network() string {
+	return c.network
+}
+
+// IsUnix returns true if the Conn
+// is Unix domain socket connection
+func (c *Conn) IsUnix() bool {
+	return c.network == "unix"
+}
+
+// IsWS returns true if the Conn
+// is WebSocket connection
+func (c *Conn) IsWS() bool {
+	return c.network == "ws"
+}
+
 // IsIncoming returns true if this Conn is
 // incoming and accepted by listener
 func (c *Conn) IsIncoming() (ok bool) {
@@ -137,7 +234,7 @@ func (c *Conn) Feeds() (feeds []cipher.PubKey) {
 
 }
 
-func connString(isIncoming, isTCP bool, addr string) (s string) {
+func connString(isIncoming bool, network, addr string) (s string) {
 
 	if isIncoming == true {
 		s = "↓ "
@@ -145,13 +242,7 @@ func connString(isIncoming, isTCP bool, addr string) (s string) {
 		s = "↑ "
 	}
 
-	if isTCP == true {
-		s += "tcp://"
-	} else {
-		s += "udp://"
-	}
-
-	return s + addr
+	return s + network + "://" + addr
 }
 
 // String returns string "-> network://remote_address"
@@ -159,7 +250,7 @@ func connString(isIncoming, isTCP bool, addr string) (s string) {
 // arrow is "->" for incoming connections and is "<-"
 // for outgoing
 func (c *Conn) String() (s string) {
-	return connString(c.incoming, c.IsTCP(), c.Address())
+	return connString(c.incoming, c.network, c.Address())
 }
 
 //
@@ -241,30 +332,45 @@ func (c *Conn) Subscribe(feed cipher.PubKey) (err error) {
 		return
 	}
 
-	var reply msg.Msg
+	// the peer sends its last Root object right after
+	// the reply, thus the connection should be added
+	// to the feed before, to don't drop the Root
 
-	if reply, err = c.sendRequest(&msg.Sub{Feed: feed}); err != nil {
-		return
+	var had = c.n.fs.hasConnFeed(c, feed)
+
+	if had == false {
+		c.n.fs.addConnFeed(c, feed)
 	}
 
-	switch x := reply.(type) {
+	var reply msg.Msg
 
-	case *msg.Ok:
-	// success
+	if reply, err = c.sendRequest(&msg.Sub{Feed: feed}); err == nil {
 
-	case *msg.Err:
-		err = errors.New(x.Err)
+		switch x := reply.(type) {
 
-	default:
-		err = fmt.Errorf("invalid response type %T", reply)
+		case *msg.Ok:
+		// success
+
+		case *msg.Err:
+			err = errors.New(x.Err)
+
+		default:
+			err = fmt.Errorf("invalid response type %T", reply)
+
+		}
 
 	}
 
 	if err != nil {
+		if had == false {
+			c.n.fs.delConnFeed(c, feed)
+		}
 		return
 	}
 
-	c.n.fs.addConnFeed(c, feed)
+	c.n.subscribed(c, feed)
+	c.n.rememberFeed(c, feed)
+	c.n.exchangePeers(c, feed)
 	c.sendLastRoot(feed)
 	return
 }
@@ -279,6 +385,7 @@ func (c *Conn) unsubscribe(pk cipher.PubKey) {
 // Unsubscribe from given feed of remote peer
 func (c *Conn) Unsubscribe(feed cipher.PubKey) {
 	c.n.fs.delConnFeed(c, feed)
+	c.n.unsubscribed(c, feed)
 	c.unsubscribe(feed) // notify peer
 	return
 }
@@ -392,6 +499,10 @@ func (c *Conn) nextSeq() uint32 {
 
 func (c *Conn) encodeMsg(seq, rseq uint32, m msg.Msg) (raw []byte) {
 
+	if c.Supports(msg.FeatureCompression) == true {
+		m = c.compress(m)
+	}
+
 	var em = m.Encode()
 
 	raw = make([]byte, 8, 8+len(em))
@@ -414,6 +525,17 @@ func (c *Conn) sendMsg(seq, rseq uint32, m msg.Msg) {
 
 func (c *Conn) sendRaw(raw []byte) {
 
+	if c.throttle(len(raw), true) != nil {
+		return // closed
+	}
+
+	if c.ss != nil {
+		c.ss.smx.Lock()         // keep order of the counters
+		defer c.ss.smx.Unlock() // in the sendq
+
+		raw = c.ss.encrypt(raw)
+	}
+
 	select {
 	case c.sendq <- raw:
 	case <-c.closeq:
@@ -458,6 +580,17 @@ func (c *Conn) receiving() {
 				return // closed
 			}
 
+			if c.throttle(len(raw), false) != nil {
+				return // closed
+			}
+
+			if c.ss != nil {
+				if raw, err = c.ss.decrypt(raw); err != nil {
+					c.fatality("can't decrypt received messege: ", err)
+					return
+				}
+			}
+
 			// [ 4 seq ][ 4 rseq ][ 1 msg type ]
 
 			if len(raw) < 9 {
@@ -478,6 +611,13 @@ func (c *Conn) receiving() {
 				return
 			}
 
+			if c.Supports(msg.FeatureCompression) == true {
+				if err = c.decompress(m); err != nil {
+					c.fatality("can't decompress received messege: ", err)
+					return
+				}
+			}
+
 			c.n.Debugf(MsgReceivePin, "[%s] receive %T", c.String(), m)
 
 			// the messege can be a response for a request
@@ -523,12 +663,7 @@ func (c *Conn) delRequest(seq uint32) {
 }
 
 func (c *Conn) responseTimeout() (rt time.Duration) {
-	if c.IsTCP() == true {
-		rt = c.n.config.TCP.ResponseTimeout
-	} else {
-		rt = c.n.config.UDP.ResponseTimeout
-	}
-	return
+	return c.n.netConfig(c.network).ResponseTimeout
 }
 
 func (c *Conn) sendRequest(m msg.Msg) (reply msg.Msg, err error) {
@@ -566,6 +701,9 @@ func (c *Conn) sendRequest(m msg.Msg) (reply msg.Msg, err error) {
 
 	case <-c.closeq:
 		return nil, ErrClosed
+
+	case <-c.n.closeq:
+		return nil, ErrClosed
 	}
 
 }
@@ -581,6 +719,41 @@ func (c *Conn) sendOk(rseq uint32) {
 // handle messeges except responses and handshakes
 func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 
+	if kind, _ := quotaKind(m); kind >= 0 {
+		c.limit(seq, m) // handled by the limiting goroutine
+		return
+	}
+
+	switch m.(type) {
+
+	//
+	// delayed messeges (ignore them)
+	//
+	// the delayed messeges are responses that received
+	// after timeout, e.g. the requst is closed with
+	// ErrTimeout and noone waits them
+
+	case *msg.Object: // -> O (delayed)
+	case *msg.Objects: // -> Os (delayed)
+	case *msg.Err: // -> Err (delayed)
+	case *msg.Ok: // -> Ok (delayed)
+	case *msg.List: // -> List (delayed)
+	case *msg.Peers: // -> Peers (delayed)
+	case *msg.Roots: // -> Roots (delayed)
+
+	default:
+
+		return fmt.Errorf("invalid messege type %T", m)
+
+	}
+
+	return
+
+}
+
+// handle messeges limited by Quota
+func (c *Conn) handleLimited(seq uint32, m msg.Msg) (err error) {
+
 	switch x := m.(type) {
 
 	// subscriptions
@@ -610,31 +783,38 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 		go c.handleRqObject(seq, x)
 		return
 
+	case *msg.RqObjects: // <- RqOs (keys)
+		if len(x.Keys) == 0 || len(x.Keys) > msg.MaxKeys {
+			return fmt.Errorf("invalid RqObjects: %d keys", len(x.Keys))
+		}
+		c.await.Add(1)
+		go c.handleRqObjects(seq, x)
+		return
+
 	// preview
 
 	case *msg.RqPreview: // -> RqPreview (feed)
 		return c.handleRqPreview(seq, x)
 
-	//
-	// delayed messeges (ignore them)
-	//
-	// the delayed messeges are responses that received
-	// after timeout, e.g. the requst is closed with
-	// ErrTimeout and noone waits them
+	// peer exchange
 
-	case *msg.Object: // -> O (delayed)
-	case *msg.Err: // -> Err (delayed)
-	case *msg.Ok: // -> Ok (delayed)
-	case *msg.List: // -> List (delayed)
+	case *msg.RqPeers: // <- RqPeers (feed, listen)
+		if len(x.Listen) > 2 {
+			return fmt.Errorf("invalid RqPeers: %d addresses", len(x.Listen))
+		}
+		return c.handleRqPeers(seq, x)
 
-	default:
+	// history
 
-		return fmt.Errorf("invalid messege type %T", m)
+	case *msg.RqRoots: // <- RqRoots (feed, nonce, from, to)
+		if x.From > x.To {
+			return fmt.Errorf("invalid RqRoots: [%d, %d]", x.From, x.To)
+		}
+		return c.handleRqRoots(seq, x)
 
 	}
 
-	return
-
+	return fmt.Errorf("invalid messege type %T", m)
 }
 
 // subscribe (with reply)
@@ -655,6 +835,14 @@ func (c *Conn) handleSub(seq uint32, sub *msg.Sub) (_ error) {
 		return
 	}
 
+	// policy
+	if p := c.n.config.Policy; p != nil {
+		if p.IsAllowed(c.verifiedID(), sub.Feed) == false {
+			c.sendErr(seq, ErrNotAllowed)
+			return
+		}
+	}
+
 	// callback
 	var reject = c.n.onSubscribeRemote(c, sub.Feed)
 
@@ -735,18 +923,26 @@ func (c *Conn) handleRoot(root *msg.Root) (_ error) {
 
 	default: // nil (found)
 
-		if last >= root.Seq {
+		if last > root.Seq {
 			return // we have newer one
 		}
 
+		// the same seq is checked for equivocation
+
 	}
 
-	var r *registry.Root
+	if c.n.config.RefuseEquivocated == true &&
+		c.n.c.IsEquivocated(root.Feed, root.Nonce) == true {
 
-	r, err = c.n.c.ReceivedRoot(root.Feed, root.Sig, root.Value)
+		return // refuse Root objects of the head
+	}
 
-	if err != nil {
-		c.n.Printf("[ERR] [%s] received Root error: %s", c.String(), err)
+	var r *registry.Root
+
+	if r, err = c.receivedRoot(root.Feed, root.Sig, root.Value); err != nil {
+		if _, ok := err.(*skyobject.EquivocationError); ok == false {
+			c.n.Printf("[ERR] [%s] received Root error: %s", c.String(), err)
+		}
 		return // keep connection ?
 	}
 
@@ -761,6 +957,28 @@ func (c *Conn) handleRoot(root *msg.Root) (_ error) {
 	return
 }
 
+// ReceivedRoot of the Container that calls
+// OnEquivocation callback if the Root conflicts
+// with saved one first time
+func (c *Conn) receivedRoot(
+	pk cipher.PubKey, //  : feed
+	sig cipher.Sig, //    : signature
+	val []byte, //        : encoded Root
+) (
+	r *registry.Root, //  : decoded Root
+	err error, //         : an error
+) {
+
+	if r, err = c.n.c.ReceivedRoot(pk, sig, val); err != nil {
+		var ee, ok = err.(*skyobject.EquivocationError)
+		if ok == true && ee.New == true {
+			c.n.onEquivocation(c, ee.Equivocation)
+		}
+	}
+
+	return
+}
+
 // async
 func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
 	defer c.await.Done()
@@ -815,6 +1033,56 @@ func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
 	return
 }
 
+// reply with objects the Node has, the reply is limited
+// by MaxObjectSize; the Node doesn't wait for objects it
+// doesn't have, the requester requests them again (from
+// another peer); if the Node has not any of the objects,
+// then the reply is Err
+func (c *Conn) handleRqObjects(seq uint32, rq *msg.RqObjects) {
+	defer c.await.Done()
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqObjects %d", c.String(),
+		len(rq.Keys))
+
+	var (
+		gc = make(chan skyobject.Object, len(rq.Keys))
+
+		limit = c.n.c.Config().MaxObjectSize
+		size  int
+
+		objs = new(msg.Objects)
+	)
+
+	for _, key := range rq.Keys {
+		if err := c.n.c.Want(key, gc, 0); err != nil {
+			c.n.Fatal("DB failure: ", err)
+		}
+		defer c.n.c.Unwant(key, gc) // to be memory safe
+	}
+
+	// the Want sends objects the Node has immediately
+
+	for len(gc) > 0 {
+
+		var obj = <-gc
+
+		if len(objs.Values) > 0 && size+len(obj.Val) > limit {
+			break // size limit
+		}
+
+		objs.Values = append(objs.Values, obj.Val)
+		size += len(obj.Val)
+
+	}
+
+	if len(objs.Values) == 0 {
+		c.sendMsg(c.nextSeq(), seq, &msg.Err{Err: ErrNotFound.Error()})
+		return
+	}
+
+	c.sendMsg(c.nextSeq(), seq, objs)
+}
+
 func (c *Conn) handleRqPreview(seq uint32, rqp *msg.RqPreview) (_ error) {
 
 	c.n.Debugf(MsgReceivePin, "[%s] handleRqPreview %s", c.String(),
@@ -0,0 +1,70 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestConn_handleRqObjects(t *testing.T) {
+
+	var (
+		ln = getTestNode("server")
+		cn = getTestNodeNotListen("client")
+	)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	var (
+		val     = []byte("value")
+		key     = cipher.SumSHA256(val)
+		missing = cipher.SumSHA256([]byte("missing"))
+	)
+
+	var _, err = ln.Container().Set(key, val, 1)
+	assertNil(t, err)
+
+	var c *Conn
+	c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	// the reply should not wait for the missing object
+
+	var (
+		tp    = time.Now()
+		reply msg.Msg
+	)
+
+	reply, err = c.sendRequest(&msg.RqObjects{
+		Keys: []cipher.SHA256{missing, key},
+	})
+	assertNil(t, err)
+
+	if time.Since(tp) >= TM {
+		t.Error("slow reply")
+	}
+
+	if objs, ok := reply.(*msg.Objects); ok == false {
+		t.Errorf("wrong reply type %T", reply)
+	} else if len(objs.Values) != 1 || string(objs.Values[0]) != "value" {
+		t.Error("wrong reply")
+	}
+
+	// nothing found
+
+	reply, err = c.sendRequest(&msg.RqObjects{
+		Keys: []cipher.SHA256{missing},
+	})
+	assertNil(t, err)
+
+	if e, ok := reply.(*msg.Err); ok == false {
+		t.Errorf("wrong reply type %T", reply)
+	} else if e.Err != ErrNotFound.Error() {
+		t.Error("wrong error:", e.Err)
+	}
+
+}
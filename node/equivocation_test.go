@@ -0,0 +1,77 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_OnEquivocation(t *testing.T) {
+
+	var eq = make(chan *skyobject.Equivocation, 1)
+
+	var tp = newTestPair(t, func(_, bc *Config) {
+		bc.OnEquivocation = func(_ *Node, _ *Conn, e *skyobject.Equivocation) {
+			select {
+			case eq <- e:
+			default:
+			}
+		}
+	})
+	defer tp.Close()
+
+	// two different Root objects with the same seq
+
+	var (
+		pk = tp.pk
+		ar = &registry.Root{Nonce: 1, Pub: pk}
+		br = &registry.Root{Nonce: 1, Pub: pk}
+	)
+
+	tp.save(t, ar)
+
+	var up, err = tp.b.Container().Unpack(tp.sk, getTestRegistry())
+	assertNil(t, err)
+	assertNil(t, tp.b.Container().Save(up, br))
+
+	if ar.Seq != br.Seq || ar.Hash == br.Hash {
+		t.Fatal("unexpected Root objects")
+	}
+
+	tp.connect(t) // the a pushes its last Root
+
+	select {
+	case e := <-eq:
+		if e.Feed != pk || e.Nonce != 1 || e.Seq != ar.Seq {
+			t.Error("wrong head")
+		}
+		if e.First.Hash != br.Hash || e.Second.Hash != ar.Hash {
+			t.Error("wrong Root objects")
+		}
+	case <-time.After(TM):
+		t.Fatal("slow or missing equivocation")
+	}
+
+	// the callback called once
+
+	tp.a.Publish(ar)
+
+	select {
+	case <-eq:
+		t.Error("OnEquivocation called twice")
+	case <-time.After(TM):
+	}
+
+	if tp.b.Container().IsEquivocated(pk, 1) == false {
+		t.Error("not equivocated")
+	}
+
+	if r, err := tp.b.Container().LastRoot(pk, 1); err != nil {
+		t.Error(err)
+	} else if r.Hash != br.Hash {
+		t.Error("the first Root replaced")
+	}
+
+}
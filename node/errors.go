@@ -16,4 +16,13 @@ var (
 	ErrMaxHeadsLimit           = errors.New("max heads limit")
 	ErrUnsubscribe             = errors.New("unsubscribe")
 	ErrBlankFeed               = errors.New("blank feed")
+	ErrInvalidProof            = errors.New("invalid handshake proof")
+	ErrEncryptionRequired      = errors.New("encryption required")
+	ErrReplay                  = errors.New("replayed or reordered messege")
+	ErrNotAllowed              = errors.New("not allowed")
+	ErrNoPolicy                = errors.New("no subscription policy")
+	ErrNoSuchPeer              = errors.New("no such peer")
+	ErrNotSupported            = errors.New("not supported by peer")
+	ErrNotFound                = errors.New("not found")
+	ErrTooManyDelayed          = errors.New("too many delayed messages")
 )
@@ -0,0 +1,401 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// max number of addresses the Node remembers from
+// one connection, received from the peer or advertised
+// by the peer; the Peers message is limited by the
+// msg.MaxPeers, but a peer can send many of them
+const maxGossiped int = 2 * msg.MaxPeers
+
+// max number of addresses the peer exchange dials per
+// received Peers message, the rest are remembered only;
+// a peer can send many addresses nobody listens on
+const maxExchangeDials int = 8
+
+// A NetAddress represents network
+// ("tcp", "udp", "unix" or "ws") and address
+type NetAddress struct {
+	Network string
+	Address string
+}
+
+// RemotePeers requests addresses of peers that share given
+// feed. The remote peer should be public and should support
+// peer exchange (see msg.FeaturePeers). Received addresses
+// are saved to address book of the Node (see KnownPeers).
+// The request contains listening addresses of the Node, and
+// if the Conn is subscribed to the feed, then the remote
+// peer remembers them to share with other peers
+func (c *Conn) RemotePeers(feed cipher.PubKey) (peers []NetAddress, err error) {
+
+	if c.Supports(msg.FeaturePeers) == false {
+		return nil, ErrNotSupported
+	}
+
+	var reply msg.Msg
+
+	reply, err = c.sendRequest(&msg.RqPeers{
+		Feed:   feed,
+		Listen: c.n.listenAddresses(),
+	})
+
+	if err != nil {
+		return
+	}
+
+	switch x := reply.(type) {
+
+	case *msg.Peers:
+
+		if x.Feed != feed || len(x.Peers) > msg.MaxPeers {
+			return nil, ErrInvalidResponse
+		}
+
+		for _, pa := range x.Peers {
+
+			if pa.Network != "tcp" && pa.Network != "udp" {
+				continue // unknown network
+			}
+
+			if pa.Address == "" {
+				continue
+			}
+
+			peers = append(peers, NetAddress{pa.Network, pa.Address})
+			c.rememberGossiped(NetAddress{pa.Network, pa.Address}, feed)
+		}
+
+	case *msg.Err:
+
+		err = errors.New(x.Err)
+
+	default:
+
+		err = fmt.Errorf("invalid response type %T", reply)
+
+	}
+
+	return
+}
+
+func (c *Conn) handleRqPeers(seq uint32, rq *msg.RqPeers) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqPeers %s",
+		c.String(), rq.Feed.Hex()[:7])
+
+	// addresses of the requester
+	var except = make(map[NetAddress]struct{})
+
+	if c.incoming == false {
+		except[NetAddress{c.Network(), c.Address()}] = struct{}{}
+	}
+
+	for _, pa := range rq.Listen {
+
+		var na, ok = c.advertisedAddress(pa)
+
+		if ok == false {
+			continue
+		}
+
+		except[na] = struct{}{}
+
+		// remember the requester if it's subscribed to the feed,
+		// e.g. it shares the feed
+		if c.n.fs.hasConnFeed(c, rq.Feed) == true {
+			c.rememberGossiped(na, rq.Feed)
+		}
+
+	}
+
+	if c.n.config.Public == false {
+		c.sendErr(seq, ErrNotPublic)
+		return
+	}
+
+	var reply = &msg.Peers{Feed: rq.Feed}
+
+	// don't share addresses of feeds the Node doesn't share
+	if c.n.fs.hasFeed(rq.Feed) == true {
+		reply.Peers = c.n.feedPeers(rq.Feed, except)
+	}
+
+	c.sendMsg(c.nextSeq(), seq, reply)
+	return
+}
+
+// advertisedAddress returns listening address of the
+// remote peer; blank or unspecified host replaced with
+// host of the connection, and other host should be the
+// host of the connection, since a peer can advertise
+// only its own address
+func (c *Conn) advertisedAddress(pa msg.PeerAddress) (na NetAddress,
+	ok bool) {
+
+	if pa.Network != "tcp" && pa.Network != "udp" {
+		return
+	}
+
+	var host, port, err = net.SplitHostPort(pa.Address)
+
+	if err != nil {
+		return
+	}
+
+	var remote string
+	if remote, _, err = net.SplitHostPort(c.remoteAddress()); err != nil {
+		return
+	}
+
+	if ip := net.ParseIP(host); host == "" || ip.IsUnspecified() == true {
+		host = remote
+	} else if ip.Equal(net.ParseIP(remote)) == false {
+		return // not the peer
+	}
+
+	return NetAddress{pa.Network, net.JoinHostPort(host, port)}, true
+}
+
+// remote address of the connection
+func (c *Conn) remoteAddress() (address string) {
+	if ra := c.GetRemoteAddr(); ra != nil {
+		return ra.String()
+	}
+	return
+}
+
+// rememberGossiped saves address received from the peer
+// to address book of the Node, up to the maxGossiped per
+// connection; it returns false if the limit reached. The
+// bootstrap doesn't dial the address until a handshake
+// with it succeeds (see bootstrap)
+func (c *Conn) rememberGossiped(na NetAddress, feed cipher.PubKey) (ok bool) {
+
+	c.mx.Lock()
+	if c.gossiped >= maxGossiped {
+		c.mx.Unlock()
+		return
+	}
+	c.gossiped++
+	c.mx.Unlock()
+
+	c.n.rememberDiscovered(na.Network, na.Address, feed)
+	return true
+}
+
+// listening addresses of the Node
+func (n *Node) listenAddresses() (listen []msg.PeerAddress) {
+
+	n.mx.Lock()
+	var tcp, udp = n.tcp, n.udp
+	n.mx.Unlock()
+
+	if tcp != nil && tcp.Address() != "" {
+		listen = append(listen, msg.PeerAddress{
+			Network: "tcp",
+			Address: tcp.Address(),
+		})
+	}
+
+	if udp != nil && udp.Address() != "" {
+		listen = append(listen, msg.PeerAddress{
+			Network: "udp",
+			Address: udp.Address(),
+		})
+	}
+
+	return
+}
+
+// feedPeers returns known addresses of peers
+// that share given feed, except given addresses
+func (n *Node) feedPeers(
+	feed cipher.PubKey, //                  :
+	except map[NetAddress]struct{}, //      :
+) (
+	peers []msg.PeerAddress, //             :
+) {
+
+	for _, kp := range n.ab.list() {
+
+		if len(peers) == msg.MaxPeers {
+			break
+		}
+
+		if _, ok := except[NetAddress{kp.Network, kp.Address}]; ok == true {
+			continue // the requester
+		}
+
+		if kp.Network == "unix" {
+			continue // local peer
+		}
+
+		for _, pk := range kp.Feeds {
+			if pk == feed {
+				peers = append(peers, msg.PeerAddress{
+					Network: kp.Network,
+					Address: kp.Address,
+				})
+				break
+			}
+		}
+
+	}
+
+	return
+}
+
+// start given peer exchange goroutine if the peer
+// exchange is turned on and the Node is not closed
+func (n *Node) startExchange(exchange func()) {
+
+	if n.config.PeerExchange == false {
+		return
+	}
+
+	n.spawn(&n.ab.await, exchange)
+}
+
+// subscribe given outgoing connection to all feeds
+// of the Node, the subscriptions start peer exchange
+func (n *Node) exchangeFeeds(c *Conn) {
+
+	if c.incoming == true || c.Supports(msg.FeaturePeers) == false {
+		return
+	}
+
+	n.startExchange(func() {
+		for _, feed := range n.Feeds() {
+			n.exchangeSubscribe(c, feed)
+		}
+	})
+}
+
+// subscribe all outgoing connections to given
+// new feed, the subscriptions start peer exchange
+func (n *Node) exchangeFeed(feed cipher.PubKey) {
+
+	n.startExchange(func() {
+		for _, c := range n.Connections() {
+			if c.incoming == false && c.Supports(msg.FeaturePeers) == true {
+				n.exchangeSubscribe(c, feed)
+			}
+		}
+	})
+}
+
+// subscribe given connection to given feed if it's
+// not subscribed yet, the remote peer can reject the
+// subscription if it doesn't share the feed
+func (n *Node) exchangeSubscribe(c *Conn, feed cipher.PubKey) {
+
+	if n.fs.hasConnFeed(c, feed) == true {
+		return // already subscribed
+	}
+
+	if err := c.Subscribe(feed); err != nil {
+		n.Debugf(ConnPin, "[%s] peer exchange: can't Subscribe to %s: %v",
+			c.String(),
+			feed.Hex()[:7],
+			err)
+	}
+}
+
+// request peers that share given feed after subscription
+// and connect to them up to the PeerExchangeLimit
+func (n *Node) exchangePeers(c *Conn, feed cipher.PubKey) {
+
+	if c.Supports(msg.FeaturePeers) == false {
+		return
+	}
+
+	n.startExchange(func() {
+
+		var peers, err = c.RemotePeers(feed)
+
+		if err != nil {
+			n.Debugf(ConnPin, "[%s] peer exchange: can't get peers of %s: %v",
+				c.String(),
+				feed.Hex()[:7],
+				err)
+			return
+		}
+
+		for _, na := range n.exchangeCandidates(peers) {
+
+			if len(n.ConnectionsOfFeed(feed)) >= n.config.PeerExchangeLimit {
+				return // enough
+			}
+
+			select {
+			case <-n.closeq:
+				return
+			default:
+			}
+
+			var pc *Conn
+
+			if pc, err = n.dial(na.Network, na.Address); err != nil {
+				n.Debugf(ConnPin, "[%s://%s] peer exchange: can't connect: %v",
+					na.Network,
+					na.Address,
+					err)
+				continue
+			}
+
+			n.exchangeSubscribe(pc, feed) // recursive
+		}
+
+	})
+}
+
+// exchangeCandidates returns up to the maxExchangeDials
+// of given addresses to dial, excluding own addresses of
+// the Node and addresses the Node has failed to connect to
+func (n *Node) exchangeCandidates(peers []NetAddress) (dial []NetAddress) {
+
+	for _, na := range peers {
+
+		if len(dial) == maxExchangeDials {
+			break
+		}
+
+		if n.isOwnAddress(na) == true {
+			continue
+		}
+
+		if n.ab.failures(na.Network, na.Address) > 0 {
+			continue // unreachable
+		}
+
+		dial = append(dial, na)
+	}
+
+	return
+}
+
+// is given address listening address of the Node
+func (n *Node) isOwnAddress(na NetAddress) bool {
+
+	n.mx.Lock()
+	var tcp, udp = n.tcp, n.udp
+	n.mx.Unlock()
+
+	switch na.Network {
+	case "tcp":
+		return tcp != nil && tcp.Address() == na.Address
+	case "udp":
+		return udp != nil && udp.Address() == na.Address
+	}
+
+	return false
+}
@@ -0,0 +1,209 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// getFreeAddress returns free local TCP address,
+// the address is taken by listening on port 0
+func getFreeAddress(t *testing.T) (address string) {
+	t.Helper()
+
+	var l, err = net.Listen("tcp", "127.0.0.1:0")
+	assertNil(t, err)
+
+	address = l.Addr().String()
+	l.Close()
+	return
+}
+
+func TestNode_peerExchange(t *testing.T) {
+
+	var (
+		hc = getTestConfig("hub")
+		bc = getTestConfigNotListen("b")
+		cc = getTestConfigNotListen("c")
+
+		pk, _ = cipher.GenerateKeyPair()
+
+		hub, b, c *Node
+		err       error
+	)
+
+	hc.Public = true
+
+	hub, err = NewNode(hc)
+	assertNil(t, err)
+	defer hub.Close()
+
+	assertNil(t, hub.Share(pk))
+
+	var listen = getFreeAddress(t)
+
+	bc.TCP.Listen = listen
+	bc.PeerExchange = true
+
+	b, err = NewNode(bc)
+	assertNil(t, err)
+	defer b.Close()
+
+	assertNil(t, b.Share(pk))
+
+	_, err = b.TCP().Connect(hub.TCP().Address())
+	assertNil(t, err)
+
+	// the hub remembers listening address of the b
+	waitFor(t, func() bool {
+		for _, kp := range hub.KnownPeers() {
+			if kp.Address == listen && len(kp.Feeds) == 1 {
+				return true
+			}
+		}
+		return false
+	})
+
+	cc.PeerExchange = true
+
+	c, err = NewNode(cc)
+	assertNil(t, err)
+	defer c.Close()
+
+	assertNil(t, c.Share(pk))
+
+	_, err = c.TCP().Connect(hub.TCP().Address())
+	assertNil(t, err)
+
+	// the c connects to the b using address from the hub
+	waitFor(t, func() bool {
+		return len(c.ConnectionsOfFeed(pk)) == 2
+	})
+
+}
+
+func TestConn_RemotePeers(t *testing.T) {
+
+	var (
+		ln = getTestNode("server") // not public
+		cn = getTestNodeNotListen("client")
+
+		pk, _ = cipher.GenerateKeyPair()
+	)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	if _, err = c.RemotePeers(pk); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestConn_advertisedAddress(t *testing.T) {
+
+	var (
+		ln = getTestNode("server")
+		cn = getTestNodeNotListen("client")
+	)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	for _, tc := range []struct {
+		network, address string
+		na               NetAddress
+		ok               bool
+	}{
+		{"tcp", ":8870", NetAddress{"tcp", "127.0.0.1:8870"}, true},
+		{"udp", "0.0.0.0:8870", NetAddress{"udp", "127.0.0.1:8870"}, true},
+		{"tcp", "127.0.0.1:8870", NetAddress{"tcp", "127.0.0.1:8870"}, true},
+		{"tcp", "10.0.0.1:8870", NetAddress{}, false},
+		{"tcp", "example.com:8870", NetAddress{}, false},
+		{"unix", "/tmp/cxo.sock", NetAddress{}, false},
+	} {
+		var na, ok = c.advertisedAddress(msg.PeerAddress{
+			Network: tc.network,
+			Address: tc.address,
+		})
+		if ok != tc.ok || na != tc.na {
+			t.Errorf("%s://%s: got %v, %t, want %v, %t",
+				tc.network, tc.address, na, ok, tc.na, tc.ok)
+		}
+	}
+
+}
+
+func TestConn_rememberGossiped(t *testing.T) {
+
+	var (
+		ln = getTestNode("server")
+		cn = getTestNodeNotListen("client")
+
+		pk, _ = cipher.GenerateKeyPair()
+	)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	var before = len(cn.KnownPeers())
+
+	for i := 0; i < 2*maxGossiped; i++ {
+		var na = NetAddress{"tcp", net.JoinHostPort("10.0.0.1", fmt.Sprint(i+1))}
+		if c.rememberGossiped(na, pk) != (i < maxGossiped) {
+			t.Fatal("wrong limit", i)
+		}
+	}
+
+	if got := len(cn.KnownPeers()) - before; got != maxGossiped {
+		t.Error("wrong number of remembered addresses:", got)
+	}
+
+}
+
+func TestNode_exchangeCandidates(t *testing.T) {
+
+	var n = getTestNode("node")
+	defer n.Close()
+
+	var peers = []NetAddress{
+		{"tcp", n.TCP().Address()}, // own address
+	}
+
+	for i := 0; i < 2*maxExchangeDials; i++ {
+		peers = append(peers,
+			NetAddress{"tcp", net.JoinHostPort("10.0.0.1", fmt.Sprint(i+1))})
+	}
+
+	// unreachable
+	var failed = peers[1]
+
+	assertNil(t, n.ab.seen(failed.Network, failed.Address))
+	assertNil(t, n.ab.failed(failed.Network, failed.Address))
+
+	var dial = n.exchangeCandidates(peers)
+
+	if len(dial) != maxExchangeDials {
+		t.Fatal("wrong number of addresses to dial:", len(dial))
+	}
+
+	for i, na := range dial {
+		if na != peers[i+2] {
+			t.Error("wrong address to dial:", i, na)
+		}
+	}
+
+}
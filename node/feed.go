@@ -2,6 +2,8 @@ package node
 
 import (
 	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
 )
 
 // feed of the Node
@@ -30,6 +32,30 @@ func (n *nodeFeed) addConn(c *Conn) {
 	n.cs[c] = struct{}{}
 }
 
+// resumePending continues filling of pending Root
+// objects of the feed (e.g. after restart) using
+// given connection just subscribed to the feed; if
+// a Root is filling already, then the connection
+// added to the filling
+func (n *nodeFeed) resumePending(c *Conn) {
+
+	var rs, err = n.node().c.Pending(n.this)
+
+	if err != nil {
+		if err != data.ErrNoSuchFeed {
+			n.node().Printf("[ERR] can't get pending Root objects of %s: %v",
+				n.this.Hex()[:7],
+				err)
+		}
+		return
+	}
+
+	for _, r := range rs {
+		n.receivedRoot(connRoot{c, r})
+	}
+
+}
+
 func (n *nodeFeed) delConn(c *Conn) {
 	delete(n.cs, c)
 
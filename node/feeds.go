@@ -56,6 +56,9 @@ type nodeFeeds struct {
 	hasfrq chan cipher.PubKey // has feed
 	hasfrn chan bool          // response
 
+	hsrq chan cipher.PubKey // heads of feed (or all) request
+	hsrn chan []*nodeHead   // response
+
 	// roo objects
 
 	rrq chan connRoot // received root
@@ -105,6 +108,9 @@ func newNodeFeeds(node *Node) (n *nodeFeeds) {
 	n.hasfrq = make(chan cipher.PubKey)
 	n.hasfrn = make(chan bool)
 
+	n.hsrq = make(chan cipher.PubKey)
+	n.hsrn = make(chan []*nodeHead)
+
 	// root objects
 
 	n.rrq = make(chan connRoot, 10) // received Root objects
@@ -151,6 +157,7 @@ func (n *nodeFeeds) handle() {
 		cfrq    = n.cfrq
 		hascfrq = n.hascfrq
 		hasfrq  = n.hasfrq
+		hsrq    = n.hsrq
 
 		rrq = n.rrq
 
@@ -206,6 +213,9 @@ func (n *nodeFeeds) handle() {
 		case pk = <-hasfrq:
 			n.handleHasFeed(pk)
 
+		case pk = <-hsrq:
+			n.handleHeads(pk)
+
 		// close
 
 		case <-closeq:
@@ -322,6 +332,8 @@ func (n *nodeFeeds) handleAddConnFeed(cf connFeed) {
 	nf.addConn(cf.c)
 	n.fs[cipher.PubKey{}].delConn(cf.c) // delete from idle
 
+	nf.resumePending(cf.c)
+
 }
 
 // (api)
@@ -625,3 +637,46 @@ func (n *nodeFeeds) handleHasFeed(pk cipher.PubKey) {
 	return
 
 }
+
+// (api) heads of given feed, or heads of all
+// feeds if given public key is blank
+func (n *nodeFeeds) heads(pk cipher.PubKey) (hs []*nodeHead) {
+
+	select {
+	case n.hsrq <- pk:
+	case <-n.closeq:
+		return
+	}
+
+	select {
+	case hs = <-n.hsrn:
+	case <-n.closeq:
+	}
+
+	return
+}
+
+// (handler)
+func (n *nodeFeeds) handleHeads(pk cipher.PubKey) {
+
+	var hs []*nodeHead
+
+	for fpk, nf := range n.fs {
+
+		if pk != (cipher.PubKey{}) && fpk != pk {
+			continue
+		}
+
+		for _, nh := range nf.hs {
+			hs = append(hs, nh)
+		}
+
+	}
+
+	select {
+	case n.hsrn <- hs:
+	case <-n.closeq:
+	}
+	return
+
+}
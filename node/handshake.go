@@ -5,18 +5,166 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
 	"github.com/skycoin/cxo/node/msg"
 )
 
+// handshake
+//
+// initiator                     acceptor
+//
+//   Syn  (versions, id, challenge, encryption, features) ->
+//                <- Ack (id, challenge, proof, encrypt, version, features)
+//   Auth (proof)                  ->
+//                                <- Ok or Err
+//
+// the initiator sends range of protocol versions
+// it supports, and the acceptor chooses the greatest
+// version both sides support; peers exchange their
+// features (capabilities) to use new messages only
+// if the remote peer understands them (see Conn.Supports)
+//
+// where the proof is signature of challenge of
+// remote peer and of all parameters of the handshake
+// (see handshakeParams); thus, both sides prove that
+// they have secret keys of their NodeIDs, and a man
+// in the middle can't change the parameters; if the
+// encrypt is true, then all messages after the Ok
+// are encrypted (see session.go)
+//
+// a peer of the msg.LegacyVersion sends Syn (version,
+// id) and receives Ack (id); the handshake ends here,
+// the NodeID of such peer is not verified and the
+// connection is not encrypted
+
+// negotiate protocol version for given range of
+// versions of remote peer, the greatest common
+// version is chosen
+func negotiateVersion(min, max uint16) (version uint16, err error) {
+
+	if min > max {
+		return 0, fmt.Errorf("invalid protocol versions range: %d-%d",
+			min, max)
+	}
+
+	if version = max; version > msg.Version {
+		version = msg.Version
+	}
+
+	if version < min || version < msg.MinVersion {
+		return 0, fmt.Errorf("incompatible protocol versions: %d-%d, want %d-%d",
+			min,
+			max,
+			msg.MinVersion,
+			msg.Version)
+	}
+
+	return
+}
+
+// create random challenge for the handshake
+func newChallenge() cipher.SHA256 {
+	return cipher.SumSHA256(cipher.RandByte(32))
+}
+
+// hash to sign for given challenge; the hash contains
+// id of node that verifies the proof, thus it's
+// impossible to retransmit a challenge to another
+// peer and use the proof; the params is hash of
+// parameters of the handshake (see handshakeParams),
+// thus a man in the middle can't change them (e.g.
+// turn the encryption off)
+func proofHash(
+	challenge cipher.SHA256, // : challenge to sign
+	verifier cipher.PubKey, //  : id of peer that verifies the proof
+	params cipher.SHA256, //    : parameters of the handshake
+) cipher.SHA256 {
+
+	var b = append(challenge[:], verifier[:]...)
+	b = append(b, params[:]...)
+
+	return cipher.SumSHA256(b)
+}
+
+// parameters of handshake both sides sign
+type handshakeParams struct {
+	Syn       msg.Syn       // the Syn as is
+	NodeID    cipher.PubKey // id of the acceptor
+	Challenge cipher.SHA256 // challenge of the acceptor
+	Encrypt   bool          // chosen by the acceptor
+	Protocol  uint16        // chosen by the acceptor
+	Features  msg.Features  // features of the acceptor
+}
+
+// paramsHash returns hash of parameters of handshake
+// for given Syn and Ack (the Proof of the Ack is not
+// used)
+func paramsHash(syn *msg.Syn, ack *msg.Ack) cipher.SHA256 {
+	return cipher.SumSHA256(encoder.Serialize(&handshakeParams{
+		Syn:       *syn,
+		NodeID:    ack.NodeID,
+		Challenge: ack.Challenge,
+		Encrypt:   ack.Encrypt,
+		Protocol:  ack.Protocol,
+		Features:  ack.Features,
+	}))
+}
+
+// sign challenge of the peer
+func (c *Conn) proof(
+	challenge cipher.SHA256, // : challenge of the peer
+	peer cipher.PubKey, //      : id of the peer
+	params cipher.SHA256, //    : parameters of the handshake
+) cipher.Sig {
+	return cipher.SignHash(proofHash(challenge, peer, params), c.n.idsk)
+}
+
+// verify proof of the peer
+func (c *Conn) verifyProof(
+	peer cipher.PubKey, //        : id of the peer
+	challenge cipher.SHA256, //   : challenge that this node sent
+	params cipher.SHA256, //      : parameters of the handshake
+	proof cipher.Sig, //          : signature of the peer
+) (
+	err error,
+) {
+
+	if err = peer.Verify(); err != nil {
+		return fmt.Errorf("invalid NodeID: %v", err)
+	}
+
+	var hash = proofHash(challenge, c.n.idpk, params)
+
+	if cipher.VerifySignature(peer, proof, hash) != nil {
+		return ErrInvalidProof
+	}
+
+	return
+}
+
 func (c *Conn) handshake(nodeCloseq <-chan struct{}) (err error) {
 
 	c.n.Debugf(ConnHskPin, "[%s] handshake", c.String())
 
+	var (
+		tm *time.Timer
+		tc <-chan time.Time
+	)
+
+	if rt := c.responseTimeout(); rt > 0 {
+		tm = time.NewTimer(rt)
+		tc = tm.C
+
+		defer tm.Stop()
+	}
+
 	if c.incoming == true {
-		return c.acceptHandshake(nodeCloseq)
+		return c.acceptHandshake(tc, nodeCloseq)
 	}
 
-	return c.performHandshake(nodeCloseq)
+	return c.performHandshake(tc, nodeCloseq)
 }
 
 func (c *Conn) sendNodeCloseq(
@@ -36,173 +184,425 @@ func (c *Conn) sendNodeCloseq(
 
 }
 
-func (c *Conn) performHandshake(nodeCloseq <-chan struct{}) (err error) {
+// receive next message of the handshake
+func (c *Conn) receiveNodeCloseq(
+	tc <-chan time.Time,
+	nodeCloseq <-chan struct{},
+) (
+	seq uint32,
+	rseq uint32,
+	m msg.Msg,
+	err error,
+) {
+
+	select {
+
+	case raw, ok := <-c.GetChanIn():
+
+		if ok == false {
+			err = ErrClosed
+			return
+		}
+
+		seq, rseq, m, err = c.decodeRaw(raw)
+
+	case <-tc:
+
+		err = ErrTimeout
+
+	case <-nodeCloseq:
+
+		err = ErrClosed
+
+	}
+
+	return
+
+}
+
+// receive response for given seq, the mseq
+// is seq of the response
+func (c *Conn) receiveResponseNodeCloseq(
+	seq uint32,
+	tc <-chan time.Time,
+	nodeCloseq <-chan struct{},
+) (
+	mseq uint32,
+	m msg.Msg,
+	err error,
+) {
+
+	var rseq uint32
+
+	if mseq, rseq, m, err = c.receiveNodeCloseq(tc, nodeCloseq); err != nil {
+		return
+	}
+
+	if rseq != seq {
+		err = errors.New("invlaid resposne for handshake: wrong seq")
+	}
+
+	return
+
+}
+
+func (c *Conn) performHandshake(
+	tc <-chan time.Time,
+	nodeCloseq <-chan struct{},
+) (
+	err error,
+) {
 
 	c.n.Debugf(ConnHskPin, "[%s] performHandshake", c.String())
 
 	// (1) send Syn
 	// (2) receive Ack or Err
+	// (3) send Auth or Err
+	// (4) receive Ok or Err
 
-	var seq = c.nextSeq()
+	var (
+		seq = c.nextSeq()
+		syn = &msg.Syn{
+			MinVersion: msg.MinVersion,
+			MaxVersion: msg.Version,
+			NodeID:     c.n.idpk,
+			Challenge:  newChallenge(),
+			Encryption: uint8(c.encryption()),
+			Features:   c.n.features(),
+		}
+	)
 
-	err = c.sendNodeCloseq(
-		c.encodeMsg(seq, 0, &msg.Syn{
-			Protocol: msg.Version,
-			NodeID:   c.n.idpk,
-		}),
-		nodeCloseq,
+	// (1)
+
+	if err = c.sendNodeCloseq(c.encodeMsg(seq, 0, syn), nodeCloseq); err != nil {
+		return
+	}
+
+	// (2)
+
+	var (
+		ackSeq uint32
+		m      msg.Msg
 	)
 
+	ackSeq, m, err = c.receiveResponseNodeCloseq(seq, tc, nodeCloseq)
+
 	if err != nil {
 		return
 	}
 
-	var (
-		rt time.Duration
+	var ack *msg.Ack
 
-		tm *time.Timer
-		tc <-chan time.Time
-	)
+	switch x := m.(type) {
+
+	case *msg.Ack:
+
+		ack = x
+
+	case *msg.Err:
+
+		return errors.New(x.Err)
+
+	default:
+
+		return fmt.Errorf("invalid response type for handshake: %T", m)
 
-	if c.IsTCP() == true {
-		rt = c.n.config.TCP.ResponseTimeout
-	} else {
-		rt = c.n.config.UDP.ResponseTimeout
 	}
 
-	if rt > 0 {
-		tm = time.NewTimer(rt)
-		tc = tm.C
+	var (
+		params = paramsHash(syn, ack)
+		ss     *session
+	)
 
-		defer tm.Stop()
+	if ss, err = c.checkAck(ack, syn.Challenge, params); err != nil {
+		return c.rejectHandshake(ackSeq, err, nodeCloseq)
 	}
 
-	select {
+	// (3)
 
-	case raw, ok := <-c.GetChanIn():
+	seq = c.nextSeq()
 
-		if ok == false {
-			return ErrClosed
-		}
+	var auth = &msg.Auth{
+		Proof: c.proof(ack.Challenge, ack.NodeID, params),
+	}
 
-		var (
-			rseq uint32
-			m    msg.Msg
-		)
+	if err = c.sendNodeCloseq(c.encodeMsg(seq, 0, auth), nodeCloseq); err != nil {
+		return
+	}
 
-		if _, rseq, m, err = c.decodeRaw(raw); err != nil {
-			return
-		}
+	// (4)
 
-		if rseq != seq {
-			return errors.New("invlaid resposne for handshake: wrong seq")
-		}
+	if _, m, err = c.receiveResponseNodeCloseq(seq, tc, nodeCloseq); err != nil {
+		return
+	}
 
-		switch x := m.(type) {
+	switch x := m.(type) {
 
-		case *msg.Ack:
+	case *msg.Ok:
 
-			c.peerID = x.NodeID
+		c.peerID = ack.NodeID // verified
+		c.verified = true
+		c.ss = ss // encrypted or not
+		c.protocol = ack.Protocol
+		c.features = ack.Features
 
-			return // ok
+		return // ok
 
-		case *msg.Err:
+	case *msg.Err:
 
-			return errors.New(x.Err)
+		return errors.New(x.Err)
 
-		default:
+	default:
 
-			return fmt.Errorf("invalid response type for handshake: %T", m)
+		return fmt.Errorf("invalid response type for handshake: %T", m)
 
-		}
+	}
 
-	case <-tc:
+}
+
+// check received Ack, the initiator should reply
+// with Err if the Ack is not acceptable
+func (c *Conn) checkAck(
+	ack *msg.Ack, //              : received Ack
+	challenge cipher.SHA256, //   : challenge of the Syn
+	params cipher.SHA256, //      : hash of the handshake
+) (
+	ss *session, //               : session or nil if not encrypted
+	err error, //                 : reason to reject the Ack
+) {
 
-		return ErrTimeout
+	if ack.Protocol < msg.MinVersion || ack.Protocol > msg.Version {
+		return nil, fmt.Errorf("peer chose unsupported protocol version %d",
+			ack.Protocol)
+	}
 
-	case <-nodeCloseq:
+	if err = c.verifyProof(ack.NodeID, challenge, params, ack.Proof); err != nil {
+		return
+	}
 
-		return ErrClosed
+	if err = c.checkAccess(ack.NodeID); err != nil {
+		return
+	}
 
+	switch {
+	case ack.Encrypt == true && c.encryption() == EncryptionOff:
+		err = errors.New("peer uses encryption, but it's turned off")
+	case ack.Encrypt == false && c.encryption() == EncryptionRequired:
+		err = ErrEncryptionRequired
+	case ack.Encrypt == true:
+		ss, err = c.newSession(ack.NodeID, challenge, ack.Challenge)
 	}
 
+	return
 }
 
-func (c *Conn) acceptHandshake(nodeCloseq <-chan struct{}) (err error) {
+// send Err to peer and return the error
+func (c *Conn) rejectHandshake(
+	rseq uint32,
+	reason error,
+	nodeCloseq <-chan struct{},
+) (
+	err error,
+) {
+
+	c.sendNodeCloseq(
+		c.encodeMsg(c.nextSeq(), rseq, &msg.Err{Err: reason.Error()}),
+		nodeCloseq,
+	)
+
+	return reason
+}
+
+func (c *Conn) acceptHandshake(
+	tc <-chan time.Time,
+	nodeCloseq <-chan struct{},
+) (
+	err error,
+) {
 
 	c.n.Debugf(ConnHskPin, "[%s] acceptHandshake", c.String())
 
 	// (1) receive the Syn
 	// (2) send the Ack or Err
+	// (3) receive the Auth
+	// (4) send the Ok or Err
 
 	// (1)
 
 	var (
-		raw []byte
-		ok  bool
+		seq uint32
+		m   msg.Msg
 	)
 
-	select {
-	case raw, ok = <-c.GetChanIn():
+	if seq, _, m, err = c.receiveNodeCloseq(tc, nodeCloseq); err != nil {
+		return
+	}
 
-		if ok == false {
-			return ErrClosed
+	var syn, ok = m.(*msg.Syn)
+
+	if ok == false {
+		return fmt.Errorf(
+			"invalid messege type received (expected handshake): %T",
+			m,
+		)
+	}
+
+	if err = syn.NodeID.Verify(); err != nil {
+		err = fmt.Errorf("invalid NodeID: %v", err)
+		return c.rejectHandshake(seq, err, nodeCloseq)
+	}
+
+	var encrypt bool
+
+	encrypt, err = c.encryption().negotiate(EncryptionMode(syn.Encryption))
+
+	if err != nil {
+		return c.rejectHandshake(seq, err, nodeCloseq)
+	}
+
+	if syn.MaxVersion == msg.LegacyVersion {
+		if c.n.config.AcceptLegacy == false {
+			err = fmt.Errorf("protocol version %d is not accepted",
+				msg.LegacyVersion)
+			return c.rejectHandshake(seq, err, nodeCloseq)
 		}
+		return c.acceptUnauthenticated(seq, syn, nodeCloseq)
+	}
 
-	case <-nodeCloseq:
-		return ErrClosed
+	var version uint16
+
+	version, err = negotiateVersion(syn.MinVersion, syn.MaxVersion)
+
+	if err != nil {
+		return c.rejectHandshake(seq, err, nodeCloseq)
 	}
 
+	// (2) send Ack back
+
 	var (
-		seq uint32
-		m   msg.Msg
+		ackSeq    = c.nextSeq()
+		challenge = newChallenge()
+
+		ack = &msg.Ack{
+			NodeID:    c.n.idpk,
+			Challenge: challenge,
+			Encrypt:   encrypt,
+			Protocol:  version,
+			Features:  c.n.features(),
+		}
+
+		params = paramsHash(syn, ack)
+	)
+
+	ack.Proof = c.proof(syn.Challenge, syn.NodeID, params)
+
+	err = c.sendNodeCloseq(
+		c.encodeMsg(ackSeq, seq, ack),
+		nodeCloseq,
 	)
 
-	if seq, _, m, err = c.decodeRaw(raw); err != nil {
+	if err != nil {
+		return
+	}
+
+	// (3)
+
+	if seq, _, m, err = c.receiveNodeCloseq(tc, nodeCloseq); err != nil {
 		return
 	}
 
+	var auth *msg.Auth
+
 	switch x := m.(type) {
 
-	case *msg.Syn:
+	case *msg.Auth:
 
-		if x.Protocol != msg.Version {
+		auth = x
 
-			err = fmt.Errorf("incompatible protocol version: %d, want %d",
-				x.Protocol,
-				msg.Version)
+	case *msg.Err:
 
-			// send Err back
+		return errors.New(x.Err)
 
-			c.sendNodeCloseq(
-				c.encodeMsg(c.nextSeq(), seq, &msg.Err{Err: err.Error()}),
-				nodeCloseq,
-			)
+	default:
 
-			return
+		return fmt.Errorf(
+			"invalid messege type received (expected Auth): %T",
+			m,
+		)
 
-		}
+	}
 
-		c.peerID = x.NodeID
+	if err = c.verifyProof(syn.NodeID, challenge, params, auth.Proof); err != nil {
+		return c.rejectHandshake(seq, err, nodeCloseq)
+	}
 
-		// (2) send Ack back
+	if err = c.checkAccess(syn.NodeID); err != nil {
+		return c.rejectHandshake(seq, err, nodeCloseq)
+	}
 
-		err = c.sendNodeCloseq(
-			c.encodeMsg(c.nextSeq(), seq, &msg.Ack{
-				NodeID: c.n.idpk,
-			}),
-			nodeCloseq,
-		)
+	var ss *session
+
+	if encrypt == true {
+		ss, err = c.newSession(syn.NodeID, syn.Challenge, challenge)
+		if err != nil {
+			return c.rejectHandshake(seq, err, nodeCloseq)
+		}
+	}
+
+	// (4)
+
+	err = c.sendNodeCloseq(
+		c.encodeMsg(c.nextSeq(), seq, &msg.Ok{}),
+		nodeCloseq,
+	)
 
+	if err != nil {
 		return
+	}
 
-	default:
+	c.peerID = syn.NodeID // verified
+	c.verified = true
+	c.ss = ss // encrypted or not
+	c.protocol = version
+	c.features = syn.Features
 
-		return fmt.Errorf(
-			"invalid messege type received (expected handshake): %T",
-			m,
-		)
+	return
+
+}
 
+// accept handshake of a peer that doesn't prove its
+// NodeID (the msg.LegacyVersion); the Ack is the last
+// message of such handshake, and the connection is not
+// encrypted; rules of access lists and of the Policy
+// are never matched against the NodeID of such peer
+func (c *Conn) acceptUnauthenticated(
+	seq uint32,
+	syn *msg.Syn,
+	nodeCloseq <-chan struct{},
+) (
+	err error,
+) {
+
+	c.n.Debugf(ConnHskPin, "[%s] acceptUnauthenticated", c.String())
+
+	if err = c.checkAccess(cipher.PubKey{}); err != nil {
+		return c.rejectHandshake(seq, err, nodeCloseq)
 	}
 
+	err = c.sendNodeCloseq(
+		c.encodeMsg(c.nextSeq(), seq, &msg.Ack{
+			NodeID:   c.n.idpk,
+			Protocol: msg.LegacyVersion,
+		}),
+		nodeCloseq,
+	)
+
+	if err != nil {
+		return
+	}
+
+	c.peerID = syn.NodeID // not verified
+	c.protocol = msg.LegacyVersion
+
+	return
 }
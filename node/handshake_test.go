@@ -0,0 +1,650 @@
+package node
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func Test_handshake(t *testing.T) {
+
+	var (
+		lconf = getTestConfig("server")
+		lcq   = make(chan *Conn, 1)
+	)
+
+	lconf.OnConnect = func(c *Conn) (_ error) {
+		lcq <- c
+		return
+	}
+
+	var ln, err = NewNode(lconf)
+	assertNil(t, err)
+
+	var cn = getTestNodeNotListen("client")
+
+	defer ln.Close()
+	defer cn.Close()
+
+	var c *Conn
+	c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	if c.PeerID() != ln.ID() {
+		t.Error("wrong PeerID")
+	}
+
+	// the listener can establish the connection
+	// a bit later than the Connect returns
+
+	var lc *Conn
+
+	select {
+	case lc = <-lcq:
+	case <-time.After(TM):
+		t.Fatal("slow")
+	}
+
+	if lc.PeerID() != cn.ID() {
+		t.Error("wrong PeerID")
+	}
+
+	for _, x := range []*Conn{c, lc} {
+
+		if x.Protocol() != msg.Version {
+			t.Error("wrong protocol version:", x.Protocol())
+		}
+
+		if x.PeerFeatures() != msg.Supported {
+			t.Error("wrong features:", x.PeerFeatures())
+		}
+
+		if x.Supports(msg.FeatureBatch) == false {
+			t.Error("doesn't support batch")
+		}
+
+		if x.IsAuthenticated() == false {
+			t.Error("not authenticated")
+		}
+
+	}
+
+}
+
+func Test_negotiateVersion(t *testing.T) {
+
+	for _, tt := range []struct {
+		min, max uint16
+		version  uint16
+		err      bool
+	}{
+		{msg.MinVersion, msg.Version, msg.Version, false},
+		{msg.MinVersion, msg.MinVersion, msg.MinVersion, false},
+		{msg.MinVersion, msg.Version + 10, msg.Version, false},
+		{msg.Version + 1, msg.Version + 10, 0, true},
+		{1, msg.MinVersion - 1, 0, true},
+		{msg.Version + 1, msg.MinVersion, 0, true},
+	} {
+
+		var version, err = negotiateVersion(tt.min, tt.max)
+
+		if tt.err == true {
+			if err == nil {
+				t.Errorf("%d-%d: missing error", tt.min, tt.max)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%d-%d: unexpected error: %v", tt.min, tt.max, err)
+		} else if version != tt.version {
+			t.Errorf("%d-%d: wrong version %d, want %d", tt.min, tt.max,
+				version, tt.version)
+		}
+
+	}
+
+}
+
+func Test_legacySyn(t *testing.T) {
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	// Syn of the legacy version
+
+	var raw = append([]byte{byte(msg.SynType)}, encoder.Serialize(struct {
+		Protocol uint16
+		NodeID   cipher.PubKey
+	}{msg.LegacyVersion, pk})...)
+
+	var m, err = msg.Decode(raw)
+	assertNil(t, err)
+
+	var syn, ok = m.(*msg.Syn)
+
+	if ok == false {
+		t.Fatalf("wrong type %T", m)
+	}
+
+	if syn.MinVersion != msg.LegacyVersion ||
+		syn.MaxVersion != msg.LegacyVersion ||
+		syn.NodeID != pk {
+
+		t.Error("wrong Syn")
+	}
+
+	if syn.Encryption != uint8(EncryptionOff) || syn.Features != 0 {
+		t.Error("wrong encryption or features:", syn.Encryption, syn.Features)
+	}
+
+	// Ack for old peer
+
+	var ack = &msg.Ack{NodeID: pk, Protocol: msg.LegacyVersion}
+
+	if len(ack.Encode()) != 1+len(pk) {
+		t.Error("wrong length of Ack for old peer")
+	}
+
+}
+
+// encode raw message of the protocol with
+// given seq and response seq
+func testRawMsg(seq, rseq uint32, m []byte) (raw []byte) {
+	raw = make([]byte, 8, 8+len(m))
+	binary.LittleEndian.PutUint32(raw, seq)
+	binary.LittleEndian.PutUint32(raw[4:], rseq)
+	return append(raw, m...)
+}
+
+// receive raw message with timeout
+func testReceiveRaw(t *testing.T, uc Connection) (seq, rseq uint32, m []byte) {
+
+	select {
+	case raw, ok := <-uc.GetChanIn():
+		if ok == false {
+			t.Fatal("closed")
+		}
+		if len(raw) < 9 {
+			t.Fatal("too short message")
+		}
+		return binary.LittleEndian.Uint32(raw),
+			binary.LittleEndian.Uint32(raw[4:]),
+			raw[8:]
+	case <-time.After(TM):
+		t.Fatal("slow")
+	}
+
+	return
+}
+
+// create Node that listens given Unix socket
+// and accepts peers of the legacy version
+func testLegacyListener(
+	t *testing.T,
+	path string,
+	configure func(lc *Config),
+) (
+	ln *Node,
+	lcq <-chan *Conn,
+) {
+
+	var (
+		lc  = getTestConfigNotListen("server")
+		cq  = make(chan *Conn, 1)
+		err error
+	)
+
+	lc.Unix.Listen = path
+	lc.AcceptLegacy = true
+	lc.OnConnect = func(c *Conn) (_ error) {
+		cq <- c
+		return
+	}
+
+	if configure != nil {
+		configure(lc)
+	}
+
+	ln, err = NewNode(lc)
+	assertNil(t, err)
+
+	return ln, cq
+}
+
+// connect to given Unix socket as a peer of the legacy
+// version with given NodeID, returning reply for the Syn
+func testLegacySyn(
+	t *testing.T,
+	path string,
+	pk cipher.PubKey,
+) (
+	uc Connection,
+	reply []byte,
+) {
+
+	var nc, err = net.Dial("unix", path)
+	assertNil(t, err)
+
+	uc = newUnixConnection(nc, path)
+
+	var synRaw = append([]byte{byte(msg.SynType)}, encoder.Serialize(struct {
+		Protocol uint16
+		NodeID   cipher.PubKey
+	}{msg.LegacyVersion, pk})...)
+
+	uc.GetChanOut() <- testRawMsg(1, 0, synRaw)
+
+	var rseq uint32
+	if _, rseq, reply = testReceiveRaw(t, uc); rseq != 1 {
+		t.Error("wrong response seq:", rseq)
+	}
+
+	return
+}
+
+func Test_handshake_version3(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		path       = filepath.Join(dir, "cxo.sock")
+		ln, lcq    = testLegacyListener(t, path, nil)
+		pk, _      = cipher.GenerateKeyPair()
+		uc, ackRaw = testLegacySyn(t, path, pk)
+	)
+
+	defer ln.Close()
+	defer uc.Close()
+
+	var want = append([]byte{byte(msg.AckType)}, encoder.Serialize(struct {
+		NodeID cipher.PubKey
+	}{ln.ID()})...)
+
+	if bytes.Equal(ackRaw, want) == false {
+		t.Error("wrong Ack for version 3")
+	}
+
+	var c *Conn
+
+	select {
+	case c = <-lcq:
+	case <-time.After(TM):
+		t.Fatal("slow")
+	}
+
+	if c.PeerID() != pk || c.Protocol() != msg.LegacyVersion {
+		t.Error("wrong connection:", c.PeerID().Hex(), c.Protocol())
+	}
+
+	if c.IsAuthenticated() == true {
+		t.Error("authenticated")
+	}
+
+	if c.Supports(msg.FeatureBatch) == true || c.IsEncrypted() == true {
+		t.Error("wrong features or encryption")
+	}
+
+	if _, ok := ln.hasPeer(pk); ok == true {
+		t.Error("not verified NodeID is used")
+	}
+
+	if len(ln.Connections()) != 1 {
+		t.Error("wrong number of connections:", len(ln.Connections()))
+	}
+
+}
+
+// expect Err
+func testLegacyRejected(t *testing.T, reply []byte) {
+
+	var m, err = msg.Decode(reply)
+	assertNil(t, err)
+
+	if _, ok := m.(*msg.Err); ok == false {
+		t.Errorf("wrong message %T", m)
+	}
+
+}
+
+func Test_handshake_legacyOff(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		path  = filepath.Join(dir, "cxo.sock")
+		ln, _ = testLegacyListener(t, path, func(lc *Config) {
+			lc.AcceptLegacy = false // default
+		})
+		pk, _      = cipher.GenerateKeyPair()
+		uc, errRaw = testLegacySyn(t, path, pk)
+	)
+
+	defer ln.Close()
+	defer uc.Close()
+
+	testLegacyRejected(t, errRaw)
+
+	if len(ln.Connections()) != 0 {
+		t.Error("legacy peer accepted")
+	}
+
+}
+
+func Test_handshake_legacyAccess(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		path = filepath.Join(dir, "cxo.sock")
+		cn   = getTestNodeNotListen("client")
+		feed = cipher.PubKey{1, 2, 3}
+
+		ln, lcq = testLegacyListener(t, path, func(lc *Config) {
+			lc.Policy = NewPolicy()
+			assertNil(t, lc.Policy.Allow(PolicyRule{Feed: feed, Peer: cn.ID()}))
+		})
+	)
+
+	defer cn.Close()
+	defer ln.Close()
+
+	assertNil(t, ln.Share(feed))
+
+	// claim NodeID of the client
+
+	var uc, ackRaw = testLegacySyn(t, path, cn.ID())
+	defer uc.Close()
+
+	if ackRaw[0] != byte(msg.AckType) {
+		t.Fatal("rejected")
+	}
+
+	select {
+	case <-lcq:
+	case <-time.After(TM):
+		t.Fatal("slow")
+	}
+
+	// policy
+
+	uc.GetChanOut() <- testRawMsg(2, 0, (&msg.Sub{Feed: feed}).Encode())
+
+	var _, rseq, reply = testReceiveRaw(t, uc)
+
+	if rseq != 2 {
+		t.Error("wrong response seq:", rseq)
+	}
+
+	testLegacyRejected(t, reply)
+
+	// the real peer with the NodeID
+
+	var c *Conn
+	c, err = cn.Unix().Connect(path)
+	assertNil(t, err)
+
+	if c.IsAuthenticated() == false {
+		t.Error("not authenticated")
+	}
+
+	assertNil(t, c.Subscribe(feed))
+
+	// access list
+
+	assertNil(t, ln.Allow(cn.ID().Hex()))
+
+	waitFor(t, func() bool {
+		return len(ln.Connections()) == 1
+	})
+
+	if lc, ok := ln.hasPeer(cn.ID()); ok == false {
+		t.Error("missing connection")
+	} else if lc.IsAuthenticated() == false {
+		t.Error("not authenticated")
+	}
+
+	var uc2, errRaw = testLegacySyn(t, path, cn.ID())
+	defer uc2.Close()
+
+	testLegacyRejected(t, errRaw)
+
+}
+
+func Test_verifyProof(t *testing.T) {
+
+	var (
+		n = getTestNodeNotListen("test")
+		c = &Conn{n: n}
+
+		challenge = newChallenge()
+
+		pk, sk = cipher.GenerateKeyPair()
+		proof  = cipher.SignHash(proofHash(challenge, n.ID(), cipher.SHA256{}), sk)
+	)
+
+	defer n.Close()
+
+	assertNil(t, c.verifyProof(pk, challenge, cipher.SHA256{}, proof))
+
+	// forged NodeID (proof signed by another key)
+
+	var fpk, _ = cipher.GenerateKeyPair()
+
+	if c.verifyProof(fpk, challenge, cipher.SHA256{}, proof) != ErrInvalidProof {
+		t.Error("missing ErrInvalidProof")
+	}
+
+	// replayed proof (another challenge)
+
+	if c.verifyProof(pk, newChallenge(), cipher.SHA256{}, proof) != ErrInvalidProof {
+		t.Error("missing ErrInvalidProof")
+	}
+
+	// proof for another verifier
+
+	proof = cipher.SignHash(proofHash(challenge, fpk, cipher.SHA256{}), sk)
+
+	if c.verifyProof(pk, challenge, cipher.SHA256{}, proof) != ErrInvalidProof {
+		t.Error("missing ErrInvalidProof")
+	}
+
+}
+
+// forward messages from one connection to another
+func testForward(from, to Connection) {
+	defer to.Close()
+	for raw := range from.GetChanIn() {
+		to.GetChanOut() <- raw
+	}
+}
+
+func Test_handshake_signedParams(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		path  = filepath.Join(dir, "cxo.sock")
+		proxy = filepath.Join(dir, "proxy.sock")
+
+		lc = getTestConfigNotListen("server")
+		cn = getTestNodeNotListen("client")
+
+		ln *Node
+		l  net.Listener
+	)
+
+	defer cn.Close()
+
+	lc.Unix.Listen = path
+
+	ln, err = NewNode(lc)
+	assertNil(t, err)
+	defer ln.Close()
+
+	l, err = net.Listen("unix", proxy)
+	assertNil(t, err)
+	defer l.Close()
+
+	var errq = make(chan error, 1)
+
+	go func() {
+		var _, err = cn.Unix().Connect(proxy)
+		errq <- err
+	}()
+
+	// man in the middle turns encryption of the Syn off
+
+	var nc net.Conn
+	nc, err = l.Accept()
+	assertNil(t, err)
+
+	var (
+		cuc = newUnixConnection(nc, proxy) // client side
+		luc Connection                     // listener side
+	)
+
+	defer cuc.Close()
+
+	if nc, err = net.Dial("unix", path); err != nil {
+		t.Fatal(err)
+	}
+
+	luc = newUnixConnection(nc, path)
+	defer luc.Close()
+
+	// the Encryption is followed by 4 bytes of the Features
+
+	var syn = <-cuc.GetChanIn()
+	syn[len(syn)-5] = byte(EncryptionOff)
+	luc.GetChanOut() <- syn
+
+	go testForward(cuc, luc)
+	go testForward(luc, cuc)
+
+	select {
+	case err = <-errq:
+		if err != ErrInvalidProof {
+			t.Error("wrong error:", err)
+		}
+	case <-time.After(TM):
+		t.Fatal("slow")
+	}
+
+	waitFor(t, func() bool {
+		return len(ln.Connections()) == 0
+	})
+
+}
+
+func Test_handshake_rejectAck(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		path = filepath.Join(dir, "cxo.sock")
+
+		cc     = getTestConfigNotListen("client")
+		pk, sk = cipher.GenerateKeyPair()
+
+		cn *Node
+		l  net.Listener
+	)
+
+	cc.Unix.Encryption = EncryptionOff
+
+	cn, err = NewNode(cc)
+	assertNil(t, err)
+	defer cn.Close()
+
+	l, err = net.Listen("unix", path)
+	assertNil(t, err)
+	defer l.Close()
+
+	var errq = make(chan error, 1)
+
+	go func() {
+		var _, err = cn.Unix().Connect(path)
+		errq <- err
+	}()
+
+	// peer that wants encryption
+
+	var nc net.Conn
+	nc, err = l.Accept()
+	assertNil(t, err)
+
+	var uc = newUnixConnection(nc, path)
+	defer uc.Close()
+
+	var seq, _, synRaw = testReceiveRaw(t, uc)
+
+	var m msg.Msg
+	if m, err = msg.Decode(synRaw); err != nil {
+		t.Fatal(err)
+	}
+
+	var syn, ok = m.(*msg.Syn)
+
+	if ok == false {
+		t.Fatalf("wrong message %T", m)
+	}
+
+	var ack = &msg.Ack{
+		NodeID:    pk,
+		Challenge: newChallenge(),
+		Encrypt:   true,
+		Protocol:  msg.Version,
+		Features:  msg.Supported,
+	}
+
+	ack.Proof = cipher.SignHash(proofHash(syn.Challenge, syn.NodeID,
+		paramsHash(syn, ack)), sk)
+
+	uc.GetChanOut() <- testRawMsg(1, seq, ack.Encode())
+
+	// the Err instead of the Auth
+
+	var _, rseq, errRaw = testReceiveRaw(t, uc)
+
+	if rseq != 1 {
+		t.Error("wrong response seq:", rseq)
+	}
+
+	if m, err = msg.Decode(errRaw); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok = m.(*msg.Err); ok == false {
+		t.Errorf("wrong message %T", m)
+	}
+
+	select {
+	case err = <-errq:
+		if err == nil {
+			t.Error("missing error")
+		}
+	case <-time.After(TM):
+		t.Fatal("slow")
+	}
+
+}
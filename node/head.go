@@ -43,6 +43,9 @@ func newNodeHead(nf *nodeFeed) (n *nodeHead) {
 	n.errq = make(chan error)
 	n.closeq = make(chan struct{})
 
+	n.inforq = make(chan struct{})
+	n.inforn = make(chan *headInfo)
+
 	n.await.Add(1)
 	go n.handle()
 
@@ -92,11 +95,30 @@ func (n *nodeHead) node() *Node {
 	return n.n.fs.n
 }
 
+// a request of objects, the same request can be
+// sent to two peers (hedged), and the first
+// response is used
+type fillRequest struct {
+	seq  uint64          // seq of the filling Root
+	keys []cipher.SHA256 // requested objects
+
+	c       *Conn       // first peer
+	running int         // peers requested
+	hedged  bool        // sent to second peer
+	done    bool        // has successful response
+	hedge   *time.Timer // hedge timer or nil
+}
+
 type failedRequest struct {
-	c   *Conn         // connection
-	seq uint64        // seq of the filling Root
-	key cipher.SHA256 // requested object
-	err error         // failed if the err is not nil
+	c   *Conn        // connection
+	rq  *fillRequest // the request
+	err error        // failed if the err is not nil
+}
+
+type succeededRequest struct {
+	c       *Conn           // connection
+	rq      *fillRequest    // the request
+	missing []cipher.SHA256 // objects not received (batch)
 }
 
 // handle local "fields" of the nodeHead
@@ -105,8 +127,9 @@ type fillHead struct {
 
 	r  connRoot           // filling Root
 	f  *skyobject.Filler  // filler of the r
-	rq chan cipher.SHA256 // request objects (TODO: maxParall)
-	ff chan error         // filler failure
+	rq  chan cipher.SHA256 // request objects (TODO: maxParall)
+	prq chan cipher.SHA256 // request prioritized objects
+	ff  chan error         // filler failure
 
 	ft *time.Timer      // fill timeout
 	tc <-chan time.Time // ------------
@@ -118,13 +141,16 @@ type fillHead struct {
 
 	cs knownRoots // conn -> known root objects (seq)
 
-	successq chan *Conn         // succeeded requests
-	failureq chan failedRequest // failed requests
+	successq chan succeededRequest // succeeded requests
+	failureq chan failedRequest    // failed requests
+	hedgeq   chan *fillRequest     // slow requests
 
-	rqo *list.List // request objects (cipher.SHA256)
-	fc  *list.List // connections to fill from (*Conn)
+	rqo *list.List    // request objects (cipher.SHA256)
+	pl  *list.Element // last prioritized object of the rqo
+	fc  *list.List    // connections to fill from (*Conn)
 
-	requesting int // number of running requests
+	inflight   map[*fillRequest]struct{} // running requests
+	requesting int                       // number of requested peers
 }
 
 func (n *nodeHead) handle() {
@@ -146,14 +172,19 @@ func (n *nodeHead) handle() {
 
 			ff: make(chan error), // filling error or nil (success)
 
-			successq: make(chan *Conn),         // release connection
-			failureq: make(chan failedRequest), // failed requests
+			successq: make(chan succeededRequest), // release connection
+			failureq: make(chan failedRequest),    // failed requests
+			hedgeq:   make(chan *fillRequest),     // slow requests
+
+			inflight: make(map[*fillRequest]struct{}),
 		}
 
 		key cipher.SHA256
 		c   *Conn
 		cr  connRoot
+		sc  succeededRequest
 		fc  failedRequest
+		hr  *fillRequest
 		err error // fillign failure or nil
 	)
 
@@ -163,14 +194,22 @@ func (n *nodeHead) handle() {
 
 			f.handleRequest(key)
 
-		case c = <-f.successq:
+		case key = <-f.prq:
+
+			f.handlePriorityRequest(key)
 
-			f.handleSuccess(c)
+		case sc = <-f.successq:
+
+			f.handleSuccess(sc)
 
 		case fc = <-f.failureq:
 
 			f.handleRequestFailure(fc)
 
+		case hr = <-f.hedgeq:
+
+			f.handleHedge(hr)
+
 		case err = <-f.ff:
 
 			f.handleFillingResult(err)
@@ -221,19 +260,83 @@ func (f *fillHead) handleRequest(key cipher.SHA256) {
 	f.triggerRequest()
 }
 
-func (f *fillHead) handleSuccess(c *Conn) {
-	f.node().Debugln(FillPin, "[fill] handleSuccess", c.String())
+// prioritized objects are requested ahead of the
+// rest, but after prioritized objects requested
+// before (keep order)
+func (f *fillHead) handlePriorityRequest(key cipher.SHA256) {
+	f.node().Debugln(FillPin, "[fill] handlePriorityRequest", key.Hex()[:7])
+
+	if f.pl == nil {
+		f.pl = f.rqo.PushFront(key)
+	} else {
+		f.pl = f.rqo.InsertAfter(key, f.pl)
+	}
+
+	f.triggerRequest()
+}
+
+// remove first object of the rqo
+func (f *fillHead) shift() (key cipher.SHA256) {
+
+	var el = f.rqo.Front()
+
+	if el == f.pl {
+		f.pl = nil // no more prioritized objects
+	}
+
+	return f.rqo.Remove(el).(cipher.SHA256)
+}
+
+func (f *fillHead) handleSuccess(sr succeededRequest) {
+	f.node().Debugln(FillPin, "[fill] handleSuccess", sr.c.String(),
+		len(sr.missing))
+
+	if _, ok := f.inflight[sr.rq]; ok == false {
+		return // request of previous filling
+	}
 
 	f.requesting--
-	f.fc.PushBack(c) // push
+	f.fc.PushBack(sr.c) // push
+
+	if sr.rq.done == false {
+		sr.rq.done = true // the first response
+		f.requeue(sr.missing)
+	}
+
+	f.release(sr.rq)
 	f.triggerRequest()
+
+	if f.node().config.OnFillingProgress != nil && f.r.r != nil {
+		f.node().onFillingProgress(f.progress())
+	}
+}
+
+// a peer of given request released the request
+func (f *fillHead) release(rq *fillRequest) {
+
+	rq.running--
+
+	if rq.hedge != nil {
+		rq.hedge.Stop()
+	}
+
+	if rq.running == 0 {
+		delete(f.inflight, rq)
+	}
+
+}
+
+// push given keys to the front of the list of
+// objects to request (keep order)
+func (f *fillHead) requeue(keys []cipher.SHA256) {
+	for i := len(keys) - 1; i >= 0; i-- {
+		f.rqo.PushFront(keys[i]) // shift
+	}
 }
 
 func (f *fillHead) handleRequestFailure(fr failedRequest) {
 	f.node().Debugln(FillPin, "[fill] handleRequestFailure", fr.c.String(),
-		fr.key.Hex()[:7])
-
-	f.requesting--
+		len(fr.rq.keys))
 
 	switch fr.err {
 	case ErrInvalidResponse:
@@ -250,7 +353,7 @@ func (f *fillHead) handleRequestFailure(fr failedRequest) {
 	case ErrTimeout:
 
 		// probably don't have object we're requesting anymore
-		f.cs.removeKnown(fr.c, fr.seq)
+		f.cs.removeKnown(fr.c, fr.rq.seq)
 
 	default:
 
@@ -258,11 +361,51 @@ func (f *fillHead) handleRequestFailure(fr failedRequest) {
 
 	}
 
-	f.rqo.PushFront(fr.key) // shift
+	if _, ok := f.inflight[fr.rq]; ok == false {
+		return // request of previous filling
+	}
+
+	f.requesting--
+	f.release(fr.rq)
+
+	// requeue if another peer is not requested
+	if fr.rq.running == 0 && fr.rq.done == false {
+		f.requeue(fr.rq.keys)
+	}
+
 	f.triggerRequest()
 
 }
 
+// send slow request to another peer too
+func (f *fillHead) handleHedge(rq *fillRequest) {
+
+	if _, ok := f.inflight[rq]; ok == false {
+		return // already done
+	}
+
+	if rq.done == true || rq.hedged == true {
+		return
+	}
+
+	f.node().Debugln(FillPin, "[fill] handleHedge", rq.c.String(),
+		len(rq.keys))
+
+	var c = f.bestConn(func(c *Conn) bool {
+		return c != rq.c &&
+			(len(rq.keys) == 1 || c.Supports(msg.FeatureBatch) == true)
+	})
+
+	if c == nil {
+		return // no idle connections
+	}
+
+	rq.hedged = true
+	c.sc.hedge()
+
+	f.send(c, rq)
+}
+
 func (f *fillHead) handleReceivedRoot(cr connRoot) {
 	f.node().Debugln(FillPin, "[fill] handleReceivedRoot",
 		cr.c.String(), cr.r.Short())
@@ -275,14 +418,26 @@ func (f *fillHead) handleReceivedRoot(cr connRoot) {
 			return // ignore the old Root
 		}
 
-		f.cs.addKnown(cr.c, cr.r.Seq) // add to known
-
 		if cr.r.Seq == f.r.r.Seq {
-			f.fc.PushBack(cr.c) // add to filling connections
+
+			if cr.r.Hash != f.r.r.Hash {
+				f.equivocation(f.r, cr) // keep the filling one
+				return
+			}
+
+			f.cs.addKnown(cr.c, cr.r.Seq) // add to known
+			f.fc.PushBack(cr.c)           // add to filling connections
 			f.triggerRequest()
 			return
 		}
 
+		if f.p.r != nil && f.p.r.Seq == cr.r.Seq && f.p.r.Hash != cr.r.Hash {
+			f.equivocation(f.p, cr) // keep the pending one
+			return
+		}
+
+		f.cs.addKnown(cr.c, cr.r.Seq) // add to known
+
 		if f.p.r == nil {
 
 			// callback
@@ -322,6 +477,29 @@ func (f *fillHead) handleReceivedRoot(cr connRoot) {
 
 }
 
+// two different Root objects with the same seq, the
+// first is filling or pending one; the equivocation
+// saved and the second Root dropped
+func (f *fillHead) equivocation(first, second connRoot) {
+
+	var e, isNew, err = f.node().c.AddEquivocation(first.r, second.r)
+
+	if err != nil {
+		f.node().Printf("[ERR] [%s] can't save equivocation %s: %v",
+			second.c.String(), second.r.Short(), err)
+		return
+	}
+
+	if isNew == true {
+		f.node().onEquivocation(second.c, e) // callback
+	}
+
+	if f.node().config.RefuseEquivocated == true && f.p.r != nil {
+		f.p = connRoot{} // refuse the next one
+	}
+
+}
+
 // value for channels, if hte (*Node).maxFillingParallel
 // is zero, then the skyobject.Filler has no limits for
 // goroutines, but we can't create an unlimited channel,
@@ -351,6 +529,20 @@ func (f *fillHead) createFiller(cr connRoot) {
 	f.rq = make(chan cipher.SHA256, f.maxParallel())
 	f.f = f.node().c.Fill(cr.r, f.rq, f.maxParallel())
 
+	if f.node().config.DeltaSync == true {
+		var prev, err = f.node().c.LastRoot(cr.r.Pub, cr.r.Nonce)
+		if err == nil && prev.Seq < cr.r.Seq {
+			f.f.Delta(prev)
+		}
+	}
+
+	if fp := f.node().fillPriority(cr.r); fp != nil {
+		f.prq = make(chan cipher.SHA256, f.maxParallel())
+		f.f.Prioritize(*fp, f.prq, func(i int) {
+			f.node().onPriorityFilled(cr.r, i)
+		})
+	}
+
 	f.rqo = list.New()                   // create list of keys
 	f.fc = f.cs.buildConnsList(cr.r.Seq) // create list of connections
 
@@ -381,7 +573,16 @@ func (f *fillHead) closeFiller() {
 
 	f.f.Close()
 
-	f.rqo, f.fc, f.rq = nil, nil, nil
+	f.rqo, f.pl, f.fc, f.rq, f.prq = nil, nil, nil, nil, nil
+
+	// responses of running requests will be ignored
+	for rq := range f.inflight {
+		if rq.hedge != nil {
+			rq.hedge.Stop()
+		}
+	}
+
+	f.inflight = make(map[*fillRequest]struct{})
 
 	f.r = connRoot{}
 	f.requesting = 0
@@ -392,12 +593,19 @@ func (f *fillHead) handleFillingResult(err error) {
 
 	f.node().Debugf(FillPin, "handleFillingResult %s: %v", f.r.r.Short(), err)
 
+	f.node().addFillingStat(f.f.Progress())
+
 	if err == nil {
 		f.node().onRootFilled(f.r.r)     // callback
 		f.favg.Add(time.Now().Sub(f.tp)) // average time
 		f.cs.moveForward(f.r.r.Seq + 1)  // move forward
 	} else {
 		f.node().onFillingBreaks(f.r.r, err) // callback
+
+		var ee, ok = err.(*skyobject.EquivocationError)
+		if ok == true && ee.New == true {
+			f.node().onEquivocation(f.r.c, ee.Equivocation) // callback
+		}
 	}
 
 	f.closeFiller() // close the filler and wait it's goroutines
@@ -435,54 +643,170 @@ func (f *fillHead) tryRequest() (fatal bool) {
 		return // no objects to request
 	}
 
-	if f.fc.Len() == 0 {
+	var c = f.bestConn(nil)
+
+	if c == nil {
 		fatal = (f.requesting == 0)
 		return // no connections to request from
 	}
 
-	var c = f.fc.Remove(f.fc.Front()).(*Conn) // unshift
+	// do the request
+
+	var rq = &fillRequest{seq: f.r.r.Seq, c: c}
+
+	if c.Supports(msg.FeatureBatch) == false || f.rqo.Len() == 1 {
+		rq.keys = []cipher.SHA256{f.shift()}
+	} else {
 
-	// the c can be removed from the head, let's check it out
+		// batch
 
-	for _, ok := f.cs[c]; ok == false; _, ok = f.cs[c] {
+		rq.keys = make([]cipher.SHA256, 0, minInt(f.rqo.Len(), msg.MaxKeys))
 
-		if f.fc.Len() == 0 {
-			fatal = (f.requesting == 0)
-			return // no connections
+		for f.rqo.Len() > 0 && len(rq.keys) < msg.MaxKeys {
+			rq.keys = append(rq.keys, f.shift())
 		}
 
-		c = f.fc.Remove(f.fc.Front()).(*Conn) // unshift next
+	}
+
+	f.inflight[rq] = struct{}{}
+	f.send(c, rq)
 
+	if delay := f.hedgeDelay(c); delay > 0 {
+		rq.hedge = time.AfterFunc(delay, func() {
+			select {
+			case f.hedgeq <- rq:
+			case <-f.closeq:
+			}
+		})
 	}
 
-	var key = f.rqo.Remove(f.rqo.Front()).(cipher.SHA256) // unshift
+	return
+}
 
-	// do the request
+// send given request to given peer
+func (f *fillHead) send(c *Conn, rq *fillRequest) {
 
 	f.requesting++
+	rq.running++
 
 	f.await.Add(1) // nodeHead.await
-	go f.request(c, f.r.r.Seq, key)
+
+	if len(rq.keys) == 1 {
+		go f.request(c, rq)
+	} else {
+		go f.requestBatch(c, rq)
+	}
+
+}
+
+// time to wait a response before sending the
+// same request to another peer, or zero
+func (f *fillHead) hedgeDelay(c *Conn) (delay time.Duration) {
+
+	if delay = f.node().config.HedgeDelay; delay <= 0 {
+		return // disabled
+	}
+
+	if latency := 2 * c.sc.avgLatency(); latency > delay {
+		delay = latency
+	}
 
 	return
 }
 
+// remove and return idle connection with best score,
+// that passes given filter (if not nil), or nil
+func (f *fillHead) bestConn(filter func(c *Conn) bool) (best *Conn) {
+
+	var (
+		bestElem  *list.Element
+		bestScore time.Duration
+		next      *list.Element
+	)
+
+	for e := f.fc.Front(); e != nil; e = next {
+
+		next = e.Next()
+
+		var c = e.Value.(*Conn)
+
+		// the c can be removed from the head
+		if _, ok := f.cs[c]; ok == false {
+			f.fc.Remove(e)
+			continue
+		}
+
+		if filter != nil && filter(c) == false {
+			continue
+		}
+
+		if score := c.sc.value(); bestElem == nil || score < bestScore {
+			bestElem, bestScore = e, score
+		}
+
+	}
+
+	if bestElem != nil {
+		best = f.fc.Remove(bestElem).(*Conn)
+	}
+
+	return
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 // code readability
 func (f *fillHead) node() *Node {
 	return f.n.fs.n
 }
 
+// (async) report failed request and
+// update score of the peer
+func (f *fillHead) failed(c *Conn, rq *fillRequest, err error) {
+
+	switch err {
+	case ErrClosed, skyobject.ErrTerminated:
+	default:
+		c.sc.fail() // timeout, invalid response, etc
+	}
+
+	f.failureq <- failedRequest{c, rq, err}
+}
+
+// (async) report succeeded request and
+// update score of the peer
+func (f *fillHead) succeeded(
+	c *Conn, //                 :
+	rq *fillRequest, //         :
+	tp time.Time, //            : request start time
+	missing []cipher.SHA256, // : not received
+) {
+
+	c.sc.success(time.Now().Sub(tp))
+	f.successq <- succeededRequest{c, rq, missing}
+}
+
 // (async) request object
-func (f *fillHead) request(c *Conn, seq uint64, key cipher.SHA256) {
+func (f *fillHead) request(c *Conn, rq *fillRequest) {
 	defer f.await.Done()
 
-	f.node().Debugf(FillPin, "[fill] request from [%s] %d %s", c.String(), seq,
-		key.Hex()[:7])
+	var key = rq.keys[0]
 
-	var reply, err = c.sendRequest(&msg.RqObject{Key: key})
+	f.node().Debugf(FillPin, "[fill] request from [%s] %d %s", c.String(),
+		rq.seq, key.Hex()[:7])
+
+	var (
+		tp         = time.Now()
+		reply, err = c.sendRequest(&msg.RqObject{Key: key})
+	)
 
 	if err != nil {
-		f.failureq <- failedRequest{c, seq, key, err}
+		f.failed(c, rq, err)
 		return
 	}
 
@@ -491,7 +815,7 @@ func (f *fillHead) request(c *Conn, seq uint64, key cipher.SHA256) {
 		var rk = cipher.SumSHA256(x.Value)
 
 		if rk != key {
-			f.failureq <- failedRequest{c, seq, key, ErrInvalidResponse}
+			f.failed(c, rq, ErrInvalidResponse)
 			return
 		}
 
@@ -501,10 +825,86 @@ func (f *fillHead) request(c *Conn, seq uint64, key cipher.SHA256) {
 			return
 		}
 
-		f.successq <- c
+		f.succeeded(c, rq, tp, nil)
 
 	default:
-		f.failureq <- failedRequest{c, seq, key, ErrInvalidResponse}
+		f.failed(c, rq, ErrInvalidResponse)
+	}
+
+}
+
+// (async) request many objects
+func (f *fillHead) requestBatch(c *Conn, rq *fillRequest) {
+	defer f.await.Done()
+
+	var keys = rq.keys
+
+	f.node().Debugf(FillPin, "[fill] request batch from [%s] %d %d",
+		c.String(), rq.seq, len(keys))
+
+	var (
+		tp         = time.Now()
+		reply, err = c.sendRequest(&msg.RqObjects{Keys: keys})
+	)
+
+	if err != nil {
+		f.failed(c, rq, err)
+		return
+	}
+
+	switch x := reply.(type) {
+	case *msg.Objects:
+
+		if len(x.Values) == 0 || len(x.Values) > len(keys) {
+			f.failed(c, rq, ErrInvalidResponse)
+			return
+		}
+
+		var requested = make(map[cipher.SHA256]bool, len(keys))
+
+		for _, key := range keys {
+			requested[key] = false
+		}
+
+		for _, val := range x.Values {
+
+			var (
+				key     = cipher.SumSHA256(val)
+				got, ok = requested[key]
+			)
+
+			if ok == false || got == true {
+				f.failed(c, rq, ErrInvalidResponse)
+				return // not requested or duplicate
+			}
+
+			requested[key] = true
+
+			// incremented by the Want call(s)
+			if _, err := f.node().c.SetWanted(key, val); err != nil {
+				f.node().Fatal("DB failure:", err)
+				return
+			}
+
+		}
+
+		var missing []cipher.SHA256
+
+		for _, key := range keys {
+			if requested[key] == false {
+				missing = append(missing, key)
+			}
+		}
+
+		f.succeeded(c, rq, tp, missing)
+
+	case *msg.Err:
+
+		// the peer doesn't have the objects
+		f.failed(c, rq, ErrTimeout)
+
+	default:
+		f.failed(c, rq, ErrInvalidResponse)
 	}
 
 }
@@ -584,22 +984,16 @@ func (k knownRoots) removeKnown(c *Conn, seq uint64) {
 // Root objects of all peers
 func (k knownRoots) moveForward(seq uint64) {
 
-	var (
-		ks uint64
-		i  int
-	)
-
 	for c, known := range k {
 
-		for i, ks = range known {
-
-			if ks >= seq {
-				k[c] = append(known[:i], known[i+1:]...)
-				break
-			}
+		var i int
 
+		for i < len(known) && known[i] < seq {
+			i++ // old one
 		}
 
+		k[c] = known[i:]
+
 	}
 
 }
@@ -633,10 +1027,16 @@ type headInfo struct {
 	pendingRoot    bool   // has pending Root
 	pendingRootSeq uint64 // its seq
 
-	// TODO (kostyarin): connections used for current filling
+	requesting int // number of requested peers
 
 	// known Root objects of peers
 	known map[*Conn][]uint64
+
+	// scores of the peers
+	scores map[*Conn]PeerScore
+
+	// progress of the filling Root or nil
+	progress *FillingProgress
 }
 
 // (api) request, can return nil
@@ -670,17 +1070,22 @@ func (n *nodeHead) handleInfo(f *fillHead) {
 	ni.pendingRoot = (f.p.r != nil)
 
 	if ni.pendingRoot == true {
-		ni.pendingRootSeq = f.r.r.Seq
+		ni.pendingRootSeq = f.p.r.Seq
 	}
 
+	ni.requesting = f.requesting
+	ni.progress = f.progress()
+
 	// make copy
 
 	ni.known = make(map[*Conn][]uint64)
+	ni.scores = make(map[*Conn]PeerScore)
 
 	for c, known := range f.cs {
 		var kc = make([]uint64, len(known))
 		copy(kc, known)
 		ni.known[c] = kc
+		ni.scores[c] = c.Score()
 	}
 
 	// the nonce field should be set by caller (by requester)
@@ -0,0 +1,90 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func Test_nodeHead_info(t *testing.T) {
+
+	var n = getTestNodeNotListen("test")
+	defer n.Close()
+
+	var (
+		nh  = newNodeHead(newNodeFeed(n.fs, cipher.PubKey{}))
+		hiq = make(chan *headInfo, 1)
+	)
+
+	defer nh.close()
+
+	go func() { hiq <- nh.info() }()
+
+	select {
+	case hi := <-hiq:
+		if hi == nil {
+			t.Error("nil info")
+		}
+	case <-time.After(TM):
+		t.Error("slow or blocked")
+	}
+
+}
+
+func Test_nodeHead_handleInfo(t *testing.T) {
+
+	var (
+		nh = &nodeHead{inforn: make(chan *headInfo, 1)}
+		f  = &fillHead{nodeHead: nh, cs: make(knownRoots)}
+	)
+
+	f.f = new(skyobject.Filler) // for progress
+
+	f.r = connRoot{r: &registry.Root{Seq: 1}}
+	f.p = connRoot{r: &registry.Root{Seq: 2}}
+
+	nh.handleInfo(f)
+
+	var hi = <-nh.inforn
+
+	if hi.fillingRoot == false || hi.fillingRootSeq != 1 {
+		t.Error("wrong filling Root:", hi.fillingRoot, hi.fillingRootSeq)
+	}
+
+	if hi.pendingRoot == false || hi.pendingRootSeq != 2 {
+		t.Error("wrong pending Root:", hi.pendingRoot, hi.pendingRootSeq)
+	}
+
+}
+
+func Test_knownRoots_moveForward(t *testing.T) {
+
+	var (
+		k      = make(knownRoots)
+		c1, c2 = new(Conn), new(Conn)
+	)
+
+	k.addKnown(c1, 0)
+	k.addKnown(c1, 1)
+	k.addKnown(c1, 3)
+	k.addKnown(c2, 0)
+
+	k.moveForward(1) // Root with seq 0 filled
+
+	if l := k.buildConnsList(1); l.Len() != 1 || l.Front().Value != c1 {
+		t.Error("newer known Root removed")
+	}
+
+	if k.buildConnsList(0).Len() != 0 {
+		t.Error("filled Root is not removed")
+	}
+
+	if len(k[c2]) != 0 {
+		t.Error("old known Root is not removed:", k[c2])
+	}
+
+}
@@ -0,0 +1,264 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// RequestHistory requests Root objects of given head with
+// seq numbers in range [fromSeq, toSeq] from the remote peer.
+// The remote peer should share the feed and should support
+// history requests (see msg.FeatureHistory). The Node should
+// share the feed too. Root objects the remote peer doesn't
+// have are skipped. Every received Root is verified, and
+// Root objects this Node doesn't have are saved as
+// historical (see History of the Index). The Index keeps
+// only full Root objects (a Root of the Index can be used
+// by Pack and sent to other peers), thus historical Root
+// objects are kept apart. If the FillHistory option of the
+// Config is true, then the historical Root objects are
+// filled using the Conn and moved to the Index. Root objects
+// returned ordered by seq, and only filled Root objects are
+// full (see IsFull field of the Root)
+func (c *Conn) RequestHistory(
+	feed cipher.PubKey, //    : feed
+	nonce uint64, //          : head
+	fromSeq uint64, //        : first seq
+	toSeq uint64, //          : last seq
+) (
+	rs []*registry.Root, //   : received Root objects
+	err error, //             : an error
+) {
+
+	if c.Supports(msg.FeatureHistory) == false {
+		return nil, ErrNotSupported
+	}
+
+	if fromSeq > toSeq {
+		return nil, fmt.Errorf("invalid range [%d, %d]", fromSeq, toSeq)
+	}
+
+	for from := fromSeq; ; {
+
+		var part []*registry.Root
+		if part, err = c.requestRoots(feed, nonce, from, toSeq); err != nil {
+			return
+		}
+
+		rs = append(rs, part...)
+
+		if len(part) < msg.MaxRoots {
+			break // the end
+		}
+
+		var last = part[len(part)-1].Seq
+
+		if last >= toSeq {
+			break // the end (and toSeq can be math.MaxUint64)
+		}
+
+		from = last + 1
+	}
+
+	for _, r := range rs {
+
+		if r.IsFull == true {
+			continue // already have
+		}
+
+		if err = c.n.c.AddHistory(r); err != nil {
+			return
+		}
+
+		if c.n.config.FillHistory == false {
+			continue
+		}
+
+		// the Filler moves the Root to the Index
+		if err = c.fillHistory(r); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+// request and check one part of history
+func (c *Conn) requestRoots(
+	feed cipher.PubKey, //  :
+	nonce uint64, //        :
+	from uint64, //         :
+	to uint64, //           :
+) (
+	rs []*registry.Root, // :
+	err error, //           :
+) {
+
+	var reply msg.Msg
+
+	reply, err = c.sendRequest(&msg.RqRoots{
+		Feed:  feed,
+		Nonce: nonce,
+		From:  from,
+		To:    to,
+	})
+
+	if err != nil {
+		return
+	}
+
+	switch x := reply.(type) {
+
+	case *msg.Roots:
+
+		if x.Feed != feed || x.Nonce != nonce || len(x.Roots) > msg.MaxRoots {
+			return nil, ErrInvalidResponse
+		}
+
+		rs = make([]*registry.Root, 0, len(x.Roots))
+
+		for _, root := range x.Roots {
+
+			if root.Feed != feed || root.Nonce != nonce ||
+				root.Seq < from || root.Seq > to {
+
+				return nil, ErrInvalidResponse
+			}
+
+			var r *registry.Root
+			if r, err = c.receivedRoot(feed, root.Sig, root.Value); err != nil {
+				return nil, err
+			}
+
+			if r.Pub != feed || r.Nonce != nonce || r.Seq != root.Seq {
+				return nil, ErrInvalidResponse
+			}
+
+			rs = append(rs, r)
+			from = r.Seq + 1 // the Roots must be ordered by seq
+		}
+
+	case *msg.Err:
+
+		err = errors.New(x.Err)
+
+	default:
+
+		err = fmt.Errorf("invalid response type %T", reply)
+
+	}
+
+	return
+}
+
+// fill given historical Root using the Conn,
+// the Filler saves the Root if it's filled
+func (c *Conn) fillHistory(r *registry.Root) (err error) {
+
+	c.n.Debugln(FillPin, "[fill] fillHistory", c.String(), r.Short())
+
+	var mp = c.n.maxFillingParallel
+
+	if mp <= 0 {
+		mp = 1024 // see (*fillHead).maxParallel
+	}
+
+	var (
+		rq = make(chan cipher.SHA256, mp)
+		f  = c.n.c.Fill(r, rq, mp)
+
+		gc = c.getter()
+		lm = make(chan struct{}, mp) // limit of requests
+
+		wg sync.WaitGroup
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for key := range rq {
+
+			lm <- struct{}{}
+
+			wg.Add(1)
+			go func(key cipher.SHA256) {
+				defer wg.Done()
+				defer func() { <-lm }()
+
+				var val, err = gc.Get(key)
+
+				if err != nil {
+					f.Fail(err)
+					return
+				}
+
+				// incremented by the Filler
+				if _, err = c.n.c.SetWanted(key, val); err != nil {
+					c.n.Fatal("DB failure:", err)
+				}
+			}(key)
+
+		}
+
+	}()
+
+	err = f.Run()
+
+	close(rq)
+	wg.Wait()
+
+	return
+}
+
+func (c *Conn) handleRqRoots(seq uint32, rq *msg.RqRoots) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqRoots %s/%d [%d, %d]",
+		c.String(),
+		rq.Feed.Hex()[:7],
+		rq.Nonce,
+		rq.From,
+		rq.To)
+
+	// don't share Root objects of feeds the Node doesn't share
+	if c.n.fs.hasFeed(rq.Feed) == false {
+		c.sendErr(seq, data.ErrNoSuchFeed)
+		return
+	}
+
+	var rs, err = c.n.c.Roots(rq.Feed, rq.Nonce, rq.From, rq.To, msg.MaxRoots)
+
+	if err != nil {
+		c.sendErr(seq, err)
+		return
+	}
+
+	var reply = &msg.Roots{
+		Feed:  rq.Feed,
+		Nonce: rq.Nonce,
+		Roots: make([]msg.Root, 0, len(rs)),
+	}
+
+	for _, r := range rs {
+		reply.Roots = append(reply.Roots, msg.Root{
+			Feed:  r.Pub,
+			Nonce: r.Nonce,
+			Seq:   r.Seq,
+
+			Value: r.Encode(),
+
+			Sig: r.Sig,
+		})
+	}
+
+	c.sendMsg(c.nextSeq(), seq, reply)
+	return
+}
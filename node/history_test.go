@@ -0,0 +1,145 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestConn_RequestHistory(t *testing.T) {
+
+	var tp = newTestPair(t, func(_, bc *Config) {
+		bc.FillHistory = true
+	})
+	defer tp.Close()
+
+	var (
+		pk   = tp.pk
+		feed Feed
+		r    = &registry.Root{Nonce: 1, Pub: pk}
+	)
+
+	for i := 0; i < 3; i++ {
+		tp.appendPosts(t, &feed, 1)
+		tp.save(t, r, feed)
+	}
+
+	var ba, err = tp.b.TCP().Connect(tp.a.TCP().Address())
+	assertNil(t, err)
+
+	// the last one first
+
+	var rs []*registry.Root
+	rs, err = ba.RequestHistory(pk, 1, 2, 10)
+	assertNil(t, err)
+
+	if len(rs) != 1 || rs[0].Seq != 2 || rs[0].IsFull == false {
+		t.Fatal("wrong history received:", len(rs))
+	}
+
+	// the rest
+
+	rs, err = ba.RequestHistory(pk, 1, 0, 2)
+	assertNil(t, err)
+
+	if len(rs) != 3 {
+		t.Fatal("wrong number of Root objects:", len(rs))
+	}
+
+	for i, r := range rs {
+
+		if r.Seq != uint64(i) {
+			t.Error("wrong order:", i, r.Seq)
+		}
+
+		if r.IsFull == false {
+			t.Error("not full:", r.Seq)
+		}
+
+		if _, err = tp.b.Container().Root(pk, 1, r.Seq); err != nil {
+			t.Error("not saved:", r.Seq, err)
+		}
+
+		if tp.b.Container().IsHistorical(pk, 1, r.Seq) == true {
+			t.Error("filled Root is historical:", r.Seq)
+		}
+
+	}
+
+	// older Root objects don't replace the last one
+
+	var seq uint64
+	if seq, err = tp.b.Container().LastRootSeq(pk, 1); err != nil {
+		t.Fatal(err)
+	} else if seq != 2 {
+		t.Error("wrong last Root:", seq)
+	}
+
+	// blank head
+
+	rs, err = ba.RequestHistory(pk, 2, 0, 2)
+
+	if err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestConn_RequestHistoryNoFill(t *testing.T) {
+
+	var tp = newTestPair(t, nil)
+	defer tp.Close()
+
+	var (
+		feed Feed
+		r    = &registry.Root{Nonce: 1, Pub: tp.pk}
+	)
+
+	for i := 0; i < 2; i++ {
+		tp.appendPosts(t, &feed, 1)
+		tp.save(t, r, feed)
+	}
+
+	var ba, err = tp.b.TCP().Connect(tp.a.TCP().Address())
+	assertNil(t, err)
+
+	var rs []*registry.Root
+	rs, err = ba.RequestHistory(tp.pk, 1, 0, 1)
+	assertNil(t, err)
+
+	if len(rs) != 2 {
+		t.Fatal("wrong number of Root objects:", len(rs))
+	}
+
+	for _, r := range rs {
+
+		if r.IsFull == true {
+			t.Error("full Root:", r.Seq)
+		}
+
+		if _, err = tp.b.Container().Root(tp.pk, 1, r.Seq); err == nil {
+			t.Error("not full Root saved:", r.Seq)
+		}
+
+		if tp.b.Container().IsHistorical(tp.pk, 1, r.Seq) == false {
+			t.Error("historical Root not saved:", r.Seq)
+		}
+
+	}
+
+	var hs []*registry.Root
+	if hs, err = tp.b.Container().History(tp.pk, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hs) != 2 {
+		t.Fatal("wrong number of historical Root objects:", len(hs))
+	}
+
+	for i, h := range hs {
+		if h.Hash != rs[i].Hash || h.Sig != rs[i].Sig {
+			t.Error("wrong historical Root:", i, h.Short())
+		}
+	}
+
+}
@@ -0,0 +1,86 @@
+package node
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// keyFilePath returns path to the key file; a relative
+// path is relative to DataDir of the Container
+func (c *Config) keyFilePath() (path string) {
+
+	path = c.KeyFile
+
+	if path == "" || filepath.IsAbs(path) == true {
+		return
+	}
+
+	if c.Config != nil && c.Config.DataDir != "" {
+		path = filepath.Join(c.Config.DataDir, path)
+	}
+
+	return
+}
+
+// loadIdentity loads keys of NodeID from given file,
+// the file contains hex-encoded secret key; if the file
+// doesn't exist, then it will be created with new random
+// keys
+func loadIdentity(path string) (pk cipher.PubKey, sk cipher.SecKey, err error) {
+
+	var b []byte
+
+	if b, err = ioutil.ReadFile(path); err != nil {
+
+		if os.IsNotExist(err) == false {
+			return
+		}
+
+		return createIdentity(path)
+
+	}
+
+	if sk, err = cipher.SecKeyFromHex(strings.TrimSpace(string(b))); err != nil {
+		err = fmt.Errorf("invalid key file %q: %v", path, err)
+		return
+	}
+
+	if err = sk.Verify(); err != nil {
+		err = fmt.Errorf("invalid key file %q: %v", path, err)
+		return
+	}
+
+	pk = cipher.PubKeyFromSecKey(sk)
+	return
+}
+
+// create key file with new random keys
+func createIdentity(path string) (pk cipher.PubKey, sk cipher.SecKey, err error) {
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err = os.MkdirAll(dir, 0700); err != nil {
+			return
+		}
+	}
+
+	pk, sk = cipher.GenerateKeyPair()
+
+	var fl *os.File
+	fl, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return
+	}
+
+	if _, err = fmt.Fprintln(fl, sk.Hex()); err != nil {
+		fl.Close()
+		return
+	}
+
+	err = fl.Close()
+	return
+}
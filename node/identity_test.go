@@ -0,0 +1,60 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_loadIdentity(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var conf = getTestConfigNotListen("test")
+	conf.DataDir = dir
+	conf.KeyFile = "node.key"
+
+	var path = conf.keyFilePath()
+
+	if path != filepath.Join(dir, "node.key") {
+		t.Fatal("wrong key file path:", path)
+	}
+
+	var n1, n2 *Node
+
+	n1, err = NewNode(conf)
+	assertNil(t, err)
+	n1.Close()
+
+	var fi os.FileInfo
+	fi, err = os.Stat(path)
+	assertNil(t, err)
+
+	if fi.Mode().Perm() != 0600 {
+		t.Error("wrong permissions of the key file:", fi.Mode().Perm())
+	}
+
+	conf = getTestConfigNotListen("test")
+	conf.DataDir = dir
+	conf.KeyFile = "node.key"
+
+	n2, err = NewNode(conf)
+	assertNil(t, err)
+	defer n2.Close()
+
+	if n1.ID() != n2.ID() {
+		t.Error("node id is not persistent")
+	}
+
+	// invalid key file
+
+	assertNil(t, ioutil.WriteFile(path, []byte("invalid"), 0600))
+
+	if _, _, err = loadIdentity(path); err == nil {
+		t.Error("missing error")
+	}
+
+}
@@ -0,0 +1,362 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// max feeds of an announcement, to fit UDP datagram
+const localMaxFeeds int = 1024
+
+// prefix and version of an announcement
+var localMagic = [4]byte{'c', 'x', 'o', 1}
+
+// an announcement of a node in local network
+type localAnnounce struct {
+	Magic   [4]byte         // prefix and version
+	NodeID  cipher.PubKey   // node id
+	Address string          // listening address
+	Feeds   []cipher.PubKey // shared feeds
+}
+
+// a localDiscovery represents discovery of peers
+// of a network (TCP or UDP) in local network using
+// UDP multicast; a Node announces its NodeID,
+// listening address and shared feeds, and connects
+// to announced peers that share the same feeds
+type localDiscovery struct {
+	n       *Node
+	network string // "tcp" or "udp"
+
+	group    *net.UDPAddr
+	interval time.Duration
+
+	ln  *net.UDPConn // receive announcements
+	out *net.UDPConn // send announcements
+
+	mx      sync.Mutex
+	dialing map[cipher.PubKey]struct{} // peers handled in background
+
+	closeo sync.Once
+	closeq chan struct{}
+	await  sync.WaitGroup
+}
+
+// start local discovery of given network
+func (n *Node) startLocalDiscovery(
+	network string, //  : "tcp" or "udp"
+	conf *NetConfig, // : configurations of the network
+) (
+	err error, //       :
+) {
+
+	var l = &localDiscovery{
+		n:        n,
+		network:  network,
+		interval: conf.LocalInterval,
+		dialing:  make(map[cipher.PubKey]struct{}),
+		closeq:   make(chan struct{}),
+	}
+
+	if l.interval <= 0 {
+		l.interval = LocalInterval
+	}
+
+	if l.group, err = net.ResolveUDPAddr("udp", conf.LocalDiscovery); err != nil {
+		return
+	}
+
+	if l.group.IP.IsMulticast() == false {
+		return fmt.Errorf("not a multicast address: %s", conf.LocalDiscovery)
+	}
+
+	if l.ln, err = net.ListenMulticastUDP("udp", nil, l.group); err != nil {
+		return
+	}
+
+	if l.out, err = net.DialUDP("udp", nil, l.group); err != nil {
+		l.ln.Close()
+		return
+	}
+
+	n.mx.Lock()
+	n.local = append(n.local, l)
+	n.mx.Unlock()
+
+	l.await.Add(2)
+	go l.receiving()
+	go l.announcing()
+
+	return
+}
+
+// close the localDiscovery and wait its goroutines
+func (l *localDiscovery) close() {
+	l.closeo.Do(func() {
+		close(l.closeq)
+		l.ln.Close()
+		l.out.Close()
+		l.await.Wait()
+	})
+}
+
+// listening address of the network or blank string
+func (l *localDiscovery) address() (address string) {
+
+	l.n.mx.Lock()
+	var tcp, udp = l.n.tcp, l.n.udp
+	l.n.mx.Unlock()
+
+	if l.network == "tcp" {
+		if tcp != nil {
+			address = tcp.Address()
+		}
+	} else if udp != nil {
+		address = udp.Address()
+	}
+
+	return
+}
+
+func (l *localDiscovery) announcing() {
+
+	defer l.await.Done()
+
+	var tk = time.NewTicker(l.interval)
+	defer tk.Stop()
+
+	for {
+
+		l.announce()
+
+		select {
+		case <-tk.C:
+		case <-l.closeq:
+			return
+		}
+
+	}
+
+}
+
+// send announcement, if the network is listening
+func (l *localDiscovery) announce() {
+
+	var address = l.address()
+
+	if address == "" {
+		return // not listening, nothing to announce
+	}
+
+	var la = localAnnounce{
+		Magic:   localMagic,
+		NodeID:  l.n.ID(),
+		Address: address,
+		Feeds:   l.n.Feeds(),
+	}
+
+	if len(la.Feeds) > localMaxFeeds {
+		la.Feeds = la.Feeds[:localMaxFeeds]
+	}
+
+	if _, err := l.out.Write(encoder.Serialize(&la)); err != nil {
+		l.n.Debugf(DiscoveryPin, "(%s) can't send local announcement: %v",
+			l.network,
+			err)
+	}
+
+}
+
+func (l *localDiscovery) receiving() {
+
+	defer l.await.Done()
+
+	var buf = make([]byte, 64*1024)
+
+	for {
+
+		var n, from, err = l.ln.ReadFromUDP(buf)
+
+		if err != nil {
+
+			select {
+			case <-l.closeq:
+				return // closed
+			default:
+			}
+
+			l.n.Debugf(DiscoveryPin, "(%s) local discovery error: %v",
+				l.network,
+				err)
+			continue
+		}
+
+		var la localAnnounce
+
+		if err = encoder.DeserializeRaw(buf[:n], &la); err != nil {
+			continue // not an announcement
+		}
+
+		if la.Magic != localMagic || la.NodeID == l.n.ID() {
+			continue // unknown version or own announcement
+		}
+
+		l.handleAnnounce(&la, from)
+
+	}
+
+}
+
+// connect to announced peer and subscribe to common feeds
+func (l *localDiscovery) handleAnnounce(la *localAnnounce, from *net.UDPAddr) {
+
+	var address, ok = announcedAddress(la.Address, from)
+
+	if ok == false {
+		return
+	}
+
+	l.n.Debugf(DiscoveryPin, "(%s) local peer %s %s",
+		l.network,
+		la.NodeID.Hex()[:7],
+		address)
+
+	var feeds = make(map[cipher.PubKey]struct{})
+
+	for _, feed := range l.n.Feeds() {
+		feeds[feed] = struct{}{}
+	}
+
+	var common []cipher.PubKey
+
+	for _, feed := range la.Feeds {
+		if _, ok = feeds[feed]; ok == true {
+			common = append(common, feed)
+		}
+	}
+
+	if len(common) == 0 {
+		return // nothing to share
+	}
+
+	var c *Conn
+
+	if c, ok = l.n.hasPeer(la.NodeID); ok == false {
+
+		// if both peers announce, then peer with
+		// lesser NodeID connects to avoid two
+		// connections at the same time
+		if l.address() != "" {
+			var id = l.n.ID()
+			if bytes.Compare(id[:], la.NodeID[:]) > 0 {
+				return // the peer connects
+			}
+		}
+
+	} else if c.incoming == true {
+		return // the peer subscribes
+	}
+
+	// dial and subscribe in background, since
+	// the Close of the Node waits for the
+	// receiving goroutine
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if _, ok = l.dialing[la.NodeID]; ok == true {
+		return // already
+	}
+
+	var id = la.NodeID
+
+	ok = l.n.spawn(&l.await, func() {
+		l.connect(c, id, address, common)
+
+		l.mx.Lock()
+		delete(l.dialing, id)
+		l.mx.Unlock()
+	})
+
+	if ok == true {
+		l.dialing[id] = struct{}{}
+	}
+
+}
+
+// connect to local peer if given Conn is nil
+// and subscribe to given feeds
+func (l *localDiscovery) connect(
+	c *Conn, //                 : connection to the peer or nil
+	id cipher.PubKey, //        : id of the peer
+	address string, //          : address of the peer
+	common []cipher.PubKey, //  : feeds to subscribe to
+) {
+
+	if c == nil {
+
+		var err error
+		if c, err = l.n.dial(l.network, address); err != nil {
+			l.n.Debugf(DiscoveryPin, "(%s) can't connect to local peer %s: %v",
+				l.network,
+				address,
+				err)
+			return
+		}
+
+		if c.PeerID() != id {
+			l.n.Debugf(DiscoveryPin, "(%s) local peer %s is not %s",
+				l.network,
+				address,
+				id.Hex()[:7])
+			return
+		}
+
+	}
+
+	for _, feed := range common {
+
+		if l.n.fs.hasConnFeed(c, feed) == true {
+			continue // already subscribed
+		}
+
+		if err := c.Subscribe(feed); err != nil {
+			l.n.Debugf(DiscoveryPin, "[%s] can't Subscribe to %s: %v",
+				c.String(),
+				feed.Hex()[:7],
+				err)
+		}
+
+	}
+
+}
+
+// announcedAddress returns address to connect to;
+// blank or unspecified host of the announced
+// address replaced with address of the sender,
+// and other host should be the address of the
+// sender, since a peer can announce only its
+// own address
+func announcedAddress(address string, from *net.UDPAddr) (
+	hostPort string, ok bool) {
+
+	var host, port, err = net.SplitHostPort(address)
+
+	if err != nil {
+		return
+	}
+
+	if ip := net.ParseIP(host); host == "" || ip.IsUnspecified() == true {
+		host = from.IP.String()
+	} else if ip.Equal(from.IP) == false {
+		return // not the sender
+	}
+
+	return net.JoinHostPort(host, port), true
+}
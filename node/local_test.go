@@ -0,0 +1,140 @@
+package node
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+const testLocalDiscovery = "239.255.42.99:18872"
+
+func getTestLocalNode(t *testing.T, prefix, listen string) (n *Node) {
+
+	var conf = getTestConfigNotListen(prefix)
+
+	// listen all interfaces, since a peer connects
+	// to address the announcement received from
+	if listen != "" {
+		var _, port, err = net.SplitHostPort(listen)
+		assertNil(t, err)
+		listen = net.JoinHostPort("", port)
+	}
+
+	conf.TCP.Listen = listen
+	conf.TCP.LocalDiscovery = testLocalDiscovery
+	conf.TCP.LocalInterval = 50 * time.Millisecond
+
+	var err error
+	if n, err = NewNode(conf); err != nil {
+		t.Skip("local discovery is not available:", err)
+	}
+
+	return
+}
+
+// skip the test if multicast datagrams don't
+// loop back, e.g. in a sandbox without multicast
+// routes, where sending doesn't fail
+func probeMulticast(t *testing.T) {
+	t.Helper()
+
+	var group, err = net.ResolveUDPAddr("udp", testLocalDiscovery)
+	assertNil(t, err)
+
+	var l *net.UDPConn
+	if l, err = net.ListenMulticastUDP("udp", nil, group); err != nil {
+		t.Skip("local discovery is not available:", err)
+	}
+	defer l.Close()
+
+	var c *net.UDPConn
+	if c, err = net.DialUDP("udp", nil, group); err != nil {
+		t.Skip("local discovery is not available:", err)
+	}
+	defer c.Close()
+
+	var buf = make([]byte, 16)
+
+	for i := 0; i < 5; i++ {
+
+		c.Write([]byte("probe"))
+
+		l.SetReadDeadline(time.Now().Add(TM / 5))
+
+		if n, _, err := l.ReadFromUDP(buf); err == nil &&
+			string(buf[:n]) == "probe" {
+			return
+		}
+
+	}
+
+	t.Skip("local discovery is not available: multicast doesn't loop back")
+}
+
+func TestNode_localDiscovery(t *testing.T) {
+
+	probeMulticast(t)
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+
+		na = getTestLocalNode(t, "a", getFreeAddress(t))
+		nb = getTestLocalNode(t, "b", getFreeAddress(t))
+		nc = getTestLocalNode(t, "c", "") // not listening
+	)
+
+	defer na.Close()
+	defer nb.Close()
+	defer nc.Close()
+
+	assertNil(t, na.Share(pk))
+	assertNil(t, nb.Share(pk))
+	assertNil(t, nc.Share(pk))
+
+	waitFor(t, func() bool {
+		return len(na.ConnectionsOfFeed(pk)) == 2 &&
+			len(nb.ConnectionsOfFeed(pk)) == 2 &&
+			len(nc.ConnectionsOfFeed(pk)) == 2
+	})
+
+	// only one connection between peers
+	if len(na.Connections()) != 2 || len(nb.Connections()) != 2 {
+		t.Error("wrong number of connections")
+	}
+
+}
+
+func Test_announcedAddress(t *testing.T) {
+
+	var from = &net.UDPAddr{IP: net.ParseIP("192.168.0.2"), Port: 1000}
+
+	for _, tt := range []struct {
+		address, want string
+	}{
+		{":8870", "192.168.0.2:8870"},
+		{"0.0.0.0:8870", "192.168.0.2:8870"},
+		{"[::]:8870", "192.168.0.2:8870"},
+		{"192.168.0.2:8870", "192.168.0.2:8870"},
+	} {
+		if got, ok := announcedAddress(tt.address, from); ok == false {
+			t.Error("can't get address of", tt.address)
+		} else if got != tt.want {
+			t.Errorf("wrong address of %s: %s, want %s", tt.address, got,
+				tt.want)
+		}
+	}
+
+	for _, address := range []string{
+		"invalid",
+		"127.0.0.1:8870",   // not the sender
+		"192.168.0.3:8870", // not the sender
+		"example.com:8870", // not an IP address
+	} {
+		if _, ok := announcedAddress(address, from); ok == true {
+			t.Error("missing error:", address)
+		}
+	}
+
+}
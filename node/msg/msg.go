@@ -16,8 +16,91 @@ import (
 // [ .... ] - encoded message
 //
 
-// Version is current protocol version
-const Version uint16 = 3
+// Version is current protocol version, it's max
+// version the node supports
+const Version uint16 = 4
+
+// MinVersion is the oldest protocol version a
+// node negotiates (see Syn); peers of the
+// LegacyVersion are handled separately
+const MinVersion uint16 = 4
+
+// LegacyVersion is protocol version of peers
+// that don't prove their NodeIDs and don't
+// negotiate anything in handshake; such peers
+// send short Syn and expect short Ack (see
+// decodeLegacySyn and Ack)
+const LegacyVersion uint16 = 3
+
+// A Features represents set of capability flags
+// of a node. A feature can be used only if both
+// peers support it
+type Features uint32
+
+// capabilities
+const (
+	// FeatureBatch is RqObjects and Objects messages
+	FeatureBatch Features = 1 << iota
+	// FeatureCompression is compression of values
+	// of Root, Object and Objects messages
+	FeatureCompression
+	// FeaturePeers is RqPeers and Peers messages
+	FeaturePeers
+	// FeatureHistory is RqRoots and Roots messages
+	FeatureHistory
+)
+
+// Supported is set of features this
+// implementation supports
+const Supported Features = FeatureBatch | FeatureCompression |
+	FeaturePeers | FeatureHistory
+
+// Has returns true if the Features contains all
+// given features
+func (f Features) Has(features Features) bool {
+	return f&features == features
+}
+
+// String implements fmt.Stringer interface
+func (f Features) String() (s string) {
+
+	var names []string
+
+	if f.Has(FeatureBatch) == true {
+		names = append(names, "batch")
+		f &^= FeatureBatch
+	}
+
+	if f.Has(FeatureCompression) == true {
+		names = append(names, "compression")
+		f &^= FeatureCompression
+	}
+
+	if f.Has(FeaturePeers) == true {
+		names = append(names, "peers")
+		f &^= FeaturePeers
+	}
+
+	if f.Has(FeatureHistory) == true {
+		names = append(names, "history")
+		f &^= FeatureHistory
+	}
+
+	if f != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint32(f)))
+	}
+
+	return fmt.Sprint(names)
+}
+
+// MaxKeys is max number of keys of RqObjects
+const MaxKeys int = 128
+
+// MaxPeers is max number of addresses of Peers
+const MaxPeers int = 32
+
+// MaxRoots is max number of Root objects of Roots
+const MaxRoots int = 64
 
 // be sure that all messages implements Msg interface compiler time
 var (
@@ -29,8 +112,9 @@ var (
 
 	// handshake
 
-	_ Msg = &Syn{} // <- Syn (node id, protocol version)
-	_ Msg = &Ack{} // -> Ack (peer id)
+	_ Msg = &Syn{}  // <- Syn  (versions, id, challenge, encryption, features)
+	_ Msg = &Ack{}  // -> Ack  (peer id, challenge, proof, version, features)
+	_ Msg = &Auth{} // <- Auth (proof)
 
 	// common replies
 
@@ -56,9 +140,22 @@ var (
 	_ Msg = &RqObject{} // <- RqO (key, prefetch)
 	_ Msg = &Object{}   // -> O   (val, vals)
 
+	_ Msg = &RqObjects{} // <- RqOs (keys)
+	_ Msg = &Objects{}   // -> Os   (vals)
+
 	// preview
 
 	_ Msg = &RqPreview{} // -> RqPreview (feed)
+
+	// peer exchange
+
+	_ Msg = &RqPeers{} // <- RqPeers (feed, listen)
+	_ Msg = &Peers{}   // -> Peers   (feed, addresses)
+
+	// history
+
+	_ Msg = &RqRoots{} // <- RqRoots (feed, nonce, from, to)
+	_ Msg = &Roots{}   // -> Roots   (feed, nonce, roots)
 )
 
 //
@@ -105,10 +202,22 @@ func (*Pong) Encode() []byte {
 // handshake
 //
 
-// A Syn is handshake initiator message
+// A Syn is handshake initiator message. The MinVersion
+// and the MaxVersion is range of protocol versions the
+// initiator supports, the acceptor chooses one of them.
+// The Challenge is random hash the remote peer should
+// sign by its secret key to prove its NodeID. The
+// Encryption is encryption mode of the initiator (off,
+// optional or required). The Features is capabilities
+// of the initiator. A peer of the LegacyVersion sends
+// short Syn (see decodeLegacySyn)
 type Syn struct {
-	Protocol uint16
-	NodeID   cipher.PubKey // node id
+	MinVersion uint16        // oldest supported version
+	MaxVersion uint16        // newest supported version
+	NodeID     cipher.PubKey // node id
+	Challenge  cipher.SHA256 // random challenge
+	Encryption uint8         // encryption mode
+	Features   Features      // capabilities
 }
 
 // Type implements Msg interface
@@ -117,18 +226,72 @@ func (*Syn) Type() Type { return SynType }
 // Encode the Syn
 func (s *Syn) Encode() []byte { return encode(s) }
 
+// Syn of the LegacyVersion
+type synV3 struct {
+	Protocol uint16
+	NodeID   cipher.PubKey
+}
+
 // An Ack is response for the Syn
 // if handshake has been accepted.
-// Otherwise, the Err returned
+// Otherwise, the Err returned. The
+// Proof is signature of Challenge
+// of the Syn, and the Challenge of
+// the Ack should be signed by the
+// initiator. If the Encrypt is true, then
+// the connection will be encrypted after
+// the handshake. The Protocol is version
+// chosen by the acceptor and the Features
+// is capabilities of the acceptor. If the
+// Protocol is the LegacyVersion, then the
+// Ack is encoded with the NodeID only, that
+// an old initiator expects
 type Ack struct {
-	NodeID cipher.PubKey // node id
+	NodeID    cipher.PubKey // node id
+	Challenge cipher.SHA256 // random challenge
+	Proof     cipher.Sig    // signed challenge of the Syn
+	Encrypt   bool          // use encryption
+	Protocol  uint16        // chosen version
+	Features  Features      // capabilities
 }
 
 // Type implements Msg interface
 func (*Ack) Type() Type { return AckType }
 
 // Encode the Ack
-func (a *Ack) Encode() []byte { return encode(a) }
+func (a *Ack) Encode() []byte {
+
+	if a.Protocol != LegacyVersion {
+		return encode(a)
+	}
+
+	return append(
+		[]byte{
+			byte(AckType),
+		},
+		encoder.Serialize(ackV3{
+			NodeID: a.NodeID,
+		})...,
+	)
+}
+
+// Ack of the LegacyVersion
+type ackV3 struct {
+	NodeID cipher.PubKey
+}
+
+// An Auth is last message of handshake, that
+// contains signed challenge of the Ack. The
+// Auth replied with Ok or with Err
+type Auth struct {
+	Proof cipher.Sig // signed challenge of the Ack
+}
+
+// Type implements Msg interface
+func (*Auth) Type() Type { return AuthType }
+
+// Encode the Auth
+func (a *Auth) Encode() []byte { return encode(a) }
 
 //
 // common
@@ -276,6 +439,35 @@ func (*Object) Type() Type { return ObjectType }
 // Encode the Object
 func (o *Object) Encode() []byte { return encode(o) }
 
+// A RqObjects represents a Msg that request
+// many objects by hashes at once. The RqObjects
+// is supported by peers with the FeatureBatch
+type RqObjects struct {
+	Keys []cipher.SHA256 // request
+}
+
+// Type implements Msg interface
+func (*RqObjects) Type() Type { return RqObjectsType }
+
+// Encode the RqObjects
+func (r *RqObjects) Encode() []byte { return encode(r) }
+
+// An Objects is reply for RqObjects. The Values
+// contains objects that the peer has. The Values
+// can contain not all requested objects, because
+// of size limit (MaxObjectSize) or because the
+// peer doesn't have some of them. Order of the
+// Values is not defined; keys are hashes of them
+type Objects struct {
+	Values [][]byte // encoded objects in person
+}
+
+// Type implements Msg interface
+func (*Objects) Type() Type { return ObjectsType }
+
+// Encode the Objects
+func (o *Objects) Encode() []byte { return encode(o) }
+
 //
 // preview
 //
@@ -291,6 +483,85 @@ func (*RqPreview) Type() Type { return RqPreviewType }
 // Encode the RqPreview
 func (r *RqPreview) Encode() []byte { return encode(r) }
 
+//
+// peer exchange
+//
+
+// A RqPeers is request for addresses of peers
+// that share given feed. The Listen is listening
+// addresses of the requester, the replying peer
+// remembers them; blank or unspecified host of an
+// address means host of the requester. The RqPeers
+// is supported by peers with the FeaturePeers
+type RqPeers struct {
+	Feed   cipher.PubKey
+	Listen []PeerAddress
+}
+
+// Type implements Msg interface
+func (*RqPeers) Type() Type { return RqPeersType }
+
+// Encode the RqPeers
+func (r *RqPeers) Encode() []byte { return encode(r) }
+
+// A PeerAddress represents listening
+// address of a peer
+type PeerAddress struct {
+	Network string // "tcp" or "udp"
+	Address string // address
+}
+
+// A Peers is reply for RqPeers. The Peers contains
+// addresses of peers that share the Feed, known by
+// the replying peer; up to MaxPeers addresses
+type Peers struct {
+	Feed  cipher.PubKey
+	Peers []PeerAddress
+}
+
+// Type implements Msg interface
+func (*Peers) Type() Type { return PeersType }
+
+// Encode the Peers
+func (p *Peers) Encode() []byte { return encode(p) }
+
+//
+// history
+//
+
+// A RqRoots is request for Root objects of a head
+// with seq numbers in range [From, To]. The RqRoots
+// is supported by peers with the FeatureHistory
+type RqRoots struct {
+	Feed  cipher.PubKey
+	Nonce uint64
+	From  uint64 // first seq
+	To    uint64 // last seq
+}
+
+// Type implements Msg interface
+func (*RqRoots) Type() Type { return RqRootsType }
+
+// Encode the RqRoots
+func (r *RqRoots) Encode() []byte { return encode(r) }
+
+// A Roots is reply for RqRoots. The Roots contains
+// Root objects the replying peer has, ordered by
+// seq; up to MaxRoots Root objects. Missing Root
+// objects are skipped, and empty Roots means that
+// the peer doesn't have Root objects in the range
+type Roots struct {
+	Feed  cipher.PubKey
+	Nonce uint64
+	Roots []Root
+}
+
+// Type implements Msg interface
+func (*Roots) Type() Type { return RootsType }
+
+// Encode the Roots
+func (r *Roots) Encode() []byte { return encode(r) }
+
 //
 // Type / Encode / Deocode / String()
 //
@@ -321,6 +592,17 @@ const (
 	ObjectType   // 13
 
 	RqPreviewType // 14
+
+	AuthType // 15
+
+	RqObjectsType // 16
+	ObjectsType   // 17
+
+	RqPeersType // 18
+	PeersType   // 19
+
+	RqRootsType // 20
+	RootsType   // 21
 )
 
 // Type to string mapping
@@ -346,6 +628,17 @@ var msgTypeString = [...]string{
 	ObjectType:   "Object",
 
 	RqPreviewType: "RqPreview",
+
+	AuthType: "Auth",
+
+	RqObjectsType: "RqObjects",
+	ObjectsType:   "Objects",
+
+	RqPeersType: "RqPeers",
+	PeersType:   "Peers",
+
+	RqRootsType: "RqRoots",
+	RootsType:   "Roots",
 }
 
 // String implements fmt.Stringer interface
@@ -378,6 +671,17 @@ var forwardRegistry = [...]reflect.Type{
 	ObjectType:   reflect.TypeOf(Object{}),
 
 	RqPreviewType: reflect.TypeOf(RqPreview{}),
+
+	AuthType: reflect.TypeOf(Auth{}),
+
+	RqObjectsType: reflect.TypeOf(RqObjects{}),
+	ObjectsType:   reflect.TypeOf(Objects{}),
+
+	RqPeersType: reflect.TypeOf(RqPeers{}),
+	PeersType:   reflect.TypeOf(Peers{}),
+
+	RqRootsType: reflect.TypeOf(RqRoots{}),
+	RootsType:   reflect.TypeOf(Roots{}),
 }
 
 // An InvalidTypeError represents decoding error when
@@ -441,6 +745,9 @@ func Decode(p []byte) (msg Msg, err error) {
 	)
 
 	if n, err = encoder.DeserializeRawToValue(p[1:], val); err != nil {
+		if mt == SynType {
+			return decodeLegacySyn(p[1:]) // old peer
+		}
 		return
 	}
 
@@ -452,3 +759,40 @@ func Decode(p []byte) (msg Msg, err error) {
 	msg = val.Interface().(Msg)
 	return
 }
+
+// decode Syn of the LegacyVersion, the Syn
+// doesn't contain the Challenge, the Encryption
+// (it's zero, that is "off") and the Features
+func decodeLegacySyn(p []byte) (msg Msg, err error) {
+
+	var v3 synV3
+
+	if err = decodeExactly(p, &v3); err != nil {
+		return
+	}
+
+	msg = &Syn{
+		MinVersion: v3.Protocol,
+		MaxVersion: v3.Protocol,
+		NodeID:     v3.NodeID,
+	}
+	return
+}
+
+// decode given value that should
+// take all the p
+func decodeExactly(p []byte, val interface{}) (err error) {
+
+	var n int
+
+	n, err = encoder.DeserializeRawToValue(p, reflect.ValueOf(val))
+	if err != nil {
+		return
+	}
+
+	if n != len(p) {
+		err = ErrIncomplieDecoding
+	}
+
+	return
+}
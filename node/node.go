@@ -2,12 +2,12 @@ package node
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/net/factory"
-	discovery "github.com/skycoin/net/skycoin-messenger/factory"
 
 	"github.com/skycoin/cxo/node/log"
 	"github.com/skycoin/cxo/skyobject"
@@ -24,20 +24,34 @@ import (
 // old Root if there is a newer one. The Node
 // uses TCP and UDP transports.
 type Node struct {
+	// bytes saved by compression, the fields used
+	// atomically and should be first (64-bit alignment)
+	sentSaved     int64
+	receivedSaved int64
+
+	// subtrees and objects skipped by delta sync and
+	// objects received during filling (used atomically
+	// too)
+	fillSkippedSubtrees int64
+	fillSkippedObjects  int64
+	fillFetched         int64
+
 	mx sync.Mutex // lock
 
-	log.Logger                       // logger
-	id         *discovery.SeedConfig // unique random identifier
-	c          *skyobject.Container  // related Container
+	log.Logger                      // logger
+	c          *skyobject.Container // related Container
 
-	idpk cipher.PubKey // id.PublicKey (string -> pk)
+	idpk cipher.PubKey // unique random identifier
+	idsk cipher.SecKey // secret key of the identifier
 
 	//
 	// feeds and connections
 	//
 
+	ac accessControl           // allow and deny lists
 	fs *nodeFeeds              // feeds
 	ic map[cipher.PubKey]*Conn // node id (pk) -> connection
+	uc map[*Conn]struct{}      // not authenticated connections
 	pc map[*Conn]struct{}      // pending connections
 
 	//
@@ -45,8 +59,10 @@ type Node struct {
 	//
 
 	// listen and connect
-	tcp *TCP
-	udp *UDP
+	tcp  *TCP
+	udp  *UDP
+	unix *Unix
+	ws   *WS
 
 	//
 	// other
@@ -56,6 +72,31 @@ type Node struct {
 	maxFillingParallel int     // copy of c.Config().MaxFillingParallel
 	rollAvgSamples     int     // copy of c.Config().RollAvgSamples
 
+	//
+	// persistent peers
+	//
+
+	pp persistentPeers
+
+	//
+	// address book
+	//
+
+	ab addressBook
+
+	//
+	// local discovery
+	//
+
+	local []*localDiscovery
+
+	//
+	// bandwidth
+	//
+
+	bw     limiter   // limits of the Node
+	connbw Bandwidth // default limits of a connection
+
 	//
 	// stat
 	//
@@ -72,9 +113,10 @@ type Node struct {
 	//  closing
 	//
 
-	await  sync.WaitGroup // wait for goroutines
-	closeo sync.Once      // close once
-	closeq chan struct{}  // closed
+	await   sync.WaitGroup // wait for goroutines
+	closeo  sync.Once      // close once
+	closeq  chan struct{}  // closed
+	closemx sync.Mutex     // lock closeq and spawning (see spawn)
 }
 
 // NewNode creates new Node instance using provided
@@ -123,16 +165,37 @@ func NewNodeContainer(
 
 	n = new(Node)
 
-	n.id = discovery.NewSeedConfig()
-	n.idpk, _ = cipher.PubKeyFromHex(n.id.PublicKey)
 	n.c = c
 	n.fs = newNodeFeeds(n)
 	n.ic = make(map[cipher.PubKey]*Conn)
+	n.uc = make(map[*Conn]struct{})
 	n.pc = make(map[*Conn]struct{})
 
 	n.config = conf
 	n.config.Config = c.Config() // actual
 
+	n.rollAvgSamples = n.config.Config.RollAvgSamples
+
+	n.ac.init(&conf.Allow, &conf.Deny)
+	n.pp.init()
+	n.bw.set(conf.Bandwidth)
+	n.connbw = conf.ConnBandwidth
+
+	if conf.KeyFile != "" {
+		n.idpk, n.idsk, err = loadIdentity(conf.keyFilePath())
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	} else {
+		n.idpk, n.idsk = cipher.GenerateKeyPair()
+	}
+
+	if err = n.ab.open(conf.peersFilePath(), conf.MaxPeerFailures); err != nil {
+		c.Close()
+		return nil, err
+	}
+
 	n.fillavg = statutil.NewDuration(conf.Config.RollAvgSamples)
 	n.closeq = make(chan struct{})
 
@@ -160,6 +223,20 @@ func NewNodeContainer(
 		}
 	}
 
+	if conf.Unix.Listen != "" {
+		if err = n.Unix().Listen(conf.Unix.Listen); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	if conf.WS.Listen != "" {
+		if err = n.WS().Listen(conf.WS.Listen); err != nil {
+			n.Close()
+			return
+		}
+	}
+
 	// rpc
 
 	if conf.RPC != "" {
@@ -183,6 +260,58 @@ func NewNodeContainer(
 		n.UDP().ConnectToDiscoveryServer(address)
 	}
 
+	// local discovery
+
+	if conf.TCP.LocalDiscovery != "" {
+		if err = n.startLocalDiscovery("tcp", &conf.TCP); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	if conf.UDP.LocalDiscovery != "" {
+		if err = n.startLocalDiscovery("udp", &conf.UDP); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	// persistent peers
+
+	for _, address := range conf.TCP.Peers {
+		if err = n.AddPersistentPeer("tcp", address); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	for _, address := range conf.UDP.Peers {
+		if err = n.AddPersistentPeer("udp", address); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	for _, address := range conf.Unix.Peers {
+		if err = n.AddPersistentPeer("unix", address); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	for _, address := range conf.WS.Peers {
+		if err = n.AddPersistentPeer("ws", address); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	// address book
+
+	if conf.Bootstrap > 0 {
+		n.spawn(&n.ab.await, n.bootstrap)
+	}
+
 	// TODO (kostyarin): pings (move to connection)
 
 	return
@@ -190,7 +319,12 @@ func NewNodeContainer(
 
 // ID retursn identifier of the Node. The identifier
 // is unique random identifier that used to avoid
-// cross-connections
+// cross-connections. The ID can be persistent
+// (see KeyFile of Config). The Node proves that it owns
+// the ID (has secret key of the ID) during handshake,
+// thus, PeerID of an authenticated Conn can't be forged.
+// Peers of the msg.LegacyVersion don't prove their IDs
+// (see AcceptLegacy of Config and Conn.IsAuthenticated)
 func (n *Node) ID() (id cipher.PubKey) {
 	return n.idpk
 }
@@ -234,12 +368,16 @@ func (n *Node) Connections() (cs []*Conn) {
 	n.mx.Lock()
 	defer n.mx.Unlock()
 
-	cs = make([]*Conn, 0, len(n.ic))
+	cs = make([]*Conn, 0, len(n.ic)+len(n.uc))
 
 	for _, c := range n.ic {
 		cs = append(cs, c)
 	}
 
+	for c := range n.uc {
+		cs = append(cs, c)
+	}
+
 	return
 }
 
@@ -285,6 +423,48 @@ func (n *Node) UDP() (tcp *UDP) {
 	return n.udp
 }
 
+// don't create Unix in background
+// returning nil, if the Unix doesn't
+// exist
+func (n *Node) getUnix() (u *Unix) {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	return n.unix
+}
+
+// Unix returns Unix domain socket transport of the Node
+func (n *Node) Unix() (unix *Unix) {
+
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	n.createUnix()
+
+	return n.unix
+}
+
+// don't create WS in background
+// returning nil, if the WS doesn't
+// exist
+func (n *Node) getWS() (w *WS) {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	return n.ws
+}
+
+// WS returns WebSocket transport of the Node
+func (n *Node) WS() (ws *WS) {
+
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	n.createWS()
+
+	return n.ws
+}
+
 // add to pending
 func (n *Node) addPendingConn(c *Conn) {
 	n.mx.Lock()
@@ -299,11 +479,18 @@ func (n *Node) addConnection(c *Conn) (err error) {
 	n.mx.Lock()
 	defer n.mx.Unlock()
 
-	if _, ok := n.ic[c.peerID]; ok == true {
+	// NodeID of a Conn that is not authenticated
+	// is not verified, and such Conn never takes
+	// place of a real peer with the NodeID
+
+	if c.verified == false {
+		n.uc[c] = struct{}{} // add
+	} else if _, ok := n.ic[c.peerID]; ok == true {
 		return ErrAlreadyHaveConnection
+	} else {
+		n.ic[c.peerID] = c // add
 	}
 
-	n.ic[c.peerID] = c                   // add
 	delete(n.pc, c)                      // remove from pending
 	n.fs.addConnFeed(c, cipher.PubKey{}) // add to blank feed
 
@@ -312,10 +499,40 @@ func (n *Node) addConnection(c *Conn) (err error) {
 
 func (n *Node) delConnection(c *Conn) {
 	n.mx.Lock()
-	defer n.mx.Unlock()
 
-	delete(n.ic, c.peerID)
+	if c.verified == false {
+		delete(n.uc, c)
+	} else if n.ic[c.peerID] == c {
+		delete(n.ic, c.peerID)
+	}
+
 	n.fs.delConn(c)
+
+	var tcp, udp, unix, ws = n.tcp, n.udp, n.unix, n.ws
+
+	n.mx.Unlock()
+
+	// remove from transport to connect again, the
+	// transports use lock of the Node inside own
+	// lock (see Connect), thus it's outside the lock
+	switch c.network {
+	case "tcp":
+		if tcp != nil {
+			tcp.delConn(c)
+		}
+	case "udp":
+		if udp != nil {
+			udp.delConn(c)
+		}
+	case "unix":
+		if unix != nil {
+			unix.delConn(c)
+		}
+	case "ws":
+		if ws != nil {
+			ws.delConn(c)
+		}
+	}
 }
 
 // call under lock of the mx
@@ -340,6 +557,43 @@ func (n *Node) createUDP() {
 
 }
 
+// call under lock of the mx
+func (n *Node) createUnix() {
+
+	if n.unix != nil {
+		return // already created
+	}
+
+	n.unix = newUnix(n)
+
+}
+
+// call under lock of the mx
+func (n *Node) createWS() {
+
+	if n.ws != nil {
+		return // already created
+	}
+
+	n.ws = newWS(n)
+
+}
+
+// configurations of given network
+func (n *Node) netConfig(network string) (nc *NetConfig) {
+	switch network {
+	case "tcp":
+		nc = &n.config.TCP
+	case "udp":
+		nc = &n.config.UDP
+	case "ws":
+		nc = &n.config.WS
+	default:
+		nc = &n.config.Unix
+	}
+	return
+}
+
 func (n *Node) onConnect(c *Conn) {
 
 	if occ := n.config.OnConnect; occ != nil {
@@ -371,13 +625,27 @@ func (n *Node) onDisconenct(c *Conn, reason error) {
 
 func (n *Node) acceptConnection(fc *factory.Connection) {
 
+	if fc.IsTCP() == true {
+		n.accept(fc, "tcp")
+	} else {
+		n.accept(fc, "udp")
+	}
+
+}
+
+func (n *Node) acceptUnixConnection(uc *unixConnection) {
+	n.accept(uc, "unix")
+}
+
+func (n *Node) accept(fc Connection, network string) {
+
 	n.Debugf(NewInConnPin, "[%s] accept",
-		connString(true, fc.IsTCP(), fc.GetRemoteAddr().String()))
+		connString(true, network, fc.GetRemoteAddr().String()))
 
-	if _, err := n.wrapConnection(fc, true); err != nil {
+	if _, err := n.wrapConnection(fc, network, true); err != nil {
 
 		n.Printf("[ERR] [%s] handshake error: %v",
-			connString(true, fc.IsTCP(), fc.GetRemoteAddr().String()),
+			connString(true, network, fc.GetRemoteAddr().String()),
 			err)
 
 	}
@@ -385,7 +653,7 @@ func (n *Node) acceptConnection(fc *factory.Connection) {
 }
 
 // delete from pending and close underlying
-// factory.Connection
+// Connection
 func (n *Node) delPendingConnClose(c *Conn) {
 
 	n.mx.Lock()
@@ -397,17 +665,18 @@ func (n *Node) delPendingConnClose(c *Conn) {
 }
 
 func (n *Node) wrapConnection(
-	fc *factory.Connection, // :
-	isIncoming bool, //        :
+	fc Connection, //     :
+	network string, //    :
+	isIncoming bool, //   :
 ) (
-	c *Conn, //                :
-	err error, //              :
+	c *Conn, //           :
+	err error, //         :
 ) {
 
 	n.Debugf(ConnHskPin, "[%s] wrapConnection",
-		connString(isIncoming, fc.IsTCP(), fc.GetRemoteAddr().String()))
+		connString(isIncoming, network, fc.GetRemoteAddr().String()))
 
-	c = n.newConnection(fc, isIncoming) // adds to pending
+	c = n.newConnection(fc, network, isIncoming) // adds to pending
 
 	// handshake
 	if err = c.handshake(n.closeq); err != nil {
@@ -422,17 +691,25 @@ func (n *Node) wrapConnection(
 		return
 	}
 
+	n.rememberConn(c)
+
 	// add to transport if the connection is incoming
 	if isIncoming == true {
-		if c.IsTCP() == true {
+		switch network {
+		case "tcp":
 			n.TCP().addAcceptedConnection(c)
-		} else {
+		case "udp":
 			n.UDP().addAcceptedConnection(c)
+		case "unix":
+			n.Unix().addAcceptedConnection(c)
+		case "ws":
+			n.WS().addAcceptedConnection(c)
 		}
 	}
 
 	c.run()
 	n.onConnect(c)
+	n.exchangeFeeds(c)
 
 	return
 
@@ -500,6 +777,7 @@ func (n *Node) Share(feed cipher.PubKey) (err error) {
 
 	if n.fs.addFeed(feed) == true {
 		n.updateServiceDiscovery()
+		n.exchangeFeed(feed)
 	}
 
 	return
@@ -564,6 +842,29 @@ func (n *Node) onRootFilled(r *registry.Root) {
 
 }
 
+func (n *Node) addFillingStat(fp skyobject.FillingProgress) {
+	atomic.AddInt64(&n.fillSkippedSubtrees, int64(fp.SkippedSubtrees))
+	atomic.AddInt64(&n.fillSkippedObjects, int64(fp.SkippedObjects))
+	atomic.AddInt64(&n.fillFetched, int64(fp.Received))
+}
+
+func (n *Node) fillPriority(r *registry.Root) (fp *skyobject.FillPriority) {
+
+	if fpf := n.config.FillPriority; fpf != nil {
+		fp = fpf(n, r)
+	}
+
+	return
+}
+
+func (n *Node) onPriorityFilled(r *registry.Root, i int) {
+
+	if opf := n.config.OnPriorityFilled; opf != nil {
+		opf(n, r, i)
+	}
+
+}
+
 func (n *Node) onFillingBreaks(r *registry.Root, reason error) {
 
 	if brk := n.config.OnFillingBreaks; brk != nil {
@@ -572,7 +873,20 @@ func (n *Node) onFillingBreaks(r *registry.Root, reason error) {
 
 }
 
-// has connection to peer with given id (pk)
+func (n *Node) onEquivocation(c *Conn, e *skyobject.Equivocation) {
+
+	n.Printf("[ERR] [%s] equivocation of %s/%d/%d: %s and %s",
+		c.String(), e.Feed.Hex()[:7], e.Nonce, e.Seq,
+		e.First.Hash.Hex()[:7], e.Second.Hash.Hex()[:7])
+
+	if oe := n.config.OnEquivocation; oe != nil {
+		oe(n, c, e)
+	}
+
+}
+
+// has authenticated connection to
+// peer with given id (pk)
 func (n *Node) hasPeer(id cipher.PubKey) (c *Conn, yep bool) {
 	n.mx.Lock()
 	defer n.mx.Unlock()
@@ -585,6 +899,52 @@ func (n *Node) hasPeer(id cipher.PubKey) (c *Conn, yep bool) {
 type Stat struct {
 	*skyobject.Stat
 	Fillavg time.Duration
+
+	// bytes saved by compression
+	SentSaved     int64 // sent objects and Root objects
+	ReceivedSaved int64 // received objects and Root objects
+
+	// filling, see also Config.DeltaSync
+	FillSkippedSubtrees int64 // subtrees skipped by delta sync
+	FillSkippedObjects  int64 // estimated objects of the subtrees
+	FillFetched         int64 // objects received from peers
+
+	// throughput, bytes per second
+	Upload   float64
+	Download float64
+
+	// Connections is stat of connections
+	Connections []ConnStat
+}
+
+// A ConnStat represents stat of a connection
+type ConnStat struct {
+	Address string        // remote address
+	PeerID  cipher.PubKey // id of remote peer
+
+	Bandwidth Bandwidth    // limits of the connection
+	Counters  ConnCounters // received messages
+
+	// throughput, bytes per second
+	Upload   float64
+	Download float64
+
+	// Score is quality of the peer as
+	// a source of objects during filling
+	Score PeerScore
+}
+
+// Stat returns statistic of the Conn
+func (c *Conn) Stat() (cs ConnStat) {
+
+	cs.Address = c.String()
+	cs.PeerID = c.PeerID()
+	cs.Bandwidth = c.Bandwidth()
+	cs.Counters = c.Counters()
+	cs.Upload, cs.Download = c.Throughput()
+	cs.Score = c.Score()
+
+	return
 }
 
 // Stat returns statistic of the Node
@@ -596,35 +956,107 @@ func (n *Node) Stat() (s *Stat) {
 	s.Stat = n.c.Stat()
 	s.Fillavg = n.fillavg.Value()
 
+	s.SentSaved = atomic.LoadInt64(&n.sentSaved)
+	s.ReceivedSaved = atomic.LoadInt64(&n.receivedSaved)
+
+	s.FillSkippedSubtrees = atomic.LoadInt64(&n.fillSkippedSubtrees)
+	s.FillSkippedObjects = atomic.LoadInt64(&n.fillSkippedObjects)
+	s.FillFetched = atomic.LoadInt64(&n.fillFetched)
+
+	s.Upload, s.Download = n.Throughput()
+
+	for _, c := range n.Connections() {
+		s.Connections = append(s.Connections, c.Stat())
+	}
+
 	return
 }
 
+// spawn runs given function in a goroutine of given
+// WaitGroup, it returns false if the Node is closed;
+// the Close waits the groups after closing, and the
+// lock guarantees that the Add never races with the
+// Wait
+func (n *Node) spawn(await *sync.WaitGroup, fn func()) (ok bool) {
+
+	n.closemx.Lock()
+	defer n.closemx.Unlock()
+
+	select {
+	case <-n.closeq:
+		return // closed
+	default:
+	}
+
+	await.Add(1)
+	go func() {
+		defer await.Done()
+		fn()
+	}()
+
+	return true
+}
+
 // Close the Node. The Close returns error
 // of (skyobject.Container).Close once.
 func (n *Node) Close() (err error) {
 	n.closeo.Do(func() {
 
+		n.closemx.Lock()
 		close(n.closeq)
+		n.closemx.Unlock()
 
 		n.mx.Lock()
-		defer n.mx.Unlock()
+		var (
+			local = n.local
+
+			tcp  = n.tcp
+			udp  = n.udp
+			unix = n.unix
+			ws   = n.ws
+		)
+		n.mx.Unlock()
+
+		for _, l := range local {
+			l.close() // local discovery
+		}
 
-		err = n.c.Close()
+		// close the transports first, the persistent peers,
+		// bootstrap and peer exchange don't wait for dials
+		// after the closeq is closed (see dial)
+
+		if tcp != nil {
+			tcp.Close()
+		}
 
-		if n.tcp != nil {
-			n.tcp.Close()
+		if udp != nil {
+			udp.Close()
 		}
 
-		if n.udp != nil {
-			n.udp.Close()
+		if unix != nil {
+			unix.Close()
 		}
 
+		if ws != nil {
+			ws.Close()
+		}
+
+		n.pp.await.Wait() // persistent peers
+		n.ab.await.Wait() // bootstrap and peer exchange
+
+		n.mx.Lock()
+		defer n.mx.Unlock()
+
+		err = n.c.Close()
+
 		if n.rpc != nil {
 			n.rpc.Close()
 		}
 
 		n.await.Wait()
 
+		n.ab.close() // ignore error
+
 	})
 
 	return
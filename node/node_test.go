@@ -1,6 +1,7 @@
 package node
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -27,6 +28,138 @@ func getTestNodeNotListen(prefix string) (n *Node) {
 	return
 }
 
+// a testPair is two nodes that share the same feed;
+// the a listens and the b doesn't; Root objects
+// filled by the b are sent to the filled channel
+type testPair struct {
+	pk cipher.PubKey
+	sk cipher.SecKey
+
+	a, b *Node
+
+	up    *skyobject.Unpack // Unpack of the a
+	posts int               // posts created
+
+	filled chan *registry.Root // filled by the b
+}
+
+// newTestPair creates two nodes; given configure
+// function (that can be nil) can change
+// configurations of the nodes
+func newTestPair(
+	t *testing.T, //                  : the test
+	configure func(ac, bc *Config), // : change configurations or nil
+) (
+	tp *testPair, //                  : the nodes
+) {
+
+	t.Helper()
+
+	tp = new(testPair)
+
+	tp.pk, tp.sk = cipher.GenerateKeyPair()
+	tp.filled = make(chan *registry.Root, 10)
+
+	var (
+		ac = getTestConfig("a")
+		bc = getTestConfigNotListen("b")
+
+		err error
+	)
+
+	bc.OnRootFilled = func(_ *Node, r *registry.Root) {
+		select {
+		case tp.filled <- r:
+		default:
+		}
+	}
+
+	if configure != nil {
+		configure(ac, bc)
+	}
+
+	if tp.a, err = NewNode(ac); err != nil {
+		t.Fatal(err)
+	}
+
+	if tp.b, err = NewNode(bc); err != nil {
+		tp.a.Close()
+		t.Fatal(err)
+	}
+
+	if err = tp.a.Share(tp.pk); err == nil {
+		if err = tp.b.Share(tp.pk); err == nil {
+			tp.up, err = tp.a.Container().Unpack(tp.sk, getTestRegistry())
+		}
+	}
+
+	if err != nil {
+		tp.Close()
+		t.Fatal(err)
+	}
+
+	return
+}
+
+// Close the nodes
+func (tp *testPair) Close() {
+	tp.b.Close()
+	tp.a.Close()
+}
+
+// appendPosts appends n unique posts to given feed
+func (tp *testPair) appendPosts(t *testing.T, feed *Feed, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		var err = feed.Posts.AppendValues(tp.up, Post{
+			Head: "head",
+			Time: int64(tp.posts),
+		})
+		assertNil(t, err)
+		tp.posts++
+	}
+}
+
+// save given feeds as Refs of given Root by the a
+func (tp *testPair) save(t *testing.T, r *registry.Root, feeds ...Feed) {
+	t.Helper()
+
+	r.Refs = r.Refs[:0]
+
+	for _, feed := range feeds {
+		r.Refs = append(r.Refs, dynamicByValue(t, tp.up, "test.Feed", feed))
+	}
+
+	assertNil(t, tp.a.Container().Save(tp.up, r))
+}
+
+// connect the b to the a and subscribe to the feed
+func (tp *testPair) connect(t *testing.T) (ba *Conn) {
+	t.Helper()
+
+	var err error
+
+	ba, err = tp.b.TCP().Connect(tp.a.TCP().Address())
+	assertNil(t, err)
+	assertNil(t, ba.Subscribe(tp.pk))
+
+	return
+}
+
+// waitFilled waits for a Root filled by the b
+func (tp *testPair) waitFilled(t *testing.T) (r *registry.Root) {
+	t.Helper()
+
+	select {
+	case r = <-tp.filled:
+	case <-time.After(10 * TM):
+		t.Fatal("slow")
+	}
+
+	return
+}
+
 func TestNode_ID(t *testing.T) {
 
 	var n = getTestNodeNotListen("test")
@@ -503,3 +636,151 @@ func TestNode_Close(t *testing.T) {
 	// TODO (kostyarin): the lowest priority
 
 }
+
+func TestNode_fillPriority(t *testing.T) {
+
+	var prioritized = make(chan int, 10)
+
+	var tp = newTestPair(t, func(_, bc *Config) {
+		bc.FillPriority = func(*Node, *registry.Root) *skyobject.FillPriority {
+			return &skyobject.FillPriority{Refs: []int{2}}
+		}
+		bc.OnPriorityFilled = func(_ *Node, r *registry.Root, i int) {
+			if r.IsFull == true {
+				t.Error("full Root")
+			}
+			prioritized <- i
+		}
+	})
+	defer tp.Close()
+
+	var feeds = make([]Feed, 3)
+
+	for i := range feeds {
+		tp.appendPosts(t, &feeds[i], 1)
+	}
+
+	tp.save(t, &registry.Root{Nonce: 1, Pub: tp.pk}, feeds...)
+	tp.connect(t)
+	tp.waitFilled(t)
+
+	select {
+	case i := <-prioritized:
+		if i != 2 {
+			t.Error("wrong prioritized element:", i)
+		}
+	default:
+		t.Fatal("OnPriorityFilled not called")
+	}
+
+}
+
+func TestNode_deltaSync(t *testing.T) {
+
+	var tp = newTestPair(t, func(_, bc *Config) {
+		bc.DeltaSync = true
+	})
+	defer tp.Close()
+
+	var (
+		feed Feed
+		r    = &registry.Root{Nonce: 1, Pub: tp.pk}
+	)
+
+	tp.appendPosts(t, &feed, 64)
+	tp.save(t, r, feed)
+	tp.connect(t)
+	tp.waitFilled(t)
+
+	// the next Root shares most of objects with the first one
+
+	tp.appendPosts(t, &feed, 1)
+	tp.save(t, r, feed)
+
+	tp.a.Publish(r)
+
+	if fr := tp.waitFilled(t); fr.Seq != r.Seq {
+		t.Fatal("wrong Root filled:", fr.Seq, r.Seq)
+	}
+
+	var s = tp.b.Stat()
+
+	if s.FillSkippedSubtrees == 0 {
+		t.Error("nothing skipped")
+	}
+
+	if s.FillSkippedObjects < s.FillSkippedSubtrees {
+		t.Error("wrong number of skipped objects:", s.FillSkippedObjects)
+	}
+
+	if s.FillFetched == 0 {
+		t.Error("nothing fetched")
+	}
+
+}
+
+func TestNode_resumePending(t *testing.T) {
+
+	var tp = newTestPair(t, nil)
+	defer tp.Close()
+
+	var (
+		feed Feed
+		r    = &registry.Root{Nonce: 1, Pub: tp.pk}
+	)
+
+	tp.appendPosts(t, &feed, 16)
+	tp.save(t, r, feed)
+
+	// the b receives a few objects of the Root
+	// and the Root becomes pending
+
+	var (
+		pr = *r
+		rq = make(chan cipher.SHA256, 10)
+		f  = tp.b.Container().Fill(&pr, rq, 10)
+	)
+
+	pr.Refs = append([]registry.Dynamic{}, r.Refs...)
+
+	go func() {
+		var received int
+		for key := range rq {
+			if received == 3 {
+				f.Fail(errors.New("break"))
+				continue
+			}
+			received++
+			var val, _, err = tp.a.Container().Get(key, 0)
+			assertNil(t, err)
+			_, err = tp.b.Container().SetWanted(key, val)
+			assertNil(t, err)
+		}
+	}()
+
+	if f.Run() == nil {
+		t.Fatal("missing error")
+	}
+	close(rq)
+
+	if tp.b.Container().IsPending(tp.pk, pr.Nonce, pr.Seq) == false {
+		t.Fatal("not pending")
+	}
+
+	// the a has newer Root, and it pushes only the newer
+
+	tp.appendPosts(t, &feed, 1)
+	tp.save(t, r, feed)
+	tp.connect(t)
+
+	var seqs = make(map[uint64]bool)
+
+	for len(seqs) < 2 {
+		seqs[tp.waitFilled(t).Seq] = true
+	}
+
+	if seqs[pr.Seq] == false {
+		t.Error("pending Root not filled")
+	}
+
+}
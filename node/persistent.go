@@ -0,0 +1,371 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A PersistentPeer represents a peer the Node keeps
+// connection to. If connection with the peer closed,
+// then the Node redials it with exponential backoff
+// and restores subscriptions of the connection
+type PersistentPeer struct {
+	Network   string          // "tcp", "udp", "unix" or "ws"
+	Address   string          // address of the peer
+	Connected bool            // is connected now
+	Attempts  int             // failed attempts in a row
+	Feeds     []cipher.PubKey // subscriptions to restore
+}
+
+// a persistent peer
+type persistentPeer struct {
+	network string
+	address string
+
+	feeds    map[cipher.PubKey]struct{} // subscriptions
+	c        *Conn                      // current connection or nil
+	attempts int                        // failed attempts in a row
+
+	stopq chan struct{} // removed
+}
+
+// persistent peers of the Node
+type persistentPeers struct {
+	mx    sync.Mutex
+	peers map[string]*persistentPeer // network + address -> peer
+	conns map[*Conn]*persistentPeer  // connection -> peer
+
+	// the goroutines use lock of the Node, and the
+	// Node waits them before the lock (see Close)
+	await sync.WaitGroup
+}
+
+func (p *persistentPeers) init() {
+	p.peers = make(map[string]*persistentPeer)
+	p.conns = make(map[*Conn]*persistentPeer)
+}
+
+func persistentKey(network, address string) string {
+	return network + "://" + address
+}
+
+// backoff returns time to wait before next attempt
+// to connect; the time grows exponentially from
+// ReconnectMin to ReconnectMax with random jitter
+func (n *Node) backoff(attempts int) (wait time.Duration) {
+
+	wait = n.config.ReconnectMin
+
+	for i := 0; i < attempts && wait < n.config.ReconnectMax; i++ {
+		wait *= 2
+	}
+
+	if wait > n.config.ReconnectMax {
+		wait = n.config.ReconnectMax
+	}
+
+	if wait <= 0 {
+		wait = ReconnectMin // not configured
+	}
+
+	// [wait/2, wait)
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// connect to given address using given network
+func (n *Node) connect(network, address string) (c *Conn, err error) {
+
+	switch network {
+	case "tcp":
+		return n.TCP().Connect(address)
+	case "udp":
+		return n.UDP().Connect(address)
+	case "unix":
+		return n.Unix().Connect(address)
+	case "ws":
+		return n.WS().Connect(address)
+	}
+
+	return nil, fmt.Errorf("unknown network %q", network)
+}
+
+// dial is the connect that returns ErrClosed once the
+// Node closed; a dial can't be canceled and can block
+// for a long time, thus goroutines the Close waits use
+// the dial; the dialing goroutine ends after the dial,
+// since handshake fails if the Node is closed
+func (n *Node) dial(network, address string) (c *Conn, err error) {
+
+	type dialed struct {
+		c   *Conn
+		err error
+	}
+
+	var dq = make(chan dialed, 1)
+
+	go func() {
+		var c, err = n.connect(network, address)
+		dq <- dialed{c, err}
+	}()
+
+	select {
+	case d := <-dq:
+		return d.c, d.err
+	case <-n.closeq:
+		return nil, ErrClosed
+	}
+}
+
+// AddPersistentPeer adds peer with given address the Node
+// keeps connection to. The network is "tcp", "udp", "unix"
+// or "ws". The Node connects to the peer in background. If
+// connection closed, then the Node redials the peer with
+// exponential backoff (see Config.ReconnectMin and
+// Config.ReconnectMax) and restores subscriptions (see
+// (*Conn).Subscribe) of the connection
+func (n *Node) AddPersistentPeer(network, address string) (err error) {
+
+	switch network {
+	case "tcp", "udp", "unix", "ws":
+	default:
+		return fmt.Errorf("unknown network %q", network)
+	}
+
+	var key = persistentKey(network, address)
+
+	n.pp.mx.Lock()
+	defer n.pp.mx.Unlock()
+
+	if _, ok := n.pp.peers[key]; ok == true {
+		return fmt.Errorf("already have persistent peer %s", key)
+	}
+
+	var pp = &persistentPeer{
+		network: network,
+		address: address,
+		feeds:   make(map[cipher.PubKey]struct{}),
+		stopq:   make(chan struct{}),
+	}
+
+	var keep = func() { n.keepConnection(pp) }
+
+	if n.spawn(&n.pp.await, keep) == false {
+		return ErrClosed
+	}
+
+	n.pp.peers[key] = pp
+	return
+}
+
+// RemovePersistentPeer stops redialing given peer.
+// Existing connection with the peer is not closed
+func (n *Node) RemovePersistentPeer(network, address string) (err error) {
+
+	var key = persistentKey(network, address)
+
+	n.pp.mx.Lock()
+	defer n.pp.mx.Unlock()
+
+	var pp, ok = n.pp.peers[key]
+
+	if ok == false {
+		return fmt.Errorf("no such persistent peer %s", key)
+	}
+
+	delete(n.pp.peers, key)
+
+	if pp.c != nil {
+		delete(n.pp.conns, pp.c)
+	}
+
+	close(pp.stopq)
+	return
+}
+
+// PersistentPeers returns list of persistent
+// peers ordered by network and address
+func (n *Node) PersistentPeers() (list []PersistentPeer) {
+
+	n.pp.mx.Lock()
+	defer n.pp.mx.Unlock()
+
+	for _, pp := range n.pp.peers {
+
+		var p = PersistentPeer{
+			Network:   pp.network,
+			Address:   pp.address,
+			Connected: pp.c != nil,
+			Attempts:  pp.attempts,
+		}
+
+		for feed := range pp.feeds {
+			p.Feeds = append(p.Feeds, feed)
+		}
+
+		sort.Slice(p.Feeds, func(i, j int) bool {
+			return bytes.Compare(p.Feeds[i][:], p.Feeds[j][:]) < 0
+		})
+
+		list = append(list, p)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Network != list[j].Network {
+			return list[i].Network < list[j].Network
+		}
+		return list[i].Address < list[j].Address
+	})
+
+	return
+}
+
+// subscribed to given feed, it's called by
+// (*Conn).Subscribe to restore the subscription
+// after reconnect
+func (n *Node) subscribed(c *Conn, feed cipher.PubKey) {
+
+	n.pp.mx.Lock()
+	defer n.pp.mx.Unlock()
+
+	if pp, ok := n.pp.conns[c]; ok == true {
+		pp.feeds[feed] = struct{}{}
+	}
+}
+
+// unsubscribed from given feed
+func (n *Node) unsubscribed(c *Conn, feed cipher.PubKey) {
+
+	n.pp.mx.Lock()
+	defer n.pp.mx.Unlock()
+
+	if pp, ok := n.pp.conns[c]; ok == true {
+		delete(pp.feeds, feed)
+	}
+}
+
+// connected to given persistent peer, it
+// returns subscriptions to restore
+func (n *Node) connectedPersistent(
+	pp *persistentPeer, //        :
+	c *Conn, //                   :
+) (
+	feeds []cipher.PubKey, //     :
+	ok bool, //                   : false if removed
+) {
+
+	n.pp.mx.Lock()
+	defer n.pp.mx.Unlock()
+
+	select {
+	case <-pp.stopq:
+		return // removed
+	default:
+	}
+
+	pp.c, pp.attempts = c, 0
+	n.pp.conns[c] = pp
+
+	for feed := range pp.feeds {
+		feeds = append(feeds, feed)
+	}
+
+	return feeds, true
+}
+
+// disconnected from given persistent peer (err is
+// nil) or can't connect to it (err is not nil)
+func (n *Node) disconnectedPersistent(pp *persistentPeer, err error) {
+
+	n.pp.mx.Lock()
+	defer n.pp.mx.Unlock()
+
+	if pp.c != nil {
+		delete(n.pp.conns, pp.c)
+		pp.c = nil
+	}
+
+	if err != nil {
+		pp.attempts++
+	}
+}
+
+func (n *Node) attemptsPersistent(pp *persistentPeer) int {
+	n.pp.mx.Lock()
+	defer n.pp.mx.Unlock()
+
+	return pp.attempts
+}
+
+// keep connection with given persistent peer
+func (n *Node) keepConnection(pp *persistentPeer) {
+
+	var key = persistentKey(pp.network, pp.address)
+
+	for {
+
+		select {
+		case <-n.closeq:
+			return // node closed
+		default:
+		}
+
+		var c, err = n.dial(pp.network, pp.address)
+
+		if err == nil {
+
+			var feeds, ok = n.connectedPersistent(pp, c)
+
+			if ok == false {
+				return // removed
+			}
+
+			for _, feed := range feeds {
+				if err := c.Subscribe(feed); err != nil {
+					n.Printf("[ERR] [%s] can't restore subscription to %s: %v",
+						c.String(),
+						feed.Hex()[:7],
+						err)
+				}
+			}
+
+			select {
+			case <-c.closeq:
+			case <-pp.stopq:
+				return // removed
+			case <-n.closeq:
+				return // node closed
+			}
+
+			n.Debugf(ConnPin, "[%s] persistent peer disconnected", key)
+
+		} else {
+
+			n.Debugf(ConnPin, "[%s] can't connect to persistent peer: %v",
+				key,
+				err)
+
+		}
+
+		n.disconnectedPersistent(pp, err)
+
+		var tm = time.NewTimer(n.backoff(n.attemptsPersistent(pp)))
+
+		select {
+		case <-tm.C:
+		case <-pp.stopq:
+			tm.Stop()
+			return // removed
+		case <-n.closeq:
+			tm.Stop()
+			return // node closed
+		}
+
+	}
+
+}
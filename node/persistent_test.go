@@ -0,0 +1,173 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// wait for given condition
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	for tp := time.Now(); time.Since(tp) < 4*TM; {
+		if cond() == true {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("timeout")
+}
+
+func TestNode_AddPersistentPeer(t *testing.T) {
+
+	var (
+		ln = getTestNode("server")
+		cc = getTestConfigNotListen("client")
+
+		pk, _ = cipher.GenerateKeyPair()
+	)
+
+	defer ln.Close()
+
+	assertNil(t, ln.Share(pk))
+
+	cc.ReconnectMin = 50 * time.Millisecond
+	cc.ReconnectMax = 200 * time.Millisecond
+
+	var cn, err = NewNode(cc)
+	assertNil(t, err)
+	defer cn.Close()
+
+	var address = ln.TCP().Address()
+
+	assertNil(t, cn.AddPersistentPeer("tcp", address))
+
+	if cn.AddPersistentPeer("tcp", address) == nil {
+		t.Error("missing error")
+	}
+
+	if cn.AddPersistentPeer("sctp", address) == nil {
+		t.Error("missing error")
+	}
+
+	var connected = func() bool {
+		var list = cn.PersistentPeers()
+		return len(list) == 1 && list[0].Connected == true
+	}
+
+	waitFor(t, connected)
+
+	var c = cn.Connections()[0]
+
+	assertNil(t, c.Subscribe(pk))
+
+	// disconnect
+
+	for _, lc := range ln.Connections() {
+		lc.Close()
+	}
+
+	// reconnect and restore subscription
+
+	waitFor(t, func() bool {
+		var cs = cn.ConnectionsOfFeed(pk)
+		return len(cs) == 1 && cs[0] != c
+	})
+
+	var list = cn.PersistentPeers()
+
+	if len(list) != 1 {
+		t.Fatal("wrong number of persistent peers:", len(list))
+	}
+
+	if len(list[0].Feeds) != 1 || list[0].Feeds[0] != pk {
+		t.Error("wrong feeds:", list[0].Feeds)
+	}
+
+	// remove
+
+	assertNil(t, cn.RemovePersistentPeer("tcp", address))
+
+	if len(cn.PersistentPeers()) != 0 {
+		t.Error("not removed")
+	}
+
+	if cn.RemovePersistentPeer("tcp", address) == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestNode_AddPersistentPeerClose(t *testing.T) {
+
+	var cn = getTestNodeNotListen("client")
+
+	var done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		// add while closing
+		for i := 1; ; i++ {
+			var address = fmt.Sprintf("127.0.0.1:%d", i)
+			if err := cn.AddPersistentPeer("tcp", address); err != nil {
+				if err != ErrClosed {
+					t.Error("unexpected error:", err)
+				}
+				return
+			}
+		}
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	var closed = make(chan struct{})
+
+	go func() {
+		defer close(closed)
+		cn.Close()
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(4 * TM):
+		t.Fatal("Close blocks")
+	}
+
+	<-done
+
+	if err := cn.AddPersistentPeer("tcp", "localhost:1"); err != ErrClosed {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestNode_backoff(t *testing.T) {
+
+	var n = getTestNodeNotListen("test")
+	defer n.Close()
+
+	n.config.ReconnectMin = time.Second
+	n.config.ReconnectMax = 10 * time.Second
+
+	for _, tt := range []struct {
+		attempts int
+		max      time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{10, 10 * time.Second},
+	} {
+		var wait = n.backoff(tt.attempts)
+		if wait < tt.max/2 || wait > tt.max {
+			t.Errorf("%d: wrong backoff %s, want %s-%s", tt.attempts, wait,
+				tt.max/2, tt.max)
+		}
+	}
+
+}
@@ -0,0 +1,305 @@
+package node
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A PolicyRule represents rule of a Policy. If
+// the Peer is blank, then any peer can subscribe
+// to the Feed
+type PolicyRule struct {
+	Feed cipher.PubKey // allowed feed
+	Peer cipher.PubKey // allowed peer or blank for any
+}
+
+// String returns line of the rule used by policy file
+func (p PolicyRule) String() (s string) {
+	if p.Peer == (cipher.PubKey{}) {
+		return p.Feed.Hex()
+	}
+	return p.Feed.Hex() + " " + p.Peer.Hex()
+}
+
+// A Policy represents subscription policy. The
+// Policy is list of feeds remote peers can subscribe
+// to. A feed can be allowed for any peer or for
+// particular peers only. If the Config of a Node
+// has a Policy, then subscriptions that the Policy
+// doesn't allow are rejected before the
+// OnSubscribeRemote callback.
+//
+// The Policy can be stored in a file. Every line
+// of the file is a rule
+//
+//     # comment
+//     <feed>           # any peer can subscribe
+//     <feed> <peer>    # only given peer can subscribe
+//
+// The Policy is safe for concurrent use
+type Policy struct {
+	mx    sync.Mutex
+	path  string                                       // file or blank
+	feeds map[cipher.PubKey]map[cipher.PubKey]struct{} // feed -> peers
+}
+
+// NewPolicy creates empty Policy that is not
+// stored in a file. The empty Policy doesn't
+// allow any subscription
+func NewPolicy() (p *Policy) {
+	p = new(Policy)
+	p.feeds = make(map[cipher.PubKey]map[cipher.PubKey]struct{})
+	return
+}
+
+// LoadPolicy loads Policy from given file. If the
+// file doesn't exist, then it will be created.
+// All changes of the Policy are saved to the file
+func LoadPolicy(path string) (p *Policy, err error) {
+
+	p = NewPolicy()
+	p.path = path
+
+	if err = p.Reload(); err != nil {
+
+		if os.IsNotExist(err) == false {
+			return nil, err
+		}
+
+		if dir := filepath.Dir(path); dir != "" {
+			if err = os.MkdirAll(dir, 0700); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = p.save(); err != nil {
+			return nil, err
+		}
+
+	}
+
+	return
+}
+
+// Reload the Policy from its file. It can be
+// used after manual changes of the file
+func (p *Policy) Reload() (err error) {
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.path == "" {
+		return // not stored in a file
+	}
+
+	var b []byte
+	if b, err = ioutil.ReadFile(p.path); err != nil {
+		return
+	}
+
+	var (
+		feeds = make(map[cipher.PubKey]map[cipher.PubKey]struct{})
+		sc    = bufio.NewScanner(bytes.NewReader(b))
+		line  int
+	)
+
+	for sc.Scan() {
+
+		line++
+
+		var text = sc.Text()
+
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i] // skip comment
+		}
+
+		var fields = strings.Fields(text)
+
+		if len(fields) == 0 {
+			continue // empty line
+		}
+
+		if len(fields) > 2 {
+			return fmt.Errorf("%s:%d: too many fields", p.path, line)
+		}
+
+		var pr PolicyRule
+
+		if pr.Feed, err = cipher.PubKeyFromHex(fields[0]); err != nil {
+			return fmt.Errorf("%s:%d: invalid feed: %v", p.path, line, err)
+		}
+
+		if len(fields) == 2 {
+			if pr.Peer, err = cipher.PubKeyFromHex(fields[1]); err != nil {
+				return fmt.Errorf("%s:%d: invalid peer: %v", p.path, line, err)
+			}
+		}
+
+		allow(feeds, pr)
+
+	}
+
+	if err = sc.Err(); err != nil {
+		return
+	}
+
+	p.feeds = feeds
+	return
+}
+
+// add rule to given map
+func allow(feeds map[cipher.PubKey]map[cipher.PubKey]struct{}, pr PolicyRule) {
+
+	var peers, ok = feeds[pr.Feed]
+
+	if pr.Peer == (cipher.PubKey{}) {
+		feeds[pr.Feed] = nil // any peer
+		return
+	}
+
+	if ok == true && peers == nil {
+		return // already allowed for any peer
+	}
+
+	if peers == nil {
+		peers = make(map[cipher.PubKey]struct{})
+		feeds[pr.Feed] = peers
+	}
+
+	peers[pr.Peer] = struct{}{}
+}
+
+// save to file, should be called under lock
+func (p *Policy) save() (err error) {
+
+	if p.path == "" {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("# subscription policy\n")
+	buf.WriteString("#\n")
+	buf.WriteString("# <feed>           # any peer can subscribe\n")
+	buf.WriteString("# <feed> <peer>    # only given peer can subscribe\n")
+	buf.WriteString("\n")
+
+	for _, pr := range p.list() {
+		buf.WriteString(pr.String())
+		buf.WriteByte('\n')
+	}
+
+	var tmp = p.path + ".tmp"
+
+	if err = ioutil.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return
+	}
+
+	return os.Rename(tmp, p.path)
+}
+
+// Allow given rule. If the rule contains blank
+// peer, then any peer can subscribe to the feed
+func (p *Policy) Allow(pr PolicyRule) (err error) {
+
+	if pr.Feed == (cipher.PubKey{}) {
+		return ErrBlankFeed
+	}
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	allow(p.feeds, pr)
+	return p.save()
+}
+
+// Disallow given rule. If the rule contains
+// blank peer, then the feed removed from the
+// Policy. Subscriptions that already accepted
+// are not affected
+func (p *Policy) Disallow(pr PolicyRule) (err error) {
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	var peers, ok = p.feeds[pr.Feed]
+
+	if ok == false {
+		return fmt.Errorf("feed %s is not in the policy", pr.Feed.Hex()[:7])
+	}
+
+	if pr.Peer == (cipher.PubKey{}) {
+		delete(p.feeds, pr.Feed)
+		return p.save()
+	}
+
+	if _, ok = peers[pr.Peer]; ok == false {
+		return fmt.Errorf("no such rule: %s", pr.String())
+	}
+
+	if delete(peers, pr.Peer); len(peers) == 0 {
+		delete(p.feeds, pr.Feed)
+	}
+
+	return p.save()
+}
+
+// IsAllowed returns true if given peer can
+// subscribe to given feed
+func (p *Policy) IsAllowed(peer, feed cipher.PubKey) (ok bool) {
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	var peers map[cipher.PubKey]struct{}
+
+	if peers, ok = p.feeds[feed]; ok == false || peers == nil {
+		return
+	}
+
+	_, ok = peers[peer]
+	return
+}
+
+// List returns all rules of the Policy
+// ordered by feed and peer
+func (p *Policy) List() (list []PolicyRule) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	return p.list()
+}
+
+func (p *Policy) list() (list []PolicyRule) {
+
+	for feed, peers := range p.feeds {
+
+		if peers == nil {
+			list = append(list, PolicyRule{Feed: feed})
+			continue
+		}
+
+		for peer := range peers {
+			list = append(list, PolicyRule{Feed: feed, Peer: peer})
+		}
+
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if c := bytes.Compare(list[i].Feed[:], list[j].Feed[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(list[i].Peer[:], list[j].Peer[:]) < 0
+	})
+
+	return
+}
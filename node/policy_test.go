@@ -0,0 +1,99 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestPolicy_Allow(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		path = filepath.Join(dir, "policy")
+
+		feed, _ = cipher.GenerateKeyPair()
+		peer, _ = cipher.GenerateKeyPair()
+		any, _  = cipher.GenerateKeyPair()
+
+		p *Policy
+	)
+
+	p, err = LoadPolicy(path)
+	assertNil(t, err)
+
+	assertTrue(t, p.IsAllowed(peer, feed) == false, "allowed")
+
+	assertNil(t, p.Allow(PolicyRule{Feed: feed, Peer: peer}))
+
+	assertTrue(t, p.IsAllowed(peer, feed), "not allowed")
+	assertTrue(t, p.IsAllowed(any, feed) == false, "allowed")
+
+	// load from file
+
+	p, err = LoadPolicy(path)
+	assertNil(t, err)
+
+	assertTrue(t, p.IsAllowed(peer, feed), "not allowed")
+	assertTrue(t, len(p.List()) == 1, "wrong list")
+
+	// any peer
+
+	assertNil(t, p.Allow(PolicyRule{Feed: feed}))
+	assertTrue(t, p.IsAllowed(any, feed), "not allowed")
+
+	assertNil(t, p.Disallow(PolicyRule{Feed: feed}))
+	assertTrue(t, p.IsAllowed(peer, feed) == false, "allowed")
+
+	// invalid file
+
+	assertNil(t, ioutil.WriteFile(path, []byte("invalid\n"), 0600))
+
+	if _, err = LoadPolicy(path); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestConn_Subscribe_policy(t *testing.T) {
+
+	var (
+		lc = getTestConfig("server")
+		ln *Node
+		cn = getTestNodeNotListen("client")
+
+		feed, _    = cipher.GenerateKeyPair()
+		another, _ = cipher.GenerateKeyPair()
+
+		err error
+	)
+
+	defer cn.Close()
+
+	lc.Policy = NewPolicy()
+	assertNil(t, lc.Policy.Allow(PolicyRule{Feed: feed, Peer: cn.ID()}))
+
+	ln, err = NewNode(lc)
+	assertNil(t, err)
+	defer ln.Close()
+
+	assertNil(t, ln.Share(feed))
+	assertNil(t, ln.Share(another))
+
+	var c *Conn
+	c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	assertNil(t, c.Subscribe(feed))
+
+	if err = c.Subscribe(another); err == nil {
+		t.Error("subscribed")
+	}
+
+}
@@ -0,0 +1,121 @@
+package node
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A FillingProgress represents progress of
+// filling of a Root object. The Total is
+// estimated using lengths of Refs of the Root
+// and it grows while the Root is being filled.
+// The Objects includes objects found in local
+// DB and objects received from peers. The
+// SkippedObjects is estimated number of objects
+// of subtrees skipped by delta sync (see
+// skyobject.FillingProgress for details)
+type FillingProgress struct {
+	Feed  cipher.PubKey // feed of the Root
+	Nonce uint64        // head of the Root
+	Seq   uint64        // seq of the Root
+	Hash  cipher.SHA256 // hash of the Root
+
+	Objects         int // objects received or found in DB
+	Received        int // objects received from peers
+	Bytes           int // bytes received from peers
+	Total           int // estimated total number of objects
+	SkippedSubtrees int // subtrees skipped by delta sync
+	SkippedObjects  int // objects of the subtrees
+
+	Requests int      // requests in flight
+	Peers    []string // addresses of peers the Root filled from
+
+	Duration time.Duration // filling time
+}
+
+// progress of the filling Root or nil
+func (f *fillHead) progress() (fp *FillingProgress) {
+
+	if f.r.r == nil {
+		return // not filling
+	}
+
+	var r = f.r.r
+
+	fp = new(FillingProgress)
+
+	fp.Feed = r.Pub
+	fp.Nonce = r.Nonce
+	fp.Seq = r.Seq
+	fp.Hash = r.Hash
+
+	var p = f.f.Progress()
+
+	fp.Objects = p.Objects
+	fp.Received = p.Received
+	fp.Bytes = p.Bytes
+	fp.Total = p.Total
+	fp.SkippedSubtrees = p.SkippedSubtrees
+	fp.SkippedObjects = p.SkippedObjects
+
+	fp.Requests = f.requesting
+
+	for c, known := range f.cs {
+		for _, seq := range known {
+			if seq == r.Seq {
+				fp.Peers = append(fp.Peers, c.String())
+				break
+			}
+		}
+	}
+
+	fp.Duration = time.Now().Sub(f.tp)
+	return
+}
+
+func (n *Node) onFillingProgress(fp *FillingProgress) {
+
+	if ofp := n.config.OnFillingProgress; ofp != nil {
+		ofp(n, fp)
+	}
+
+}
+
+// FillingProgress returns progress of filling
+// Root of given head of given feed. It returns
+// nil if the head doesn't fill a Root
+func (n *Node) FillingProgress(
+	feed cipher.PubKey, // : feed
+	nonce uint64, //       : head
+) (
+	fp *FillingProgress, // : the progress or nil
+) {
+
+	if feed == (cipher.PubKey{}) {
+		return // blank feed
+	}
+
+	for _, nh := range n.fs.heads(feed) {
+		if hi := nh.info(); hi != nil && hi.progress != nil &&
+			hi.progress.Nonce == nonce {
+
+			return hi.progress
+		}
+	}
+
+	return
+}
+
+// Filling returns progress of all Root
+// objects the Node fills at this moment
+func (n *Node) Filling() (fps []*FillingProgress) {
+
+	for _, nh := range n.fs.heads(cipher.PubKey{}) {
+		if hi := nh.info(); hi != nil && hi.progress != nil {
+			fps = append(fps, hi.progress)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,74 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_FillingProgress(t *testing.T) {
+
+	var progress = make(chan *FillingProgress, 100)
+
+	var tp = newTestPair(t, func(_, bc *Config) {
+		bc.OnFillingProgress = func(_ *Node, fp *FillingProgress) {
+			select {
+			case progress <- fp:
+			default:
+			}
+		}
+	})
+	defer tp.Close()
+
+	var (
+		feed Feed
+		r    = &registry.Root{Nonce: 1, Pub: tp.pk}
+	)
+
+	tp.appendPosts(t, &feed, 64)
+	tp.save(t, r, feed)
+
+	var ba = tp.connect(t)
+	tp.waitFilled(t)
+
+	// objects of a response can be received by the
+	// filler after the callback, thus the last
+	// progress is used
+
+	var fp *FillingProgress
+
+	for len(progress) > 0 {
+		fp = <-progress
+	}
+
+	if fp == nil {
+		t.Fatal("OnFillingProgress not called")
+	}
+
+	if fp.Feed != tp.pk || fp.Nonce != r.Nonce || fp.Hash != r.Hash {
+		t.Error("wrong Root of progress")
+	}
+
+	if fp.Objects == 0 || fp.Received == 0 || fp.Bytes == 0 {
+		t.Error("missing objects:", fp.Objects, fp.Received, fp.Bytes)
+	}
+
+	if fp.Total < fp.Objects {
+		t.Error("wrong estimated total:", fp.Total, fp.Objects)
+	}
+
+	if len(fp.Peers) != 1 || fp.Peers[0] != ba.String() {
+		t.Error("wrong peers:", fp.Peers)
+	}
+
+	// filled
+
+	if fp = tp.b.FillingProgress(tp.pk, r.Nonce); fp != nil {
+		t.Error("unexpected progress:", fp)
+	}
+
+	if fps := tp.b.Filling(); len(fps) != 0 {
+		t.Error("unexpected fillings:", len(fps))
+	}
+
+}
@@ -0,0 +1,285 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// A Quota represents limits of messages a remote
+// peer can send per second. Zero is no limit. A peer
+// can send a burst of one second limit at once. If
+// the peer exceeds a limit, then handling of its
+// messages is delayed. If the delay is longer then
+// the MaxDelay, then the connection closed with
+// QuotaError. If there are too many delayed messages
+// of the peer, then next limited messages are dropped
+// and replied with ErrTooManyDelayed
+type Quota struct {
+	Objects  int // requested objects (RqObject, keys of RqObjects)
+	Previews int // RqPreview and RqRoots messages
+	Subs     int // Sub, Unsub and RqList messages
+	Roots    int // pushed Root objects
+
+	// MaxDelay is max delay of a message. Zero
+	// means that a peer is never disconnected
+	// and its messages are delayed only
+	MaxDelay time.Duration
+}
+
+// Validate the Quota
+func (q *Quota) Validate() (err error) {
+	if q.Objects < 0 || q.Previews < 0 || q.Subs < 0 || q.Roots < 0 {
+		return errors.New("negative limit")
+	}
+	if q.MaxDelay < 0 {
+		return errors.New("negative MaxDelay")
+	}
+	return
+}
+
+// kinds of messages of a Quota
+const (
+	quotaObjects int = iota
+	quotaPreviews
+	quotaSubs
+	quotaRoots
+
+	quotaKinds // number of kinds
+)
+
+var quotaNames = [quotaKinds]string{
+	quotaObjects:  "requested objects",
+	quotaPreviews: "previews",
+	quotaSubs:     "subscription requests",
+	quotaRoots:    "pushed Root objects",
+}
+
+func (q *Quota) limits() (limits [quotaKinds]int) {
+	limits[quotaObjects] = q.Objects
+	limits[quotaPreviews] = q.Previews
+	limits[quotaSubs] = q.Subs
+	limits[quotaRoots] = q.Roots
+	return
+}
+
+// A QuotaError is reason of closing connection
+// with a peer that exceeds a Quota
+type QuotaError struct {
+	Messages string // kind of messages
+	Limit    int    // limit per second
+}
+
+// Error implements error interface
+func (q *QuotaError) Error() string {
+	return fmt.Sprintf("quota exceeded: too many %s, limit is %d per second",
+		q.Messages,
+		q.Limit)
+}
+
+// A ConnCounters represents number of messages
+// received from a remote peer
+type ConnCounters struct {
+	Objects  uint64 // requested objects
+	Previews uint64 // RqPreview and RqRoots messages
+	Subs     uint64 // Sub, Unsub and RqList messages
+	Roots    uint64 // pushed Root objects
+
+	Delayed uint64 // messages delayed by Quota
+	Dropped uint64 // messages dropped, since too many delayed
+}
+
+// per-peer counters and limits
+type peerQuota struct {
+	buckets [quotaKinds]tokenBucket
+
+	mx       sync.Mutex
+	counters [quotaKinds]uint64
+	delayed  uint64
+	dropped  uint64
+}
+
+func (p *peerQuota) init(q *Quota) {
+	for i, limit := range q.limits() {
+		p.buckets[i].setRate(limit)
+	}
+}
+
+func (p *peerQuota) add(kind, n int) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	p.counters[kind] += uint64(n)
+}
+
+func (p *peerQuota) addDelayed() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	p.delayed++
+}
+
+func (p *peerQuota) addDropped() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	p.dropped++
+}
+
+func (p *peerQuota) get() (cc ConnCounters) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	cc.Objects = p.counters[quotaObjects]
+	cc.Previews = p.counters[quotaPreviews]
+	cc.Subs = p.counters[quotaSubs]
+	cc.Roots = p.counters[quotaRoots]
+	cc.Delayed = p.delayed
+	cc.Dropped = p.dropped
+	return
+}
+
+// kind of given message and number of
+// units of the message, or -1 if the
+// message is not limited
+func quotaKind(m msg.Msg) (kind, n int) {
+
+	switch x := m.(type) {
+	case *msg.RqObject:
+		return quotaObjects, 1
+	case *msg.RqObjects:
+		return quotaObjects, len(x.Keys)
+	case *msg.RqPreview, *msg.RqRoots:
+		return quotaPreviews, 1
+	case *msg.Sub, *msg.Unsub, *msg.RqList, *msg.RqPeers:
+		return quotaSubs, 1
+	case *msg.Root:
+		return quotaRoots, 1
+	}
+
+	return -1, 0
+}
+
+// quota counts given message and returns time
+// to delay handling of the message if the remote
+// peer exceeds a limit; it returns QuotaError if
+// the peer should be disconnected
+func (c *Conn) quota(m msg.Msg) (wait time.Duration, err error) {
+
+	var kind, n = quotaKind(m)
+
+	if kind < 0 {
+		return
+	}
+
+	c.qt.add(kind, n)
+
+	if wait = c.qt.buckets[kind].reserve(n); wait <= 0 {
+		return 0, nil
+	}
+
+	if md := c.n.config.Quota.MaxDelay; md > 0 && wait > md {
+		err = &QuotaError{
+			Messages: quotaNames[kind],
+			Limit:    c.qt.buckets[kind].getRate(),
+		}
+		return
+	}
+
+	c.qt.addDelayed()
+
+	c.n.Debugf(MsgReceivePin, "[%s] delay %T for %s", c.String(), m, wait)
+
+	return
+}
+
+// a limited message to handle after
+// its delay
+type limitedMsg struct {
+	seq uint32
+	m   msg.Msg
+	at  time.Time // handle after
+}
+
+// size of queue of limited messages
+const limitedQueueSize = 128
+
+// limit puts a limited message to the queue;
+// the messages are handled in order by the
+// limiting goroutine, thus a delay doesn't
+// block other messages and responses; if the
+// queue is full, then the message is dropped
+// and replied with ErrTooManyDelayed
+func (c *Conn) limit(seq uint32, m msg.Msg) {
+
+	var wait, err = c.quota(m)
+
+	if err != nil {
+		c.n.Printf("[ERR] [%s] %v", c.String(), err)
+		c.sendErr(seq, err)
+		go c.close(err) // can't close from the receiving goroutine
+		return
+	}
+
+	select {
+	case c.limitq <- limitedMsg{seq, m, time.Now().Add(wait)}:
+	default:
+		c.qt.addDropped()
+		c.n.Debugf(MsgReceivePin, "[%s] drop %T", c.String(), m)
+		c.sendErr(seq, ErrTooManyDelayed)
+	}
+}
+
+// handle limited messages after their delays
+func (c *Conn) limiting() {
+
+	defer c.await.Done()
+
+	var (
+		limitq = c.limitq
+		closeq = c.closeq
+	)
+
+	for {
+
+		select {
+
+		case lm := <-limitq:
+
+			if wait := time.Until(lm.at); wait > 0 {
+
+				var tm = time.NewTimer(wait)
+
+				select {
+				case <-tm.C:
+				case <-closeq:
+					tm.Stop()
+					return
+				}
+
+			}
+
+			if err := c.handleLimited(lm.seq, lm.m); err != nil {
+				c.n.Printf("[ERR] [%s] error handling messege: %v",
+					c.String(), err)
+				go c.close(err) // can't close from the limiting goroutine
+				return
+			}
+
+		case <-closeq:
+			return
+
+		}
+
+	}
+
+}
+
+// Counters returns number of messages
+// received from the remote peer
+func (c *Conn) Counters() (cc ConnCounters) {
+	return c.qt.get()
+}
@@ -0,0 +1,227 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestConn_quota(t *testing.T) {
+
+	var (
+		lc = getTestConfig("server")
+		cn = getTestNodeNotListen("client")
+	)
+
+	lc.Quota = Quota{Previews: 2, Objects: 10, MaxDelay: time.Second}
+
+	var ln, err = NewNode(lc)
+	assertNil(t, err)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	_, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	var cs = ln.Connections()
+
+	if len(cs) != 1 {
+		t.Fatal("wrong number of connections:", len(cs))
+	}
+
+	var c = cs[0] // server side
+
+	// burst
+
+	for i := 0; i < 2; i++ {
+		if wait, err := c.quota(&msg.RqPreview{}); err != nil {
+			t.Fatal(err)
+		} else if wait > 0 {
+			t.Error("delayed burst:", wait)
+		}
+	}
+
+	// delayed
+
+	var wait time.Duration
+	wait, err = c.quota(&msg.RqPreview{})
+	assertNil(t, err)
+
+	if wait < 300*time.Millisecond {
+		t.Error("not delayed:", wait)
+	}
+
+	// not limited
+
+	_, err = c.quota(&msg.Sub{})
+	assertNil(t, err)
+
+	// disconnect
+
+	var keys = make([]cipher.SHA256, 30)
+
+	switch _, err = c.quota(&msg.RqObjects{Keys: keys}); err.(type) {
+	case *QuotaError:
+	default:
+		t.Error("missing QuotaError, got", err)
+	}
+
+	var cc = c.Counters()
+
+	if cc.Previews != 3 || cc.Objects != 30 || cc.Subs != 1 {
+		t.Error("wrong counters:", cc)
+	}
+
+	if cc.Delayed != 1 {
+		t.Error("wrong number of delayed messages:", cc.Delayed)
+	}
+
+}
+
+func TestConn_quotaDisconnect(t *testing.T) {
+
+	var (
+		lc = getTestConfig("server")
+		cn = getTestNodeNotListen("client")
+
+		reason = make(chan error, 1)
+	)
+
+	lc.Quota = Quota{Subs: 1, MaxDelay: 100 * time.Millisecond}
+	lc.OnDisconnect = func(_ *Conn, err error) {
+		reason <- err
+	}
+
+	var ln, err = NewNode(lc)
+	assertNil(t, err)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	var c *Conn
+	c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	for i := 0; i < 10; i++ {
+		c.sendMsg(c.nextSeq(), 0, &msg.RqList{})
+	}
+
+	select {
+	case err = <-reason:
+		if _, ok := err.(*QuotaError); ok == false {
+			t.Error("wrong reason:", err)
+		}
+	case <-time.After(TM):
+		t.Error("not disconnected")
+	}
+
+}
+
+func TestConn_quotaResponses(t *testing.T) {
+
+	var (
+		lc = getTestConfig("server")
+		cn = getTestNodeNotListen("client")
+	)
+
+	lc.Quota = Quota{Previews: 1}
+
+	var ln, err = NewNode(lc)
+	assertNil(t, err)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	var c *Conn
+	c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	var cs = ln.Connections()
+
+	if len(cs) != 1 {
+		t.Fatal("wrong number of connections:", len(cs))
+	}
+
+	// the server delays the RqPreviews for seconds
+	for i := 0; i < 5; i++ {
+		c.sendMsg(c.nextSeq(), 0, &msg.RqPreview{})
+	}
+
+	// but handles responses for its own requests
+	var tp = time.Now()
+
+	if _, err = cs[0].RemoteFeeds(); err == nil || err.Error() != ErrNotPublic.Error() {
+		t.Error("unexpected error:", err)
+	}
+
+	if time.Since(tp) > TM {
+		t.Error("response stalled:", time.Since(tp))
+	}
+
+	if cc := cs[0].Counters(); cc.Delayed == 0 {
+		t.Error("not delayed")
+	}
+
+}
+
+func TestConn_quotaQueueFull(t *testing.T) {
+
+	var (
+		lc = getTestConfig("server")
+		cn = getTestNodeNotListen("client")
+	)
+
+	lc.Quota = Quota{Previews: 1}
+
+	var ln, err = NewNode(lc)
+	assertNil(t, err)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	var c *Conn
+	c, err = cn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+
+	var cs = ln.Connections()
+
+	if len(cs) != 1 {
+		t.Fatal("wrong number of connections:", len(cs))
+	}
+
+	// more delayed messages than the queue can hold
+	for i := 0; i < 2*limitedQueueSize; i++ {
+		c.sendMsg(c.nextSeq(), 0, &msg.RqPreview{})
+	}
+
+	waitFor(t, func() bool {
+		return cs[0].Counters().Dropped > 0
+	})
+
+	// the receiving goroutine doesn't stall
+	var tp = time.Now()
+
+	if _, err = cs[0].RemoteFeeds(); err == nil || err.Error() != ErrNotPublic.Error() {
+		t.Error("unexpected error:", err)
+	}
+
+	if time.Since(tp) > TM {
+		t.Error("response stalled:", time.Since(tp))
+	}
+
+	// the first one is not delayed, and the limiting
+	// goroutine holds one of delayed messages
+
+	var cc = cs[0].Counters()
+
+	if cc.Delayed != 2*limitedQueueSize-1 ||
+		cc.Dropped < limitedQueueSize-2 {
+
+		t.Error("wrong counters:", cc.Delayed, cc.Dropped)
+	}
+
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
@@ -31,9 +32,17 @@ func (r *rpcServer) Listen(address string) (err error) {
 
 	r.r.RegisterName("tcp", &TCPRPC{r.n})
 	r.r.RegisterName("udp", &UDPRPC{r.n})
+	r.r.RegisterName("unix", &UnixRPC{r.n})
+	r.r.RegisterName("ws", &WSRPC{r.n})
 
 	r.r.RegisterName("root", &RootRPC{r.n})
 
+	r.r.RegisterName("peers", &PeersRPC{r.n})
+	r.r.RegisterName("policy", &PolicyRPC{r.n})
+	r.r.RegisterName("bandwidth", &BandwidthRPC{r.n})
+	r.r.RegisterName("persistent", &PersistentRPC{r.n})
+	r.r.RegisterName("retention", &RetentionRPC{r.n})
+
 	if r.l, err = net.Listen("tcp", address); err != nil {
 		return
 	}
@@ -84,6 +93,12 @@ func (r *RPC) DontShare(pk cipher.PubKey, _ *struct{}) (err error) {
 	return r.n.DontShare(pk)
 }
 
+// ID is RPC method
+func (r *RPC) ID(_ struct{}, id *cipher.PubKey) (_ error) {
+	*id = r.n.ID()
+	return
+}
+
 // Feeds is RPC method
 func (r *RPC) Feeds(_ struct{}, fs *[]cipher.PubKey) (_ error) {
 	*fs = r.n.Feeds()
@@ -101,14 +116,18 @@ func (n *Node) connections() (cs []string) {
 	n.mx.Lock()
 	defer n.mx.Unlock()
 
-	cs = make([]string, 0, len(n.pc)+len(n.ic))
+	cs = make([]string, 0, len(n.pc)+len(n.ic)+len(n.uc))
 
 	for c := range n.pc {
 		cs = append(cs, c.String()+"(⌛)") // pending
 	}
 
 	for _, c := range n.ic {
-		cs = append(cs, c.String()+"(✓)") // established
+		cs = append(cs, c.String()+" "+c.PeerID().Hex()+"(✓)") // established
+	}
+
+	for c := range n.uc {
+		cs = append(cs, c.String()+" "+c.PeerID().Hex()+"(?)") // not verified
 	}
 
 	return
@@ -152,6 +171,198 @@ func (r *RPC) Stat(_ struct{}, stat *Stat) (err error) {
 	return
 }
 
+// Filling is RPC method
+func (r *RPC) Filling(_ struct{}, fps *[]*FillingProgress) (_ error) {
+	*fps = r.n.Filling()
+	return
+}
+
+// A PeersRPC represents RPC object
+// of access control of the Node
+type PeersRPC struct {
+	n *Node
+}
+
+// Allow is RPC method
+func (p *PeersRPC) Allow(rule string, _ *struct{}) (err error) {
+	return p.n.Allow(rule)
+}
+
+// Deny is RPC method
+func (p *PeersRPC) Deny(rule string, _ *struct{}) (err error) {
+	return p.n.Deny(rule)
+}
+
+// Remove is RPC method
+func (p *PeersRPC) Remove(rule string, _ *struct{}) (err error) {
+	return p.n.RemoveAccessRule(rule)
+}
+
+// AccessLists represents allow and deny
+// lists of the Node
+type AccessLists struct {
+	Allow AccessList
+	Deny  AccessList
+}
+
+// List is RPC method
+func (p *PeersRPC) List(_ struct{}, al *AccessLists) (_ error) {
+	al.Allow, al.Deny = p.n.AccessLists()
+	return
+}
+
+// A PolicyRPC represents RPC object of
+// subscription policy of the Node
+type PolicyRPC struct {
+	n *Node
+}
+
+func (p *PolicyRPC) policy() (policy *Policy, err error) {
+	if policy = p.n.config.Policy; policy == nil {
+		err = ErrNoPolicy
+	}
+	return
+}
+
+// Allow is RPC method
+func (p *PolicyRPC) Allow(pr PolicyRule, _ *struct{}) (err error) {
+	var policy *Policy
+	if policy, err = p.policy(); err != nil {
+		return
+	}
+	return policy.Allow(pr)
+}
+
+// Disallow is RPC method
+func (p *PolicyRPC) Disallow(pr PolicyRule, _ *struct{}) (err error) {
+	var policy *Policy
+	if policy, err = p.policy(); err != nil {
+		return
+	}
+	return policy.Disallow(pr)
+}
+
+// List is RPC method
+func (p *PolicyRPC) List(_ struct{}, list *[]PolicyRule) (err error) {
+	var policy *Policy
+	if policy, err = p.policy(); err != nil {
+		return
+	}
+	*list = policy.List()
+	return
+}
+
+// Reload is RPC method
+func (p *PolicyRPC) Reload(_ struct{}, _ *struct{}) (err error) {
+	var policy *Policy
+	if policy, err = p.policy(); err != nil {
+		return
+	}
+	return policy.Reload()
+}
+
+// A BandwidthRPC represents RPC object
+// of bandwidth limits of the Node
+type BandwidthRPC struct {
+	n *Node
+}
+
+// Node is RPC method
+func (b *BandwidthRPC) Node(_ struct{}, bw *Bandwidth) (_ error) {
+	*bw = b.n.Bandwidth()
+	return
+}
+
+// SetNode is RPC method
+func (b *BandwidthRPC) SetNode(bw Bandwidth, _ *struct{}) (err error) {
+	return b.n.SetBandwidth(bw)
+}
+
+// Conn is RPC method
+func (b *BandwidthRPC) Conn(_ struct{}, bw *Bandwidth) (_ error) {
+	*bw = b.n.ConnBandwidth()
+	return
+}
+
+// SetConn is RPC method
+func (b *BandwidthRPC) SetConn(bw Bandwidth, _ *struct{}) (err error) {
+	return b.n.SetConnBandwidth(bw)
+}
+
+// A ConnBandwidth represents connection
+// address and its limits
+type ConnBandwidth struct {
+	Address   string
+	Bandwidth Bandwidth
+}
+
+// SetConnection is RPC method
+func (b *BandwidthRPC) SetConnection(cb ConnBandwidth, _ *struct{}) (err error) {
+	for _, c := range b.n.Connections() {
+		if c.Address() == cb.Address {
+			return c.SetBandwidth(cb.Bandwidth)
+		}
+	}
+	return errors.New("no such connection")
+}
+
+// A PersistentRPC represents RPC
+// object of persistent peers
+type PersistentRPC struct {
+	n *Node
+}
+
+// Add is RPC method
+func (p *PersistentRPC) Add(na NetAddress, _ *struct{}) (err error) {
+	return p.n.AddPersistentPeer(na.Network, na.Address)
+}
+
+// Remove is RPC method
+func (p *PersistentRPC) Remove(na NetAddress, _ *struct{}) (err error) {
+	return p.n.RemovePersistentPeer(na.Network, na.Address)
+}
+
+// List is RPC method
+func (p *PersistentRPC) List(_ struct{}, list *[]PersistentPeer) (_ error) {
+	*list = p.n.PersistentPeers()
+	return
+}
+
+// A RetentionRPC represents RPC object
+// of retention policies of feeds
+type RetentionRPC struct {
+	n *Node
+}
+
+// A FeedRetention represents feed
+// and its retention policy
+type FeedRetention struct {
+	Feed      cipher.PubKey
+	Retention skyobject.Retention
+}
+
+// Get is RPC method
+func (r *RetentionRPC) Get(
+	feed cipher.PubKey, //         :
+	rt *skyobject.Retention, //    :
+) (
+	_ error, //                    :
+) {
+
+	*rt = r.n.c.Retention(feed)
+	return
+}
+
+// Set is RPC method
+func (r *RetentionRPC) Set(fr FeedRetention, _ *struct{}) (err error) {
+	return r.n.c.SetRetention(fr.Feed, fr.Retention)
+}
+
+// Apply is RPC method
+func (r *RetentionRPC) Apply(_ struct{}, _ *struct{}) (err error) {
+	return r.n.c.ApplyRetention()
+}
+
 // A TCPRPC represents RPC object
 // of TCP transport of the Node
 type TCPRPC struct {
@@ -298,6 +509,146 @@ func (u *UDPRPC) Address(_ struct{}, address *string) (_ error) {
 	return errors.New("to UDP transport")
 }
 
+// A UnixRPC represents RPC object
+// of Unix transport of the Node
+type UnixRPC struct {
+	n *Node
+}
+
+// Connect is RPC method
+func (u *UnixRPC) Connect(address string, _ *struct{}) (err error) {
+	_, err = u.n.Unix().Connect(address)
+	return
+}
+
+// Disconnect is RPC method
+func (u *UnixRPC) Disconnect(address string, _ *struct{}) (err error) {
+	if unix := u.n.getUnix(); unix != nil {
+		if c := unix.getConn(address); c != nil {
+			err = c.Close()
+		}
+	}
+	return
+}
+
+// Subscribe is RPC method
+func (u *UnixRPC) Subscribe(cf ConnFeed, _ *struct{}) (err error) {
+	if unix := u.n.getUnix(); unix != nil {
+		if c := unix.getConn(cf.Address); c != nil {
+			return c.Subscribe(cf.Feed)
+		}
+		return errors.New("no such connection")
+	}
+	return errors.New("no Unix transport")
+}
+
+// Unsubscribe is RPC method
+func (u *UnixRPC) Unsubscribe(cf ConnFeed, _ *struct{}) (err error) {
+	if unix := u.n.getUnix(); unix != nil {
+		if c := unix.getConn(cf.Address); c != nil {
+			c.Unsubscribe(cf.Feed)
+			return
+		}
+		return errors.New("no such connection")
+	}
+	return errors.New("no Unix transport")
+}
+
+// RemoteFeeds is RPC method
+func (u *UnixRPC) RemoteFeeds(address string, rfs *[]cipher.PubKey) (err error) {
+	if unix := u.n.getUnix(); unix != nil {
+		if c := unix.getConn(address); c != nil {
+			var rf []cipher.PubKey
+			if rf, err = c.RemoteFeeds(); err != nil {
+				return
+			}
+			*rfs = rf
+			return // nil
+		}
+		return errors.New("no such connection")
+	}
+	return errors.New("no Unix transport")
+}
+
+// Address is RPC method
+func (u *UnixRPC) Address(_ struct{}, address *string) (_ error) {
+	if unix := u.n.getUnix(); unix != nil {
+		*address = unix.Address()
+		return
+	}
+	return errors.New("no Unix transport")
+}
+
+// A WSRPC represents RPC object
+// of WebSocket transport of the Node
+type WSRPC struct {
+	n *Node
+}
+
+// Connect is RPC method
+func (w *WSRPC) Connect(address string, _ *struct{}) (err error) {
+	_, err = w.n.WS().Connect(address)
+	return
+}
+
+// Disconnect is RPC method
+func (w *WSRPC) Disconnect(address string, _ *struct{}) (err error) {
+	if ws := w.n.getWS(); ws != nil {
+		if c := ws.getConn(address); c != nil {
+			err = c.Close()
+		}
+	}
+	return
+}
+
+// Subscribe is RPC method
+func (w *WSRPC) Subscribe(cf ConnFeed, _ *struct{}) (err error) {
+	if ws := w.n.getWS(); ws != nil {
+		if c := ws.getConn(cf.Address); c != nil {
+			return c.Subscribe(cf.Feed)
+		}
+		return errors.New("no such connection")
+	}
+	return errors.New("no WebSocket transport")
+}
+
+// Unsubscribe is RPC method
+func (w *WSRPC) Unsubscribe(cf ConnFeed, _ *struct{}) (err error) {
+	if ws := w.n.getWS(); ws != nil {
+		if c := ws.getConn(cf.Address); c != nil {
+			c.Unsubscribe(cf.Feed)
+			return
+		}
+		return errors.New("no such connection")
+	}
+	return errors.New("no WebSocket transport")
+}
+
+// RemoteFeeds is RPC method
+func (w *WSRPC) RemoteFeeds(address string, rfs *[]cipher.PubKey) (err error) {
+	if ws := w.n.getWS(); ws != nil {
+		if c := ws.getConn(address); c != nil {
+			var rf []cipher.PubKey
+			if rf, err = c.RemoteFeeds(); err != nil {
+				return
+			}
+			*rfs = rf
+			return // nil
+		}
+		return errors.New("no such connection")
+	}
+	return errors.New("no WebSocket transport")
+}
+
+// Address is RPC method
+func (w *WSRPC) Address(_ struct{}, address *string) (_ error) {
+	if ws := w.n.getWS(); ws != nil {
+		*address = ws.Address()
+		return
+	}
+	return errors.New("no WebSocket transport")
+}
+
 // A RootRPC represents RPC object
 // of Root objects of the Node
 type RootRPC struct {
@@ -311,6 +662,12 @@ type RootSelector struct {
 	Seq   uint64
 }
 
+// A HeadSelector represents head selector
+type HeadSelector struct {
+	Feed  cipher.PubKey
+	Nonce uint64
+}
+
 // Show Root (RPC method)
 func (r *RootRPC) Show(rs RootSelector, z *registry.Root) (err error) {
 	var x *registry.Root
@@ -350,3 +707,18 @@ func (r *RootRPC) Last(feed cipher.PubKey, z *registry.Root) (err error) {
 	*z = *x
 	return
 }
+
+// VerifyChain of given head (RPC method)
+func (r *RootRPC) VerifyChain(
+	hs HeadSelector,
+	cr *skyobject.ChainReport,
+) (
+	err error,
+) {
+	var x *skyobject.ChainReport
+	if x, err = r.n.c.VerifyChain(hs.Feed, hs.Nonce); err != nil {
+		return
+	}
+	*cr = *x
+	return
+}
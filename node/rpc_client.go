@@ -5,6 +5,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
@@ -34,11 +35,46 @@ func (r *RPCClient) UDP() (t *RPCClientUDP) {
 	return &RPCClientUDP{r}
 }
 
+// Unix related methods
+func (r *RPCClient) Unix() (u *RPCClientUnix) {
+	return &RPCClientUnix{r}
+}
+
+// WebSocket related methods
+func (r *RPCClient) WS() (w *RPCClientWS) {
+	return &RPCClientWS{r}
+}
+
 // Root objects related methods
 func (r *RPCClient) Root() (t *RPCClientRoot) {
 	return &RPCClientRoot{r}
 }
 
+// Peers related methods (access control)
+func (r *RPCClient) Peers() (p *RPCClientPeers) {
+	return &RPCClientPeers{r}
+}
+
+// Policy related methods (subscription policy)
+func (r *RPCClient) Policy() (p *RPCClientPolicy) {
+	return &RPCClientPolicy{r}
+}
+
+// Bandwidth related methods
+func (r *RPCClient) Bandwidth() (b *RPCClientBandwidth) {
+	return &RPCClientBandwidth{r}
+}
+
+// Persistent peers related methods
+func (r *RPCClient) Persistent() (p *RPCClientPersistent) {
+	return &RPCClientPersistent{r}
+}
+
+// Retention policies related methods
+func (r *RPCClient) Retention() (p *RPCClientRetention) {
+	return &RPCClientRetention{r}
+}
+
 // NewRPCClient creates RPC client connected to RPC server with
 // given address
 func NewRPCClient(address string) (rc *RPCClient, err error) {
@@ -57,6 +93,12 @@ type RPCClientNode struct {
 	r *RPCClient
 }
 
+// ID of the Node
+func (r *RPCClientNode) ID() (id cipher.PubKey, err error) {
+	err = r.r.c.Call("node.ID", struct{}{}, &id)
+	return
+}
+
 // Share given feed
 func (r *RPCClientNode) Share(pk cipher.PubKey) (err error) {
 	return r.r.c.Call("node.Share", pk, &struct{}{})
@@ -115,6 +157,138 @@ func (r *RPCClientNode) Stat() (stat *Stat, err error) {
 	return &s, nil
 }
 
+// Filling obtains progress of all Root
+// objects the Node fills at this moment
+func (r *RPCClientNode) Filling() (fps []*FillingProgress, err error) {
+	err = r.r.c.Call("node.Filling", struct{}{}, &fps)
+	return
+}
+
+// A RPCClientPeers implements RPC
+// methods related to access control
+type RPCClientPeers struct {
+	r *RPCClient
+}
+
+// Allow peers by given rule (public key or CIDR)
+func (r *RPCClientPeers) Allow(rule string) (err error) {
+	return r.r.c.Call("peers.Allow", rule, &struct{}{})
+}
+
+// Deny peers by given rule (public key or CIDR)
+func (r *RPCClientPeers) Deny(rule string) (err error) {
+	return r.r.c.Call("peers.Deny", rule, &struct{}{})
+}
+
+// Remove given rule from allow and deny lists
+func (r *RPCClientPeers) Remove(rule string) (err error) {
+	return r.r.c.Call("peers.Remove", rule, &struct{}{})
+}
+
+// List returns allow and deny lists
+func (r *RPCClientPeers) List() (allow, deny AccessList, err error) {
+	var al AccessLists
+	if err = r.r.c.Call("peers.List", struct{}{}, &al); err != nil {
+		return
+	}
+	return al.Allow, al.Deny, nil
+}
+
+// A RPCClientPolicy implements RPC methods
+// related to subscription policy
+type RPCClientPolicy struct {
+	r *RPCClient
+}
+
+// Allow given feed for given peer, use blank
+// peer to allow the feed for any peer
+func (r *RPCClientPolicy) Allow(feed, peer cipher.PubKey) (err error) {
+	return r.r.c.Call("policy.Allow", PolicyRule{feed, peer}, &struct{}{})
+}
+
+// Disallow given feed for given peer, use blank
+// peer to remove the feed from the policy
+func (r *RPCClientPolicy) Disallow(feed, peer cipher.PubKey) (err error) {
+	return r.r.c.Call("policy.Disallow", PolicyRule{feed, peer}, &struct{}{})
+}
+
+// List rules of the policy
+func (r *RPCClientPolicy) List() (list []PolicyRule, err error) {
+	err = r.r.c.Call("policy.List", struct{}{}, &list)
+	return
+}
+
+// Reload the policy from its file
+func (r *RPCClientPolicy) Reload() (err error) {
+	return r.r.c.Call("policy.Reload", struct{}{}, &struct{}{})
+}
+
+// A RPCClientBandwidth implements RPC
+// methods related to bandwidth limits
+type RPCClientBandwidth struct {
+	r *RPCClient
+}
+
+// Node returns limits of all connections together
+func (r *RPCClientBandwidth) Node() (bw Bandwidth, err error) {
+	err = r.r.c.Call("bandwidth.Node", struct{}{}, &bw)
+	return
+}
+
+// SetNode changes limits of all connections together
+func (r *RPCClientBandwidth) SetNode(bw Bandwidth) (err error) {
+	return r.r.c.Call("bandwidth.SetNode", bw, &struct{}{})
+}
+
+// Conn returns default limits of a connection
+func (r *RPCClientBandwidth) Conn() (bw Bandwidth, err error) {
+	err = r.r.c.Call("bandwidth.Conn", struct{}{}, &bw)
+	return
+}
+
+// SetConn changes limits of all connections
+func (r *RPCClientBandwidth) SetConn(bw Bandwidth) (err error) {
+	return r.r.c.Call("bandwidth.SetConn", bw, &struct{}{})
+}
+
+// SetConnection changes limits of connection
+// with given remote address
+func (r *RPCClientBandwidth) SetConnection(
+	address string, //  :
+	bw Bandwidth, //    :
+) (
+	err error, //       :
+) {
+
+	return r.r.c.Call("bandwidth.SetConnection",
+		ConnBandwidth{address, bw},
+		&struct{}{})
+}
+
+// A RPCClientPersistent implements RPC
+// methods related to persistent peers
+type RPCClientPersistent struct {
+	r *RPCClient
+}
+
+// Add persistent peer, the network is "tcp", "udp", "unix" or "ws"
+func (r *RPCClientPersistent) Add(network, address string) (err error) {
+	return r.r.c.Call("persistent.Add", NetAddress{network, address},
+		&struct{}{})
+}
+
+// Remove persistent peer
+func (r *RPCClientPersistent) Remove(network, address string) (err error) {
+	return r.r.c.Call("persistent.Remove", NetAddress{network, address},
+		&struct{}{})
+}
+
+// List of persistent peers
+func (r *RPCClientPersistent) List() (list []PersistentPeer, err error) {
+	err = r.r.c.Call("persistent.List", struct{}{}, &list)
+	return
+}
+
 // A RPCClientTCP implements RPC
 // methods related to TCP transport
 type RPCClientTCP struct {
@@ -211,6 +385,102 @@ func (r *RPCClientUDP) Address() (address string, err error) {
 	return
 }
 
+// A RPCClientUnix implements RPC
+// methods related to Unix transport
+type RPCClientUnix struct {
+	r *RPCClient
+}
+
+// Connect to peer
+func (r *RPCClientUnix) Connect(address string) (err error) {
+	return r.r.c.Call("unix.Connect", address, &struct{}{})
+}
+
+// Disconnect from peer
+func (r *RPCClientUnix) Disconnect(address string) (err error) {
+	return r.r.c.Call("unix.Disconnect", address, &struct{}{})
+}
+
+// Subscribe to feed of peer
+func (r *RPCClientUnix) Subscribe(address string, pk cipher.PubKey) (err error) {
+	return r.r.c.Call("unix.Subscribe", ConnFeed{address, pk}, &struct{}{})
+}
+
+// Unsubscribe from feed of peer
+func (r *RPCClientUnix) Unsubscribe(
+	address string,
+	pk cipher.PubKey,
+) (
+	err error,
+) {
+	return r.r.c.Call("unix.Unsubscribe", ConnFeed{address, pk}, &struct{}{})
+}
+
+// RemoteFeeds of peer
+func (r *RPCClientUnix) RemoteFeeds(
+	address string, //      :
+) (
+	rfs []cipher.PubKey, // :
+	err error, //           :
+) {
+	err = r.r.c.Call("unix.RemoteFeeds", address, &rfs)
+	return
+}
+
+// Address of Unix listener
+func (r *RPCClientUnix) Address() (address string, err error) {
+	err = r.r.c.Call("unix.Address", struct{}{}, &address)
+	return
+}
+
+// A RPCClientWS implements RPC
+// methods related to WebSocket transport
+type RPCClientWS struct {
+	r *RPCClient
+}
+
+// Connect to peer
+func (r *RPCClientWS) Connect(address string) (err error) {
+	return r.r.c.Call("ws.Connect", address, &struct{}{})
+}
+
+// Disconnect from peer
+func (r *RPCClientWS) Disconnect(address string) (err error) {
+	return r.r.c.Call("ws.Disconnect", address, &struct{}{})
+}
+
+// Subscribe to feed of peer
+func (r *RPCClientWS) Subscribe(address string, pk cipher.PubKey) (err error) {
+	return r.r.c.Call("ws.Subscribe", ConnFeed{address, pk}, &struct{}{})
+}
+
+// Unsubscribe from feed of peer
+func (r *RPCClientWS) Unsubscribe(
+	address string,
+	pk cipher.PubKey,
+) (
+	err error,
+) {
+	return r.r.c.Call("ws.Unsubscribe", ConnFeed{address, pk}, &struct{}{})
+}
+
+// RemoteFeeds of peer
+func (r *RPCClientWS) RemoteFeeds(
+	address string, //      :
+) (
+	rfs []cipher.PubKey, // :
+	err error, //           :
+) {
+	err = r.r.c.Call("ws.RemoteFeeds", address, &rfs)
+	return
+}
+
+// Address of WebSocket listener
+func (r *RPCClientWS) Address() (address string, err error) {
+	err = r.r.c.Call("ws.Address", struct{}{}, &address)
+	return
+}
+
 // A RPCClientRoot implements RPC
 // methods related to Root objects
 type RPCClientRoot struct {
@@ -262,3 +532,56 @@ func (r *RPCClientRoot) Last(
 	}
 	return &x, nil
 }
+
+// VerifyChain of given head
+func (r *RPCClientRoot) VerifyChain(
+	feed cipher.PubKey,
+	nonce uint64,
+) (
+	cr *skyobject.ChainReport,
+	err error,
+) {
+
+	var x skyobject.ChainReport
+	err = r.r.c.Call("root.VerifyChain", HeadSelector{feed, nonce}, &x)
+	if err != nil {
+		return
+	}
+	return &x, nil
+}
+
+// A RPCClientRetention implements RPC
+// methods related to retention policies
+type RPCClientRetention struct {
+	r *RPCClient
+}
+
+// Get retention policy of given feed
+func (r *RPCClientRetention) Get(
+	feed cipher.PubKey, //        :
+) (
+	rt skyobject.Retention, //    :
+	err error, //                 :
+) {
+
+	err = r.r.c.Call("retention.Get", feed, &rt)
+	return
+}
+
+// Set retention policy of given feed
+func (r *RPCClientRetention) Set(
+	feed cipher.PubKey, //        :
+	rt skyobject.Retention, //    :
+) (
+	err error, //                 :
+) {
+
+	return r.r.c.Call("retention.Set",
+		FeedRetention{feed, rt},
+		&struct{}{})
+}
+
+// Apply retention policies now
+func (r *RPCClientRetention) Apply() (err error) {
+	return r.r.c.Call("retention.Apply", struct{}{}, &struct{}{})
+}
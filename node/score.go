@@ -0,0 +1,139 @@
+package node
+
+import (
+	"sync"
+	"time"
+
+	"github.com/skycoin/cxo/skyobject/statutil"
+)
+
+// latency of a peer without responses yet, it's
+// small enough to try the peer soon
+const scoreUnknownLatency = 50 * time.Millisecond
+
+// every failed request makes a peer this
+// times slower than its average latency
+const scoreFailurePenalty = 4
+
+// A PeerScore represents quality of a remote peer
+// as a source of objects during filling. The Node
+// requests objects from peers with lesser Score
+// first (see also Config.HedgeDelay)
+type PeerScore struct {
+	Latency     time.Duration // average response time
+	FailureRate float64       // average rate of failed requests, [0; 1]
+
+	Requests uint64 // requests sent to the peer
+	Failures uint64 // failed requests
+	Hedged   uint64 // requests duplicated to the peer
+
+	// Score is expected response time,
+	// lesser is better
+	Score time.Duration
+}
+
+// per-peer score
+type peerScore struct {
+	mx sync.Mutex
+
+	latency *statutil.Duration
+	failure *statutil.Float
+
+	requests uint64
+	failures uint64
+	hedged   uint64
+	answered bool // has a response
+}
+
+func (p *peerScore) init(samples int) {
+	if samples <= 0 {
+		samples = 1
+	}
+	p.latency = statutil.NewDuration(samples)
+	p.failure = statutil.NewFloat(samples)
+}
+
+// successful response with given latency
+func (p *peerScore) success(latency time.Duration) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	p.requests++
+	p.answered = true
+	p.latency.Add(latency)
+	p.failure.Add(0)
+}
+
+// failed request
+func (p *peerScore) fail() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	p.requests++
+	p.failures++
+	p.failure.Add(1)
+}
+
+// a request duplicated to the peer
+func (p *peerScore) hedge() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	p.hedged++
+}
+
+// average latency of the peer or zero
+// if there are not responses yet
+func (p *peerScore) avgLatency() (latency time.Duration) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.answered == true {
+		latency = p.latency.Value()
+	}
+	return
+}
+
+// value of the score, lesser is better
+func (p *peerScore) value() time.Duration {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	return p.score()
+}
+
+// under lock
+func (p *peerScore) score() time.Duration {
+
+	var latency = scoreUnknownLatency
+
+	if p.answered == true {
+		latency = p.latency.Value()
+	}
+
+	var penalty = 1 + scoreFailurePenalty*p.failure.Value()
+
+	return time.Duration(float64(latency) * penalty)
+}
+
+func (p *peerScore) get() (ps PeerScore) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.answered == true {
+		ps.Latency = p.latency.Value()
+	}
+
+	ps.FailureRate = p.failure.Value()
+	ps.Requests = p.requests
+	ps.Failures = p.failures
+	ps.Hedged = p.hedged
+	ps.Score = p.score()
+	return
+}
+
+// Score returns quality of the remote
+// peer as a source of objects
+func (c *Conn) Score() (ps PeerScore) {
+	return c.sc.get()
+}
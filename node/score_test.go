@@ -0,0 +1,174 @@
+package node
+
+import (
+	"container/list"
+	"testing"
+	"time"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func Test_peerScore(t *testing.T) {
+
+	var p peerScore
+	p.init(5)
+
+	if p.value() != scoreUnknownLatency {
+		t.Error("wrong score of unknown peer:", p.value())
+	}
+
+	p.success(10 * time.Millisecond)
+
+	if p.value() != 10*time.Millisecond {
+		t.Error("wrong score:", p.value())
+	}
+
+	p.fail()
+
+	var ps = p.get()
+
+	if ps.Requests != 2 || ps.Failures != 1 {
+		t.Error("wrong counters:", ps.Requests, ps.Failures)
+	}
+
+	if ps.Score <= ps.Latency {
+		t.Error("failure doesn't increase score:", ps.Score, ps.Latency)
+	}
+
+}
+
+func testScoredConn(latency time.Duration, failures int) (c *Conn) {
+	c = new(Conn)
+	c.sc.init(5)
+	c.sc.success(latency)
+	for i := 0; i < failures; i++ {
+		c.sc.fail()
+	}
+	return
+}
+
+func Test_fillHead_bestConn(t *testing.T) {
+
+	var (
+		fast    = testScoredConn(10*time.Millisecond, 0)
+		slow    = testScoredConn(100*time.Millisecond, 0)
+		failing = testScoredConn(5*time.Millisecond, 4)
+		removed = testScoredConn(1*time.Millisecond, 0)
+
+		f = fillHead{cs: make(knownRoots), fc: list.New()}
+	)
+
+	for _, c := range []*Conn{slow, removed, failing, fast} {
+		f.fc.PushBack(c)
+		if c != removed {
+			f.cs.addKnown(c, 0)
+		}
+	}
+
+	for i, want := range []*Conn{fast, failing, slow, nil} {
+		if got := f.bestConn(nil); got != want {
+			t.Errorf("%d: wrong connection", i)
+		}
+	}
+
+	f.fc.PushBack(fast)
+	f.fc.PushBack(slow)
+
+	var c = f.bestConn(func(c *Conn) bool { return c != fast })
+
+	if c != slow {
+		t.Error("filter not used")
+	}
+
+}
+
+func TestNode_fillFromManyPeers(t *testing.T) {
+
+	var tp = newTestPair(t, func(_, bc *Config) {
+		bc.TCP.Listen = "127.0.0.1:8088"
+	})
+	defer tp.Close()
+
+	var (
+		filled = make(chan *registry.Root, 1)
+
+		cc = getTestConfigNotListen("c")
+
+		c   *Node
+		err error
+	)
+
+	cc.OnRootFilled = func(_ *Node, r *registry.Root) { filled <- r }
+	cc.HedgeDelay = time.Microsecond // duplicate requests
+
+	c, err = NewNode(cc)
+	assertNil(t, err)
+	defer c.Close()
+
+	// create Root with many objects
+
+	var feed Feed
+
+	tp.appendPosts(t, &feed, 64)
+	tp.save(t, &registry.Root{Nonce: 1, Pub: tp.pk}, feed)
+
+	// the b gets the Root from the a
+
+	tp.connect(t)
+	tp.waitFilled(t)
+
+	// the c gets the Root from both
+
+	for _, address := range []string{tp.a.TCP().Address(), tp.b.TCP().Address()} {
+		var cn *Conn
+		cn, err = c.TCP().Connect(address)
+		assertNil(t, err)
+		assertNil(t, cn.Subscribe(tp.pk))
+	}
+
+	select {
+	case <-filled:
+	case <-time.After(10 * TM):
+		t.Fatal("slow")
+	}
+
+	var requests uint64
+
+	for _, cs := range c.Stat().Connections {
+		if cs.Score.Requests > 0 && cs.Score.Latency == 0 {
+			t.Error("missing latency:", cs.Address)
+		}
+		requests += cs.Score.Requests
+	}
+
+	if requests == 0 {
+		t.Error("missing scores")
+	}
+
+}
+
+func TestNode_rollAvgSamples(t *testing.T) {
+
+	var conf = getTestConfigNotListen("test")
+	conf.Config.RollAvgSamples = 5
+
+	var n, err = NewNode(conf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer n.Close()
+
+	var p peerScore
+	p.init(n.rollAvgSamples)
+
+	p.success(10 * time.Millisecond)
+	p.success(30 * time.Millisecond)
+
+	// the latency should be averaged, not the last one
+	if p.value() >= 30*time.Millisecond {
+		t.Error("score uses the last sample only:", p.value())
+	}
+
+}
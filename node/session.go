@@ -0,0 +1,143 @@
+package node
+
+import (
+	"crypto/aes"
+	gocipher "crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A session represents encryption layer of a Conn.
+// The session uses AES-256-GCM. Every encrypted
+// messege is
+//
+//     [ 8 counter ][ encrypted messege + tag ]
+//
+// where the counter is used as nonce and should
+// be strictly increasing. Thus, it's impossible
+// to replay or reorder messages. There are two
+// keys: one for outgoing messages and one for
+// incoming messages
+type session struct {
+	smx  sync.Mutex    // lock for sending (keep order)
+	seal gocipher.AEAD // outgoing
+	sn   uint64        // last counter of outgoing messages
+	open gocipher.AEAD // incoming
+	rn   uint64        // last counter of incoming messages
+}
+
+// derive key for given direction from shared secret
+// and challenges of the handshake
+func sessionKey(
+	secret []byte, //          : ECDH shared secret
+	syn cipher.SHA256, //      : challenge of the initiator
+	ack cipher.SHA256, //      : challenge of the acceptor
+	sender cipher.PubKey, //   : id of sender
+) (
+	key cipher.SHA256, //      : AES-256 key
+) {
+
+	var b = make([]byte, 0, len(secret)+2*len(cipher.SHA256{})+
+		len(cipher.PubKey{}))
+
+	b = append(b, secret...)
+	b = append(b, syn[:]...)
+	b = append(b, ack[:]...)
+	b = append(b, sender[:]...)
+
+	return cipher.SumSHA256(b)
+}
+
+func newAEAD(key cipher.SHA256) (aead gocipher.AEAD, err error) {
+
+	var block gocipher.Block
+	if block, err = aes.NewCipher(key[:]); err != nil {
+		return
+	}
+
+	return gocipher.NewGCM(block)
+}
+
+// newSession creates session for a Conn. The peer
+// is NodeID of remote peer, the syn and the ack are
+// challenges of the handshake
+func (c *Conn) newSession(
+	peer cipher.PubKey,
+	syn cipher.SHA256,
+	ack cipher.SHA256,
+) (
+	s *session,
+	err error,
+) {
+
+	var secret = cipher.ECDH(peer, c.n.idsk)
+
+	s = new(session)
+
+	var (
+		sk = sessionKey(secret, syn, ack, c.n.idpk) // send
+		rk = sessionKey(secret, syn, ack, peer)     // receive
+	)
+
+	if s.seal, err = newAEAD(sk); err != nil {
+		return
+	}
+
+	if s.open, err = newAEAD(rk); err != nil {
+		return
+	}
+
+	return
+}
+
+func (s *session) nonce(counter uint64) (nonce []byte) {
+	nonce = make([]byte, s.seal.NonceSize())
+	binary.LittleEndian.PutUint64(nonce, counter)
+	return
+}
+
+// encrypt given messege, should be called
+// under the smx lock
+func (s *session) encrypt(raw []byte) (er []byte) {
+
+	s.sn++
+
+	er = make([]byte, 8, 8+len(raw)+s.seal.Overhead())
+	binary.LittleEndian.PutUint64(er, s.sn)
+
+	return s.seal.Seal(er, s.nonce(s.sn), raw, er[:8])
+}
+
+// decrypt given messege, should be called from
+// one goroutine (the receiving goroutine)
+func (s *session) decrypt(er []byte) (raw []byte, err error) {
+
+	if len(er) < 8+s.open.Overhead() {
+		return nil, errors.New("invalid encrypted messege: too short")
+	}
+
+	var counter = binary.LittleEndian.Uint64(er)
+
+	if counter <= s.rn {
+		return nil, ErrReplay
+	}
+
+	if raw, err = s.open.Open(nil, s.nonce(counter), er[8:], er[:8]); err != nil {
+		return
+	}
+
+	s.rn = counter
+	return
+}
+
+// IsEncrypted returns true if the Conn is encrypted
+func (c *Conn) IsEncrypted() bool {
+	return c.ss != nil
+}
+
+func (c *Conn) encryption() (e EncryptionMode) {
+	return c.n.netConfig(c.network).Encryption
+}
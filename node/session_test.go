@@ -0,0 +1,146 @@
+package node
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func Test_session(t *testing.T) {
+
+	var (
+		an = getTestNodeNotListen("a")
+		bn = getTestNodeNotListen("b")
+
+		ac = &Conn{n: an}
+		bc = &Conn{n: bn}
+
+		syn, ack = newChallenge(), newChallenge()
+	)
+
+	defer an.Close()
+	defer bn.Close()
+
+	var as, bs *session
+	var err error
+
+	as, err = ac.newSession(bn.ID(), syn, ack)
+	assertNil(t, err)
+
+	bs, err = bc.newSession(an.ID(), syn, ack)
+	assertNil(t, err)
+
+	var (
+		raw = []byte("hello")
+		er  = as.encrypt(raw)
+		dr  []byte
+	)
+
+	if bytes.Contains(er, raw) == true {
+		t.Error("not encrypted")
+	}
+
+	dr, err = bs.decrypt(er)
+	assertNil(t, err)
+
+	if bytes.Equal(dr, raw) == false {
+		t.Error("wrong decrypted messege")
+	}
+
+	// replay
+
+	if _, err = bs.decrypt(er); err != ErrReplay {
+		t.Error("missing ErrReplay:", err)
+	}
+
+	// keys of the directions are different
+
+	if _, err = as.decrypt(as.encrypt(raw)); err == nil {
+		t.Error("the same key for both directions")
+	}
+
+	// modified
+
+	er = as.encrypt(raw)
+	er[len(er)-1]++
+
+	if _, err = bs.decrypt(er); err == nil {
+		t.Error("modified messege decrypted")
+	}
+
+}
+
+func Test_encryption(t *testing.T) {
+
+	var modes = []EncryptionMode{
+		EncryptionOff,
+		EncryptionOptional,
+		EncryptionRequired,
+	}
+
+	for _, lm := range modes {
+
+		for _, cm := range modes {
+
+			var (
+				lc = getTestConfig("server")
+				cc = getTestConfigNotListen("client")
+			)
+
+			lc.TCP.Encryption = lm
+			cc.TCP.Encryption = cm
+
+			var ln, err = NewNode(lc)
+			assertNil(t, err)
+
+			var cn *Node
+			if cn, err = NewNode(cc); err != nil {
+				ln.Close()
+				t.Fatal(err)
+			}
+
+			var (
+				c       *Conn
+				encrypt bool
+			)
+
+			c, err = cn.TCP().Connect(ln.TCP().Address())
+
+			switch {
+			case lm == EncryptionOff && cm == EncryptionRequired,
+				lm == EncryptionRequired && cm == EncryptionOff:
+
+				if err == nil {
+					t.Errorf("connected (%s, %s)", lm.String(), cm.String())
+				}
+
+			default:
+
+				encrypt = lm != EncryptionOff && cm != EncryptionOff
+
+				if err != nil {
+					t.Errorf("can't connect (%s, %s): %v",
+						lm.String(),
+						cm.String(),
+						err)
+				} else if c.IsEncrypted() != encrypt {
+					t.Errorf("wrong encryption (%s, %s)",
+						lm.String(),
+						cm.String())
+				} else {
+					var pk, _ = cipher.GenerateKeyPair()
+					assertNil(t, ln.Share(pk))
+					assertNil(t, c.Subscribe(pk)) // request - response
+				}
+
+			}
+
+			cn.Close()
+			ln.Close()
+
+		}
+
+	}
+
+}
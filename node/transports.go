@@ -1,9 +1,14 @@
 package node
 
 import (
+	"fmt"
+	"net"
+	"net/http"
 	"sync"
 	"time"
 
+	"github.com/gorilla/websocket"
+
 	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/net/factory"
@@ -91,6 +96,16 @@ func (t *TCP) addAcceptedConnection(c *Conn) {
 	t.cs[c.Address()] = c
 }
 
+// remove closed connection
+func (t *TCP) delConn(c *Conn) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if t.cs[c.Address()] == c {
+		delete(t.cs, c.Address())
+	}
+}
+
 // Connect to given TCP address. The method blocks. If connection
 // with given address already exists, then the Connect returns this
 // existing connection.
@@ -107,11 +122,12 @@ func (t *TCP) Connect(address string) (c *Conn, err error) {
 	var fc *factory.Connection
 
 	if fc, err = t.TCPFactory.Connect(address); err != nil {
+		t.n.rememberFailure("tcp", address)
 		return
 	}
 
-	if c, err = t.n.wrapConnection(fc, false); err != nil {
-		return
+	if c, err = t.n.wrapConnection(fc, "tcp", false); err != nil {
+		return // not a network failure
 	}
 
 	t.cs[c.Address()] = c // put to the map
@@ -190,6 +206,8 @@ func (t *TCP) findServiceNodes(resp *discovery.QueryResp) {
 				err    error
 			)
 
+			t.n.rememberDiscovered("tcp", ni.Address, si.PubKey)
+
 			if yep == false {
 				if c, err = t.Connect(ni.Address); err != nil { // block
 					t.n.Debugf(DiscoveryPin, "can't Connect to tcp://%q: %v",
@@ -364,6 +382,16 @@ func (u *UDP) addAcceptedConnection(c *Conn) {
 	u.cs[c.Address()] = c
 }
 
+// remove closed connection
+func (u *UDP) delConn(c *Conn) {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+
+	if u.cs[c.Address()] == c {
+		delete(u.cs, c.Address())
+	}
+}
+
 // Connect to given UDP address. If connection with given
 // address already exists, then the Connect returns this
 // existing connection.
@@ -380,11 +408,12 @@ func (u *UDP) Connect(address string) (c *Conn, err error) {
 	var fc *factory.Connection
 
 	if fc, err = u.UDPFactory.Connect(address); err != nil {
+		u.n.rememberFailure("udp", address)
 		return
 	}
 
-	if c, err = u.n.wrapConnection(fc, false); err != nil {
-		return
+	if c, err = u.n.wrapConnection(fc, "udp", false); err != nil {
+		return // not a network failure
 	}
 
 	u.cs[c.Address()] = c // put to the map
@@ -458,6 +487,8 @@ func (u *UDP) findServiceNodes(resp *discovery.QueryResp) {
 				err    error
 			)
 
+			u.n.rememberDiscovered("udp", ni.Address, si.PubKey)
+
 			if yep == false {
 				if c, err = u.Connect(ni.Address); err != nil { // block
 					u.n.Debugf(DiscoveryPin, "can't Connect to udp://%q: %v",
@@ -555,3 +586,383 @@ func (u *UDP) connections() (cs []string) {
 
 	return
 }
+
+// A Unix represents Unix domain socket
+// transport of the Node. The Unix used
+// to listen and connect to local peers
+type Unix struct {
+	// back reference
+	n *Node
+
+	mx sync.Mutex
+
+	l net.Listener // listener or nil
+
+	address     string
+	isListening bool
+
+	seq uint64 // sequence number of accepted connections
+
+	cs map[string]*Conn // connections
+
+	await sync.WaitGroup // accepting loop
+}
+
+func newUnix(n *Node) (u *Unix) {
+
+	u = new(Unix)
+
+	u.n = n
+	u.cs = make(map[string]*Conn)
+
+	return
+}
+
+func (u *Unix) getConn(address string) (c *Conn) {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+
+	return u.cs[address]
+}
+
+// Listen on given path. It's possible to listen
+// only once. If the path is a socket nothing is
+// listening on (e.g. after a crash), then the
+// socket file removed
+func (u *Unix) Listen(address string) (err error) {
+
+	u.mx.Lock()
+	defer u.mx.Unlock()
+
+	if u.isListening == true {
+		return ErrAlreadyListen
+	}
+
+	if u.l, err = net.Listen("unix", address); err != nil {
+
+		if removeStaleSocket(address) == false {
+			return
+		}
+
+		if u.l, err = net.Listen("unix", address); err != nil {
+			return
+		}
+
+	}
+
+	u.address = address
+	u.isListening = true
+
+	u.await.Add(1)
+	go u.accepting(u.l)
+
+	return
+}
+
+func (u *Unix) accepting(l net.Listener) {
+
+	defer u.await.Done()
+
+	for {
+
+		var nc, err = l.Accept()
+
+		if err != nil {
+			return // closed
+		}
+
+		u.mx.Lock()
+		u.seq++
+		var remote = fmt.Sprintf("%s#%d", u.address, u.seq)
+		u.mx.Unlock()
+
+		go u.n.acceptUnixConnection(newUnixConnection(nc, remote))
+
+	}
+
+}
+
+// Address returns istening address as it
+// passed to the Listen method. The address
+// is blank string if the Unix is not listening
+func (u *Unix) Address() string {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+
+	return u.address
+}
+
+func (u *Unix) addAcceptedConnection(c *Conn) {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+
+	u.cs[c.Address()] = c
+}
+
+// remove closed connection
+func (u *Unix) delConn(c *Conn) {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+
+	if u.cs[c.Address()] == c {
+		delete(u.cs, c.Address())
+	}
+}
+
+// Connect to given Unix socket. The method blocks. If
+// connection with given address already exists, then
+// the Connect returns this existing connection.
+func (u *Unix) Connect(address string) (c *Conn, err error) {
+
+	if c = u.getConn(address); c != nil {
+		return // already have
+	}
+
+	var nc net.Conn
+
+	if nc, err = net.Dial("unix", address); err != nil {
+		u.n.rememberFailure("unix", address)
+		return
+	}
+
+	var uc = newUnixConnection(nc, address)
+
+	if c, err = u.n.wrapConnection(uc, "unix", false); err != nil {
+		return // not a network failure
+	}
+
+	u.mx.Lock()
+	defer u.mx.Unlock()
+
+	u.cs[c.Address()] = c // put to the map
+	return
+}
+
+// Close the Unix. The Close closes listener
+// and all connections of the Unix
+func (u *Unix) Close() (err error) {
+
+	u.mx.Lock()
+
+	if u.l != nil {
+		err = u.l.Close()
+	}
+
+	for _, c := range u.cs {
+		c.Connection.Close() // underlying connection
+	}
+
+	u.mx.Unlock()
+
+	u.await.Wait()
+	return
+}
+
+// connections strings
+func (u *Unix) connections() (cs []string) {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+
+	cs = make([]string, 0, len(u.cs))
+
+	for _, c := range u.cs {
+		cs = append(cs, c.String())
+	}
+
+	return
+}
+
+// A WS represents WebSocket transport of
+// the Node. The WS used to listen and connect
+// through HTTP reverse proxies and by clients
+// that can use WebSockets only. The WS is
+// http.Handler and can be used with any HTTP
+// server, without the Listen
+type WS struct {
+	// back reference
+	n *Node
+
+	mx sync.Mutex
+
+	up  websocket.Upgrader
+	srv *http.Server // server or nil
+
+	address     string
+	isListening bool
+
+	cs map[string]*Conn // connections
+
+	await sync.WaitGroup // serving
+}
+
+func newWS(n *Node) (w *WS) {
+
+	w = new(WS)
+
+	w.n = n
+	w.cs = make(map[string]*Conn)
+
+	w.up.ReadBufferSize = 4096
+	w.up.WriteBufferSize = 4096
+
+	// light clients can connect from browsers
+	// of allowed origins (see Config.WSOrigins)
+	w.up.CheckOrigin = w.checkOrigin
+
+	return
+}
+
+func (w *WS) getConn(address string) (c *Conn) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	return w.cs[address]
+}
+
+// Listen on given address. It's possible to listen
+// only once. The WS accepts WebSocket connections
+// on any path
+func (w *WS) Listen(address string) (err error) {
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.isListening == true {
+		return ErrAlreadyListen
+	}
+
+	var l net.Listener
+
+	if l, err = net.Listen("tcp", address); err != nil {
+		return
+	}
+
+	w.srv = &http.Server{Handler: w}
+
+	w.address = address
+	w.isListening = true
+
+	w.await.Add(1)
+	go func(srv *http.Server) {
+		defer w.await.Done()
+		srv.Serve(l) // ignore error
+	}(w.srv)
+
+	return
+}
+
+// ServeHTTP implements http.Handler interface.
+// The ServeHTTP upgrades request to WebSocket
+// connection and blocks until the handshake
+// is complete
+func (w *WS) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+
+	var wc, err = w.up.Upgrade(rw, r, nil)
+
+	if err != nil {
+		w.n.Debugf(NewInConnPin, "(WS) can't upgrade %s: %v",
+			r.RemoteAddr,
+			err)
+		return // the Upgrade replies with error
+	}
+
+	w.n.accept(newWSConnection(wc, r.RemoteAddr), "ws")
+}
+
+// Address returns istening address as it
+// passed to the Listen method. The address
+// is blank string if the WS is not listening
+func (w *WS) Address() string {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	return w.address
+}
+
+func (w *WS) addAcceptedConnection(c *Conn) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	w.cs[c.Address()] = c
+}
+
+// remove closed connection
+func (w *WS) delConn(c *Conn) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.cs[c.Address()] == c {
+		delete(w.cs, c.Address())
+	}
+}
+
+// Connect to given address. The address is URL like
+// "ws://example.com/cxo" or "wss://example.com/cxo",
+// or host:port that means "ws://host:port/". The method
+// blocks. If connection with given address already
+// exists, then the Connect returns this existing
+// connection.
+func (w *WS) Connect(address string) (c *Conn, err error) {
+
+	if c = w.getConn(address); c != nil {
+		return // already have
+	}
+
+	var dialer = websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: w.n.config.WS.ResponseTimeout,
+	}
+
+	var wc *websocket.Conn
+
+	if wc, _, err = dialer.Dial(wsURL(address), nil); err != nil {
+		w.n.rememberFailure("ws", address)
+		return
+	}
+
+	var fc = newWSConnection(wc, address)
+
+	if c, err = w.n.wrapConnection(fc, "ws", false); err != nil {
+		return // not a network failure
+	}
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	w.cs[c.Address()] = c // put to the map
+	return
+}
+
+// Close the WS. The Close closes listener
+// and all connections of the WS
+func (w *WS) Close() (err error) {
+
+	w.mx.Lock()
+
+	if w.srv != nil {
+		err = w.srv.Close()
+	}
+
+	for _, c := range w.cs {
+		c.Connection.Close() // underlying connection
+	}
+
+	w.mx.Unlock()
+
+	w.await.Wait()
+	return
+}
+
+// connections strings
+func (w *WS) connections() (cs []string) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	cs = make([]string, 0, len(w.cs))
+
+	for _, c := range w.cs {
+		cs = append(cs, c.String())
+	}
+
+	return
+}
@@ -0,0 +1,229 @@
+package node
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// max size of a message of Unix
+// transport, since peers are local
+// it's just a sanity check
+const unixMaxMessageSize = 64 * 1024 * 1024
+
+// size of GetChanIn and GetChanOut channels
+const unixChanSize = 128
+
+// max time to write pending messages of
+// a closed Unix connection
+const unixCloseTimeout = time.Second
+
+// removeStaleSocket removes socket file with given
+// path if nothing is listening on it, it returns
+// false if the file is not a socket, it's in use
+// or it can't be removed
+func removeStaleSocket(address string) (ok bool) {
+
+	var fi, err = os.Lstat(address)
+
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return // not a socket
+	}
+
+	var nc net.Conn
+	if nc, err = net.Dial("unix", address); err == nil {
+		nc.Close()
+		return // in use
+	}
+
+	return os.Remove(address) == nil
+}
+
+// address of a Unix connection; since remote address
+// of an accepted Unix connection is usually blank, the
+// unixAddr is path of the socket, with sequence number
+// of the connection for accepted connections
+type unixAddr string
+
+// Network implements net.Addr interface
+func (u unixAddr) Network() string {
+	return "unix"
+}
+
+// String implements net.Addr interface
+func (u unixAddr) String() string {
+	return string(u)
+}
+
+// a unixConnection implements Connection
+// interface over Unix domain socket; every
+// message prefixed with its length
+type unixConnection struct {
+	nc     net.Conn
+	remote unixAddr
+
+	in  chan []byte
+	out chan []byte
+
+	closeo sync.Once
+	closeq chan struct{}
+}
+
+func newUnixConnection(nc net.Conn, remote string) (uc *unixConnection) {
+
+	uc = &unixConnection{
+		nc:     nc,
+		remote: unixAddr(remote),
+		in:     make(chan []byte, unixChanSize),
+		out:    make(chan []byte, unixChanSize),
+		closeq: make(chan struct{}),
+	}
+
+	go uc.reading()
+	go uc.writing()
+
+	return
+}
+
+func (u *unixConnection) reading() {
+
+	defer close(u.in)
+	defer u.Close()
+
+	var (
+		r   = bufio.NewReader(u.nc)
+		hdr [4]byte
+	)
+
+	for {
+
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return
+		}
+
+		var size = binary.LittleEndian.Uint32(hdr[:])
+
+		if size > unixMaxMessageSize {
+			return // malformed
+		}
+
+		var p = make([]byte, size)
+
+		if _, err := io.ReadFull(r, p); err != nil {
+			return
+		}
+
+		select {
+		case u.in <- p:
+		case <-u.closeq:
+			return
+		}
+
+	}
+
+}
+
+func (u *unixConnection) writing() {
+
+	defer u.nc.Close()
+	defer u.Close()
+
+	var w = bufio.NewWriter(u.nc)
+
+	for {
+
+		select {
+		case p := <-u.out:
+
+			if err := u.write(w, p); err != nil {
+				return
+			}
+
+			// write all pending messages before flushing
+			for len(u.out) > 0 {
+				if err := u.write(w, <-u.out); err != nil {
+					return
+				}
+			}
+
+			if err := w.Flush(); err != nil {
+				return
+			}
+
+		case <-u.closeq:
+
+			// write messages sent before the Close (e.g. Err
+			// of a rejected handshake), but don't block
+			u.nc.SetWriteDeadline(time.Now().Add(unixCloseTimeout))
+
+			for len(u.out) > 0 {
+				if err := u.write(w, <-u.out); err != nil {
+					return
+				}
+			}
+
+			w.Flush()
+			return
+		}
+
+	}
+
+}
+
+var errUnixMessageSize = errors.New("unix: message is too big")
+
+func (u *unixConnection) write(w *bufio.Writer, p []byte) (err error) {
+
+	if len(p) > unixMaxMessageSize {
+		return errUnixMessageSize
+	}
+
+	var hdr [4]byte
+	binary.LittleEndian.PutUint32(hdr[:], uint32(len(p)))
+
+	if _, err = w.Write(hdr[:]); err != nil {
+		return
+	}
+
+	_, err = w.Write(p)
+	return
+}
+
+// GetChanIn implements Connection interface
+func (u *unixConnection) GetChanIn() <-chan []byte {
+	return u.in
+}
+
+// GetChanOut implements Connection interface
+func (u *unixConnection) GetChanOut() chan<- []byte {
+	return u.out
+}
+
+// GetRemoteAddr implements Connection interface
+func (u *unixConnection) GetRemoteAddr() net.Addr {
+	return u.remote
+}
+
+// IsTCP implements Connection interface
+func (u *unixConnection) IsTCP() bool {
+	return false
+}
+
+// IsUDP implements Connection interface
+func (u *unixConnection) IsUDP() bool {
+	return false
+}
+
+// Close implements Connection interface. Messages
+// sent before the Close are written to the socket
+// and the writing goroutine closes it
+func (u *unixConnection) Close() {
+	u.closeo.Do(func() {
+		close(u.closeq)
+	})
+}
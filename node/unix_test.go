@@ -0,0 +1,115 @@
+package node
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestUnix_Connect(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		path = filepath.Join(dir, "cxo.sock")
+
+		lc = getTestConfigNotListen("server")
+		cn = getTestNodeNotListen("client")
+
+		pk, _ = cipher.GenerateKeyPair()
+
+		ln *Node
+	)
+
+	defer cn.Close()
+
+	lc.Unix.Listen = path
+
+	ln, err = NewNode(lc)
+	assertNil(t, err)
+	defer ln.Close()
+
+	if ln.Unix().Address() != path {
+		t.Error("wrong listening address:", ln.Unix().Address())
+	}
+
+	assertNil(t, ln.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	var c *Conn
+	c, err = cn.Unix().Connect(path)
+	assertNil(t, err)
+
+	if c.Network() != "unix" {
+		t.Error("wrong network:", c.Network())
+	}
+
+	var same *Conn
+	if same, err = cn.Unix().Connect(path); err != nil {
+		t.Error(err)
+	} else if same != c {
+		t.Error("new connection created")
+	}
+
+	assertNil(t, c.Subscribe(pk))
+
+	waitFor(t, func() bool {
+		return len(ln.ConnectionsOfFeed(pk)) == 1
+	})
+
+	var lcs = ln.ConnectionsOfFeed(pk)
+
+	if lcs[0].Network() != "unix" || lcs[0].IsIncoming() == false {
+		t.Error("wrong accepted connection:", lcs[0].String())
+	}
+
+	assertNil(t, c.Close())
+
+	waitFor(t, func() bool {
+		return len(ln.Connections()) == 0 && cn.Unix().getConn(path) == nil
+	})
+
+}
+
+func TestUnix_ListenStale(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-node-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "cxo.sock")
+
+	// stale socket file, e.g. after a crash
+
+	var l *net.UnixListener
+	l, err = net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	assertNil(t, err)
+
+	l.SetUnlinkOnClose(false)
+	l.Close()
+
+	var n = getTestNodeNotListen("stale")
+	defer n.Close()
+
+	assertNil(t, n.Unix().Listen(path))
+
+	// the socket is in use
+
+	var o = getTestNodeNotListen("other")
+	defer o.Close()
+
+	if o.Unix().Listen(path) == nil {
+		t.Error("missing error")
+	}
+
+	if _, err = os.Lstat(path); err != nil {
+		t.Error("socket of listening node removed:", err)
+	}
+
+}
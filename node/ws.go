@@ -0,0 +1,202 @@
+package node
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// max size of a message of WebSocket transport
+const wsMaxMessageSize = 64 * 1024 * 1024
+
+// size of GetChanIn and GetChanOut channels
+const wsChanSize = 128
+
+// max time to write pending messages of
+// a closed WebSocket connection
+const wsCloseTimeout = time.Second
+
+// wsURL returns URL to dial; an address without
+// scheme treated as "ws://address/"
+func wsURL(address string) string {
+	if strings.HasPrefix(address, "ws://") ||
+		strings.HasPrefix(address, "wss://") {
+
+		return address
+	}
+	return "ws://" + address + "/"
+}
+
+// checkOrigin of a WebSocket request; a request without
+// the Origin is not from a browser and it's allowed
+func (w *WS) checkOrigin(r *http.Request) bool {
+
+	var origin = r.Header.Get("Origin")
+
+	if origin == "" {
+		return true // not a browser
+	}
+
+	for _, allowed := range w.n.config.WSOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) == true {
+			return true
+		}
+	}
+
+	// same origin
+
+	var u, err = url.Parse(origin)
+
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// address of a WebSocket connection; it's URL as it
+// passed to the (*WS).Connect for outgoing connections,
+// and remote address of HTTP request for accepted
+type wsAddr string
+
+// Network implements net.Addr interface
+func (w wsAddr) Network() string {
+	return "ws"
+}
+
+// String implements net.Addr interface
+func (w wsAddr) String() string {
+	return string(w)
+}
+
+// a wsConnection implements Connection interface
+// over WebSocket; every message of the msg protocol
+// is sent as binary WebSocket message
+type wsConnection struct {
+	wc     *websocket.Conn
+	remote wsAddr
+
+	in  chan []byte
+	out chan []byte
+
+	closeo sync.Once
+	closeq chan struct{}
+}
+
+func newWSConnection(wc *websocket.Conn, remote string) (w *wsConnection) {
+
+	w = &wsConnection{
+		wc:     wc,
+		remote: wsAddr(remote),
+		in:     make(chan []byte, wsChanSize),
+		out:    make(chan []byte, wsChanSize),
+		closeq: make(chan struct{}),
+	}
+
+	wc.SetReadLimit(wsMaxMessageSize)
+
+	go w.reading()
+	go w.writing()
+
+	return
+}
+
+func (w *wsConnection) reading() {
+
+	defer close(w.in)
+	defer w.Close()
+
+	for {
+
+		var mt, p, err = w.wc.ReadMessage()
+
+		if err != nil {
+			return
+		}
+
+		if mt != websocket.BinaryMessage {
+			return // protocol error
+		}
+
+		select {
+		case w.in <- p:
+		case <-w.closeq:
+			return
+		}
+
+	}
+
+}
+
+func (w *wsConnection) writing() {
+
+	defer w.wc.Close()
+	defer w.Close()
+
+	for {
+
+		select {
+		case p := <-w.out:
+
+			if err := w.wc.WriteMessage(websocket.BinaryMessage, p); err != nil {
+				return
+			}
+
+		case <-w.closeq:
+
+			// write messages sent before the Close (e.g. Err
+			// of a rejected handshake), but don't block
+			w.wc.SetWriteDeadline(time.Now().Add(wsCloseTimeout))
+
+			for len(w.out) > 0 {
+				var p = <-w.out
+				if err := w.wc.WriteMessage(websocket.BinaryMessage, p); err != nil {
+					return
+				}
+			}
+
+			return
+		}
+
+	}
+
+}
+
+// GetChanIn implements Connection interface
+func (w *wsConnection) GetChanIn() <-chan []byte {
+	return w.in
+}
+
+// GetChanOut implements Connection interface
+func (w *wsConnection) GetChanOut() chan<- []byte {
+	return w.out
+}
+
+// GetRemoteAddr implements Connection interface
+func (w *wsConnection) GetRemoteAddr() net.Addr {
+	return w.remote
+}
+
+// IsTCP implements Connection interface
+func (w *wsConnection) IsTCP() bool {
+	return false
+}
+
+// IsUDP implements Connection interface
+func (w *wsConnection) IsUDP() bool {
+	return false
+}
+
+// Close implements Connection interface. Messages
+// sent before the Close are written to the socket
+// and the writing goroutine closes it
+func (w *wsConnection) Close() {
+	w.closeo.Do(func() {
+		close(w.closeq)
+	})
+}
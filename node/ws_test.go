@@ -0,0 +1,232 @@
+package node
+
+import (
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestWS_Connect(t *testing.T) {
+
+	var (
+		lc = getTestConfigNotListen("server")
+		cn = getTestNodeNotListen("client")
+
+		pk, _ = cipher.GenerateKeyPair()
+
+		ln  *Node
+		err error
+	)
+
+	defer cn.Close()
+
+	lc.WS.Listen = "127.0.0.1:8089"
+
+	ln, err = NewNode(lc)
+	assertNil(t, err)
+	defer ln.Close()
+
+	assertNil(t, ln.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	var c *Conn
+	c, err = cn.WS().Connect("ws://127.0.0.1:8089/cxo")
+	assertNil(t, err)
+
+	if c.IsWS() == false || c.IsTCP() == true || c.Network() != "ws" {
+		t.Error("wrong type of connection:", c.String())
+	}
+
+	assertNil(t, c.Subscribe(pk))
+
+	waitFor(t, func() bool {
+		return len(ln.ConnectionsOfFeed(pk)) == 1
+	})
+
+	if lc := ln.ConnectionsOfFeed(pk)[0]; lc.IsWS() == false {
+		t.Error("wrong accepted connection:", lc.String())
+	}
+
+}
+
+func TestWS_ServeHTTP(t *testing.T) {
+
+	var (
+		ln = getTestNodeNotListen("server")
+		cn = getTestNodeNotListen("client")
+	)
+
+	defer ln.Close()
+	defer cn.Close()
+
+	// the WS used by another HTTP server
+	var srv = httptest.NewServer(ln.WS())
+	defer srv.Close()
+
+	var c, err = cn.WS().Connect(strings.Replace(srv.URL, "http", "ws", 1))
+	assertNil(t, err)
+
+	if c.PeerID() != ln.ID() {
+		t.Error("wrong peer id")
+	}
+
+}
+
+func TestWS_reject(t *testing.T) {
+
+	var (
+		lc = getTestConfigNotListen("server")
+		cn = getTestNodeNotListen("client")
+
+		ln  *Node
+		err error
+	)
+
+	defer cn.Close()
+
+	assertNil(t, lc.Deny.Add(cn.ID().Hex()))
+
+	if ln, err = NewNode(lc); err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var srv = httptest.NewServer(ln.WS())
+	defer srv.Close()
+
+	// the Err of the rejected handshake reaches the client
+
+	_, err = cn.WS().Connect(strings.Replace(srv.URL, "http", "ws", 1))
+
+	if err == nil || err.Error() != ErrNotAllowed.Error() {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestWS_ConnectSlow(t *testing.T) {
+
+	var (
+		cc = getTestConfigNotListen("client")
+		ln = getTestNodeNotListen("server")
+
+		cn  *Node
+		err error
+	)
+
+	defer ln.Close()
+
+	cc.WS.ResponseTimeout = 0 // no timeout
+
+	if cn, err = NewNode(cc); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	// accept TCP connections, but never reply
+
+	var l net.Listener
+	l, err = net.Listen("tcp", "127.0.0.1:0")
+	assertNil(t, err)
+	defer l.Close()
+
+	go func() {
+		for {
+			var nc, err = l.Accept()
+			if err != nil {
+				return
+			}
+			defer nc.Close()
+		}
+	}()
+
+	go cn.WS().Connect(l.Addr().String())
+
+	time.Sleep(TM / 10)
+
+	var srv = httptest.NewServer(ln.WS())
+	defer srv.Close()
+
+	var done = make(chan error, 1)
+
+	go func() {
+		var _, err = cn.WS().Connect(strings.Replace(srv.URL, "http", "ws", 1))
+		done <- err
+	}()
+
+	select {
+	case err = <-done:
+		assertNil(t, err)
+	case <-time.After(TM):
+		t.Fatal("blocked by another dial")
+	}
+
+}
+
+func TestWS_checkOrigin(t *testing.T) {
+
+	var conf = getTestConfigNotListen("test")
+
+	conf.WSOrigins = Addresses{"https://example.com"}
+
+	var n, err = NewNode(conf)
+	assertNil(t, err)
+	defer n.Close()
+
+	for _, tt := range []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},                          // not a browser
+		{"http://127.0.0.1:8089", true},     // same origin
+		{"https://example.com", true},       // allowed
+		{"https://Example.COM", true},       // case insensitive
+		{"https://evil.example.org", false}, // not allowed
+		{"http://127.0.0.1:8090", false},    // other port
+		{"://invalid origin", false},        // malformed
+	} {
+
+		var r = httptest.NewRequest("GET", "http://127.0.0.1:8089/", nil)
+
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+
+		if got := n.WS().checkOrigin(r); got != tt.want {
+			t.Errorf("origin %q: got %t, want %t", tt.origin, got, tt.want)
+		}
+
+	}
+
+	// any
+
+	n.config.WSOrigins = Addresses{"*"}
+
+	var r = httptest.NewRequest("GET", "http://127.0.0.1:8089/", nil)
+	r.Header.Set("Origin", "https://evil.example.org")
+
+	if n.WS().checkOrigin(r) == false {
+		t.Error("\"*\" doesn't allow any origin")
+	}
+
+}
+
+func Test_wsURL(t *testing.T) {
+
+	for _, tt := range []struct {
+		address, want string
+	}{
+		{"127.0.0.1:8870", "ws://127.0.0.1:8870/"},
+		{"ws://example.com/cxo", "ws://example.com/cxo"},
+		{"wss://example.com/cxo", "wss://example.com/cxo"},
+	} {
+		if got := wsURL(tt.address); got != tt.want {
+			t.Errorf("wrong URL of %s: %s, want %s", tt.address, got, tt.want)
+		}
+	}
+
+}
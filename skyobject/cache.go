@@ -956,7 +956,8 @@ func (c *Cache) inc(
 		return
 	}
 
-	err = c.putItem(key, val, int(urc))
+	rc = int(urc)
+	err = c.putItem(key, val, rc)
 	return
 
 }
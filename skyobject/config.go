@@ -6,6 +6,9 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/node/log"
@@ -35,6 +38,10 @@ const (
 
 	MaxFillingParallel int = 10 // ten parallel subtrees
 
+	// retention
+
+	RetentionInterval time.Duration = 10 * time.Second // apply every 10s
+
 	// DB related constants
 	CXDS  string = "cxds.db" // default CXDS file name
 	IdxDB string = "idx.db"  // default IdxDB file name
@@ -150,6 +157,26 @@ type Config struct {
 	// to number of connections that used to fill a Root.
 	MaxFillingParallel int
 
+	// retention
+
+	// Retention is default retention policy of feeds
+	// of the Container. By default it's zero and all
+	// Root objects are kept. See Retention for details
+	Retention Retention
+	// FeedRetention is retention policy per feed. It
+	// overrides the Retention for given feeds. Zero
+	// Retention of a feed keeps all its Root objects.
+	// The FeedRetention can contain feeds the Container
+	// doesn't have. Use SetRetention method of the
+	// Container to change a policy at runtime
+	FeedRetention map[cipher.PubKey]Retention
+	// RetentionInterval is interval the Container applies
+	// retention policies with in background. Set it to
+	// zero to turn the background removing off. In this
+	// case call ApplyRetention method of the Container
+	// manually
+	RetentionInterval time.Duration
+
 	// DB configs
 
 	// CheckSizes force Container to check sizes of objects
@@ -203,6 +230,8 @@ func NewConfig() (conf *Config) {
 
 	conf.MaxObjectSize = MaxObjectSize
 
+	conf.RetentionInterval = RetentionInterval
+
 	// data dir
 	conf.DataDir = DataDir()
 
@@ -224,6 +253,18 @@ func (c *Config) FromFlags() {
 		"db-path",
 		c.DBPath,
 		"path to database")
+	flag.IntVar(&c.Retention.KeepLast,
+		"keep-last",
+		c.Retention.KeepLast,
+		"keep last n Root objects of a head, 0 - keep all")
+	flag.DurationVar(&c.Retention.KeepFor,
+		"keep-for",
+		c.Retention.KeepFor,
+		"keep Root objects saved during this time, 0 - keep all")
+	flag.DurationVar(&c.RetentionInterval,
+		"retention-interval",
+		c.RetentionInterval,
+		"interval of removing old Root objects, 0 - don't remove")
 }
 
 // Validate the Config
@@ -279,5 +320,21 @@ func (c *Config) Validate() error {
 			c.MaxObjectSize)
 	}
 
+	if err := c.Retention.Validate(); err != nil {
+		return err
+	}
+
+	for pk, rt := range c.FeedRetention {
+		if err := rt.Validate(); err != nil {
+			return fmt.Errorf("%s (feed %s)", err, pk.Hex()[:7])
+		}
+	}
+
+	if c.RetentionInterval < 0 {
+		return fmt.Errorf(
+			"skyobject.Config.RetentionInterval is negative: %s",
+			c.RetentionInterval)
+	}
+
 	return nil
 }
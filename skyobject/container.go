@@ -3,6 +3,7 @@ package skyobject
 import (
 	"log"
 	"path/filepath"
+	"sync"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
@@ -25,6 +26,13 @@ type Container struct {
 
 	conf *Config // configurations
 
+	// retention
+	rmx sync.Mutex                  // lock the rtn
+	rtn map[cipher.PubKey]Retention // per feed policies
+
+	closeq chan struct{}  // stop background goroutines
+	await  sync.WaitGroup // wait background goroutines
+
 	// human readable (used by node for debugging)
 	cxPath, idxPath string
 }
@@ -79,6 +87,9 @@ func NewContainer(conf *Config) (c *Container, err error) {
 		return
 	}
 
+	// remove old Root objects in background
+	c.initRetention(conf)
+
 	return // done
 }
 
@@ -354,6 +365,8 @@ func (c *Container) RootByHash(
 // with user-provided DB.
 func (c *Container) Close() (err error) {
 
+	c.closeRetention() // stop background goroutine
+
 	// the Cache.Close closes CXDS
 	if err = c.Cache.Close(); err == nil {
 		err = c.db.Close()
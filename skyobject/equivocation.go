@@ -0,0 +1,259 @@
+package skyobject
+
+import (
+	"errors"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// If owner of a feed signs two different Root objects
+// with the same nonce and seq, then it's equivocation
+// (a fork of the head). The Index detects it receiving
+// or adding a Root that conflicts with a Root the DB
+// already has. Both signed Root objects are saved in
+// IdxDB as evidence, and the head is marked as
+// equivocated. The Index keeps the first Root only
+
+// An Equivocation represents two different Root objects
+// with the same feed, nonce and seq signed by owner of
+// the feed. The Root objects have hashes and signatures,
+// and the First is a Root received first
+type Equivocation struct {
+	Feed  cipher.PubKey
+	Nonce uint64
+	Seq   uint64
+
+	Time int64 // detected at
+
+	First  *registry.Root // Root received first
+	Second *registry.Root // conflicting Root
+}
+
+// encode and verify given Root
+func signedRoot(r *registry.Root) (sr data.SignedRoot, err error) {
+
+	sr.Value = r.Encode()
+	sr.Sig = r.Sig
+
+	if cipher.SumSHA256(sr.Value) != r.Hash {
+		err = errors.New("hash of Root doesn't match: " + r.Short())
+		return
+	}
+
+	err = cipher.VerifySignature(r.Pub, r.Sig, r.Hash)
+	return
+}
+
+// decode given signed Root
+func decodeSignedRoot(sr data.SignedRoot) (r *registry.Root, err error) {
+
+	if r, err = registry.DecodeRoot(sr.Value); err != nil {
+		return
+	}
+
+	r.Hash = cipher.SumSHA256(sr.Value)
+	r.Sig = sr.Sig
+
+	return
+}
+
+// under lock
+func (i *Index) addEquivocation(
+	first *registry.Root, //  : Root received first
+	second *registry.Root, // : conflicting Root
+) (
+	e *Equivocation, //       : the equivocation
+	isNew bool, //            : saved first time
+	err error, //             : an error
+) {
+
+	if first.Pub != second.Pub || first.Nonce != second.Nonce ||
+		first.Seq != second.Seq {
+
+		return nil, false, errors.New("Root objects of different heads or" +
+			" seq: " + first.Short() + " and " + second.Short())
+	}
+
+	if first.Hash == second.Hash {
+		return nil, false, errors.New("the same Root: " + first.Short())
+	}
+
+	var hs, ok = i.feeds[first.Pub]
+
+	if ok == false {
+		return nil, false, data.ErrNoSuchFeed
+	}
+
+	var de = &data.Equivocation{
+		Nonce: first.Nonce,
+		Seq:   first.Seq,
+		Time:  time.Now().UnixNano(),
+	}
+
+	if de.First, err = signedRoot(first); err != nil {
+		return
+	}
+
+	if de.Second, err = signedRoot(second); err != nil {
+		return
+	}
+
+	err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		var es data.Equivocations
+		if es, err = feeds.Equivocations(first.Pub); err != nil {
+			return
+		}
+
+		var has bool
+		if has, err = es.Has(de.Nonce, de.Seq); err != nil || has == true {
+			return // the first evidence is enough
+		}
+
+		isNew = true
+		return es.Add(de)
+	})
+
+	if err != nil {
+		return
+	}
+
+	hs.eq[first.Nonce] = struct{}{}
+
+	e = &Equivocation{
+		Feed:   first.Pub,
+		Nonce:  first.Nonce,
+		Seq:    first.Seq,
+		Time:   de.Time,
+		First:  first,
+		Second: second,
+	}
+
+	return
+}
+
+// under lock, the dr is saved Root that
+// conflicts with given received one
+func (i *Index) equivocation(
+	dr *data.Root, //     : saved
+	r *registry.Root, // : received
+) (
+	err error, //         : EquivocationError or another error
+) {
+
+	var first *registry.Root
+	if first, err = i.c.rootByHash(dr.Hash); err != nil {
+		return
+	}
+
+	first.Sig = dr.Sig
+	first.IsFull = true
+
+	var (
+		e     *Equivocation
+		isNew bool
+	)
+
+	if e, isNew, err = i.addEquivocation(first, r); err != nil {
+		return
+	}
+
+	return &EquivocationError{Equivocation: e, New: isNew}
+}
+
+// AddEquivocation saves given Root objects as evidence
+// of equivocation and marks their head as equivocated.
+// The Root objects should have the same feed, nonce and
+// seq, but different hashes, and they should be signed
+// by owner of the feed. The AddEquivocation used by the
+// node package when it receives two different Root
+// objects with the same seq before any of them saved,
+// otherwise the ReceivedRoot and the AddRoot detect the
+// equivocation. The isNew reply is false if there is
+// an equivocation with the same nonce and seq already,
+// in this case the evidence is not replaced. It returns
+// data.ErrNoSuchFeed if the feed doesn't exist
+func (i *Index) AddEquivocation(
+	first *registry.Root, //  : Root received first
+	second *registry.Root, // : conflicting Root
+) (
+	e *Equivocation, //       : the equivocation
+	isNew bool, //            : saved first time
+	err error, //             : an error
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	return i.addEquivocation(first, second)
+}
+
+// Equivocations returns all equivocations of given
+// feed ordered by nonce and seq. It returns
+// data.ErrNoSuchFeed if the feed doesn't exist
+func (i *Index) Equivocations(
+	pk cipher.PubKey, //     : feed
+) (
+	es []*Equivocation, //   : the equivocations
+	err error, //            : an error
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[pk]; ok == false {
+		return nil, data.ErrNoSuchFeed
+	}
+
+	err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+
+		var des data.Equivocations
+		if des, err = feeds.Equivocations(pk); err != nil {
+			return
+		}
+
+		return des.Iterate(func(de *data.Equivocation) (err error) {
+
+			var e = &Equivocation{
+				Feed:  pk,
+				Nonce: de.Nonce,
+				Seq:   de.Seq,
+				Time:  de.Time,
+			}
+
+			if e.First, err = decodeSignedRoot(de.First); err != nil {
+				return
+			}
+
+			if e.Second, err = decodeSignedRoot(de.Second); err != nil {
+				return
+			}
+
+			es = append(es, e)
+			return
+		})
+
+	})
+
+	return
+}
+
+// IsEquivocated returns true if given head
+// of given feed has at least one equivocation
+func (i *Index) IsEquivocated(pk cipher.PubKey, nonce uint64) (yep bool) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs, ok = i.feeds[pk]
+
+	if ok == false {
+		return
+	}
+
+	_, yep = hs.eq[nonce]
+	return
+}
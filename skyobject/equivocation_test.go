@@ -0,0 +1,107 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestIndex_equivocation(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	assertNil(t, c.Save(up, r))
+
+	assertTrue(t, c.IsEquivocated(pk, 1) == false, "unexpected equivocation")
+
+	// another Root with the same seq
+
+	var fr = *r
+
+	fr.Time++
+
+	var val = fr.Encode()
+
+	fr.Hash = cipher.SumSHA256(val)
+	fr.Sig = cipher.SignHash(fr.Hash, sk)
+	fr.IsFull = true
+
+	var check = func(t *testing.T, err error, isNew bool) {
+		t.Helper()
+
+		var ee, ok = err.(*EquivocationError)
+
+		if ok == false {
+			t.Fatal("missing EquivocationError:", err)
+		}
+
+		assertTrue(t, ee.New == isNew, "wrong New")
+
+		var e = ee.Equivocation
+
+		assertTrue(t, e.Feed == pk && e.Nonce == 1 && e.Seq == r.Seq,
+			"wrong head")
+		assertTrue(t, e.First.Hash == r.Hash && e.First.Sig == r.Sig,
+			"wrong first Root")
+		assertTrue(t, e.Second.Hash == fr.Hash && e.Second.Sig == fr.Sig,
+			"wrong second Root")
+	}
+
+	t.Run("received", func(t *testing.T) {
+		_, err := c.ReceivedRoot(pk, fr.Sig, val)
+		check(t, err, true)
+	})
+
+	t.Run("add", func(t *testing.T) {
+		var cp = fr
+		_, err := c.AddRoot(&cp)
+		check(t, err, false) // already saved
+	})
+
+	assertTrue(t, c.IsEquivocated(pk, 1) == true, "not equivocated")
+	assertTrue(t, c.IsEquivocated(pk, 2) == false, "wrong head equivocated")
+
+	// the first Root kept
+
+	var lr *registry.Root
+	lr, err = c.LastRoot(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, lr.Hash == r.Hash, "the first Root replaced")
+
+	// evidence
+
+	var es []*Equivocation
+	es, err = c.Equivocations(pk)
+	assertNil(t, err)
+
+	assertTrue(t, len(es) == 1, "wrong number of equivocations")
+	assertTrue(t, es[0].First.Hash == r.Hash && es[0].Second.Hash == fr.Hash,
+		"wrong evidence")
+	assertTrue(t, es[0].First.Sig == r.Sig && es[0].Second.Sig == fr.Sig,
+		"wrong signatures")
+	assertTrue(t, es[0].Second.Time == fr.Time, "wrong decoded Root")
+
+	// the first evidence is kept
+
+	var isNew bool
+	_, isNew, err = c.AddEquivocation(r, &fr)
+	assertNil(t, err)
+	assertTrue(t, isNew == false, "equivocation saved twice")
+
+	// the same Root is not an equivocation
+
+	_, _, err = c.AddEquivocation(r, r)
+	assertTrue(t, err != nil, "missing error")
+
+}
@@ -2,6 +2,7 @@ package skyobject
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/skycoin/skycoin/src/cipher"
 )
@@ -29,3 +30,24 @@ func (o *ObjectIsTooLargeError) Hash() cipher.SHA256 {
 func (o *ObjectIsTooLargeError) Error() string {
 	return "object is too large: " + o.Hash().Hex()[:7]
 }
+
+// An EquivocationError returned by the ReceivedRoot
+// and the AddRoot methods if given Root conflicts with
+// a Root the DB already has (see Equivocation). The
+// equivocation is already saved, when the error
+// returned. The New is false if the equivocation
+// has been saved before
+type EquivocationError struct {
+	Equivocation *Equivocation
+	New          bool
+}
+
+// Error implements error interface
+func (e *EquivocationError) Error() string {
+	return fmt.Sprintf("equivocation of %s/%d/%d: %s and %s",
+		e.Equivocation.Feed.Hex()[:7],
+		e.Equivocation.Nonce,
+		e.Equivocation.Seq,
+		e.Equivocation.First.Hash.Hex()[:7],
+		e.Equivocation.Second.Hash.Hex()[:7])
+}
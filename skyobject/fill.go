@@ -17,11 +17,31 @@ type Filler struct {
 
 	reg *registry.Registry
 
-	rq chan<- cipher.SHA256
-
-	mx   sync.Mutex
-	incs map[cipher.SHA256]int
-	pre  map[cipher.SHA256]struct{} // prerequested by RC
+	rq  chan<- cipher.SHA256 // request objects
+	prq chan<- cipher.SHA256 // request prioritized objects
+
+	mx     sync.Mutex
+	incs   map[cipher.SHA256]int
+	direct map[cipher.SHA256]int      // incs of objects found in DB
+	pre    map[cipher.SHA256]struct{} // prerequested by RC
+
+	// progress
+	rcvd     map[cipher.SHA256]struct{} // received objects
+	bytes    int                        // bytes received
+	waiting  int                        // objects requested
+	expected int                        // Refs elements not reached yet
+
+	// delta
+	prev           *registry.Root // previous full Root or nil
+	pp             *Pack          // pack of the prev
+	skipped        int            // subtrees skipped
+	skippedObjects int            // objects of the subtrees
+
+	// priority
+	prior            []int                // prioritized Root.Refs
+	elementsFrom     int                  // prioritized elements
+	elementsTo       int                  // of Refs
+	onPriorityFilled OnPriorityFilledFunc // callback
 
 	limit chan struct{} // max
 
@@ -45,6 +65,7 @@ func (f *Filler) Registry() (reg *registry.Registry) {
 func (f *Filler) get(
 	key cipher.SHA256,
 	inc int,
+	rq chan<- cipher.SHA256,
 ) (
 	val []byte,
 	rc int,
@@ -56,7 +77,8 @@ func (f *Filler) get(
 
 	if err == nil {
 		if inc > 0 {
-			rc = f.inc(key, rc) // ++
+			// the rc includes the inc, but the hard rc should not
+			rc = f.incDirect(key, rc-inc, inc) // ++
 		}
 		return
 	}
@@ -71,15 +93,20 @@ func (f *Filler) get(
 	var gc = make(chan Object, 1) // wait for the object
 
 	f.c.Want(key, gc, inc)
-	defer f.c.Unwant(key, gc) // to be memory safe
 
 	// requset the object using the rq channel
-	if f.requset(key) == false {
+	if f.requset(rq, key) == false {
+		f.c.Unwant(key, gc) // to be memory safe
 		return
 	}
 
+	f.wait(1)
+	defer f.wait(-1)
+
 	select {
 	case obj := <-gc:
+		f.c.Unwant(key, gc) // to be memory safe
+
 		if err = obj.Err; err != nil {
 			return
 		}
@@ -89,8 +116,21 @@ func (f *Filler) get(
 		} else {
 			rc = obj.RC
 		}
+		f.received(key, len(val))
 	case <-f.closeq:
 		err = ErrTerminated
+
+		// the object can be received already, and
+		// its inc should be rejected by the Filler
+		f.c.Unwant(key, gc)
+
+		select {
+		case obj := <-gc:
+			if obj.Err == nil && inc > 0 {
+				f.inc(key, obj.RC)
+			}
+		default:
+		}
 	}
 
 	return
@@ -101,7 +141,19 @@ func (f *Filler) get(
 // an item this method used. The method doesn't return value, because
 // nobobdy need it. The Pre used to obtain "hard rc" of an item
 func (f *Filler) Pre(key cipher.SHA256) (rc int, err error) {
-	if _, rc, err = f.get(key, 1); err != nil {
+	return f.preRequest(key, f.rq)
+}
+
+// the Pre using given channel to request the object
+func (f *Filler) preRequest(
+	key cipher.SHA256,
+	rq chan<- cipher.SHA256,
+) (
+	rc int,
+	err error,
+) {
+
+	if _, rc, err = f.get(key, 1, rq); err != nil {
 		return
 	}
 
@@ -124,6 +176,18 @@ func (f *Filler) isPrerequested(key cipher.SHA256) (ok bool) {
 // channel. The Get increments references counter
 // of value
 func (f *Filler) Get(key cipher.SHA256) (val []byte, rc int, err error) {
+	return f.getRequest(key, f.rq)
+}
+
+// the Get using given channel to request the object
+func (f *Filler) getRequest(
+	key cipher.SHA256,
+	rq chan<- cipher.SHA256,
+) (
+	val []byte,
+	rc int,
+	err error,
+) {
 
 	var inc = 1
 
@@ -131,10 +195,71 @@ func (f *Filler) Get(key cipher.SHA256) (val []byte, rc int, err error) {
 		inc = 0 // prerequested
 	}
 
-	val, rc, err = f.get(key, inc)
+	val, rc, err = f.get(key, inc, rq)
 	return
 }
 
+// Expect used to estimate total number of objects
+// of the Root. See registry.Splitter for details
+func (f *Filler) Expect(n int) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.expected += n
+}
+
+// Previous returns Pack of previous full Root
+// if delta sync used, or nil. See Delta method
+// and registry.Splitter for details
+func (f *Filler) Previous() (pack registry.Pack) {
+	if f.pp != nil {
+		pack = f.pp
+	}
+	return
+}
+
+// Skip increments rc of an object, that is the same
+// in the previous full Root, without loading it. See
+// registry.Splitter for details
+func (f *Filler) Skip(key cipher.SHA256, objects int) (ok bool) {
+
+	if f.pp == nil {
+		return // no delta
+	}
+
+	var rc, err = f.c.Inc(key, 1)
+
+	if err == data.ErrNotFound {
+		return // removed or wanted
+	}
+
+	if err != nil {
+		fatal("DB failure:", err) // fatality
+	}
+
+	// the hard rc should not be zero, since the object
+	// belongs to a full Root; otherwise the previous
+	// Root has been removed and objects of the subtree
+	// can be removed too, and the object should be
+	// walked as usual
+
+	if rc-1 <= 0 {
+		if _, err = f.c.Inc(key, -1); err != nil {
+			fatal("DB failure:", err) // fatality
+		}
+		return
+	}
+
+	f.incDirect(key, rc-1, 1)
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.skipped++
+	f.skippedObjects += objects
+	return true
+}
+
 // Fail used to terminate the Filler with
 // provided error
 func (f *Filler) Fail(err error) {
@@ -168,10 +293,82 @@ func (f *Filler) inc(key cipher.SHA256, drc int) (rc int) {
 	return
 }
 
-func (f *Filler) requset(key cipher.SHA256) (ok bool) {
+// like the inc, but the object found in DB and its rc
+// is already incremented (the Cache doesn't track the
+// inc as inc of a filler)
+func (f *Filler) incDirect(key cipher.SHA256, drc, inc int) (rc int) {
+	rc = f.inc(key, drc)
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.direct[key] += inc
+	return
+}
+
+func (f *Filler) wait(n int) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.waiting += n
+}
+
+func (f *Filler) received(key cipher.SHA256, size int) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if _, ok := f.rcvd[key]; ok == false {
+		f.rcvd[key] = struct{}{}
+		f.bytes += size
+	}
+}
+
+// A FillingProgress represents progress
+// of a Filler. The Total is estimated
+// using lengths of Refs of the Root and
+// can be changed during the filling. The
+// SkippedObjects is estimated too, since
+// skipped subtrees are not loaded: it's
+// number of roots of the subtrees and
+// elements of skipped Refs, but objects
+// the elements refer to are not counted
+type FillingProgress struct {
+	Objects         int // objects received or found in DB
+	Received        int // objects received
+	Bytes           int // bytes received
+	Total           int // estimated total number of objects
+	SkippedSubtrees int // subtrees skipped by delta sync
+	SkippedObjects  int // estimated objects of the subtrees
+}
+
+// Progress of the Filler
+func (f *Filler) Progress() (fp FillingProgress) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	fp.Objects = len(f.incs)
+	fp.Received = len(f.rcvd)
+	fp.Bytes = f.bytes
+	fp.Total = fp.Objects + f.waiting
+	fp.SkippedSubtrees = f.skipped
+	fp.SkippedObjects = f.skippedObjects
+
+	if f.expected > 0 {
+		fp.Total += f.expected
+	}
+
+	return
+}
+
+func (f *Filler) requset(
+	rq chan<- cipher.SHA256,
+	key cipher.SHA256,
+) (
+	ok bool,
+) {
 
 	select {
-	case f.rq <- key:
+	case rq <- key:
 		ok = true
 	case <-f.closeq:
 	}
@@ -207,6 +404,8 @@ func (c *Container) Fill(
 
 	f.rq = rq
 	f.incs = make(map[cipher.SHA256]int)
+	f.direct = make(map[cipher.SHA256]int)
+	f.rcvd = make(map[cipher.SHA256]struct{})
 	f.pre = make(map[cipher.SHA256]struct{})
 
 	if maxParall > 0 {
@@ -221,18 +420,56 @@ func (c *Container) Fill(
 
 func (f *Filler) apply() {
 	for key, inc := range f.incs {
+		if inc -= f.direct[key]; inc == 0 {
+			continue // already applied
+		}
 		if err := f.c.Finc(key, inc); err != nil {
 			panic("DB failure: " + err.Error()) // TODO: handle the error
 		}
 	}
 }
 
-func (f *Filler) reject() {
+// reject incs of the Filler returning keys of all
+// objects, the objects are kept in DB even if their
+// rc turns zero
+func (f *Filler) reject() (keys []cipher.SHA256) {
+
+	keys = make([]cipher.SHA256, 0, len(f.incs))
+
 	for key, inc := range f.incs {
+
+		keys = append(keys, key)
+
+		var direct = f.direct[key]
+
+		if direct > 0 {
+			if _, err := f.c.Inc(key, -direct); err != nil {
+				panic("DB failure: " + err.Error()) // TODO: handle the error
+			}
+		}
+
+		if inc -= direct; inc == 0 {
+			continue
+		}
+
 		if err := f.c.Finc(key, -inc); err != nil {
 			panic("DB failure: " + err.Error()) // TODO: handle the error
 		}
 	}
+
+	return
+}
+
+// keep objects received and mark the Root as
+// pending to continue the filling later
+func (f *Filler) keep(keys []cipher.SHA256) {
+
+	var err = f.c.AddPending(f.r, keys)
+
+	if err != nil && err != data.ErrNoSuchFeed {
+		panic("DB failure: " + err.Error()) // TODO: handle the error
+	}
+
 }
 
 func (f *Filler) acquire() (parall bool) {
@@ -286,12 +523,12 @@ func (f *Filler) Run() (err error) {
 		return
 	}
 
-	f.inc(f.r.Hash, 0) // increment
+	f.incDirect(f.r.Hash, 0, 1) // increment
 
 	defer func() {
 		if err != nil {
 			f.r.IsFull = false // reset
-			f.reject()
+			f.keep(f.reject())
 		} else {
 			f.apply()
 		}
@@ -301,12 +538,51 @@ func (f *Filler) Run() (err error) {
 		return
 	}
 
-	for _, dr := range f.r.Refs {
+	if f.prev != nil {
+		f.pp = f.c.getPack(f.reg)
+	}
+
+	var prior, rest = f.order()
+
+	for _, i := range prior {
+		f.splitPriority(i)
+	}
+
+	if err = f.split(rest...); err == nil {
+		f.r.IsFull = true // full!
+		_, err = f.c.AddRoot(f.r)
+	}
+
+	f.Close()
+
+	return
+}
+
+// split prioritized element of Root.Refs,
+// the method doesn't wait for the end
+func (f *Filler) splitPriority(i int) {
+
+	var (
+		st       = &subtree{Filler: f}
+		dr, prev = f.r.Refs[i], f.previous(i)
+	)
+
+	st.Go(func() { dr.SplitDelta(st, prev) })
+
+	f.await.Add(1)
+	go st.notify(i)
+}
+
+// split given elements of Root.Refs and wait for
+// the end or for first error
+func (f *Filler) split(is ...int) (err error) {
+
+	for _, i := range is {
 
 		// the closure is data-race protection
-		func(dr registry.Dynamic) {
-			f.Go(func() { dr.Split(f) })
-		}(dr)
+		func(dr, prev registry.Dynamic) {
+			f.Go(func() { dr.SplitDelta(f, prev) })
+		}(f.r.Refs[i], f.previous(i))
 
 	}
 
@@ -320,12 +596,35 @@ func (f *Filler) Run() (err error) {
 	select {
 	case err = <-f.errq:
 	case <-done:
-		f.r.IsFull = true // full!
-		_, err = f.c.AddRoot(f.r)
 	}
 
-	f.Close()
+	return
+}
 
+// Delta turns on delta sync. The Filler compares
+// the Root with given previous full Root of the same
+// head and skips subtrees with the same hashes
+// without loading them. The Delta should be called
+// before the Run. Root objects of different heads
+// or with different registries are not compared
+func (f *Filler) Delta(prev *registry.Root) {
+
+	if prev == nil || prev.Hash == f.r.Hash {
+		return
+	}
+
+	if prev.Pub != f.r.Pub || prev.Nonce != f.r.Nonce || prev.Reg != f.r.Reg {
+		return
+	}
+
+	f.prev = prev
+}
+
+// element of Root.Refs of previous Root or blank
+func (f *Filler) previous(i int) (prev registry.Dynamic) {
+	if f.prev != nil && i < len(f.prev.Refs) {
+		prev = f.prev.Refs[i]
+	}
 	return
 }
 
@@ -245,3 +245,200 @@ func testFillDBs(t *testing.T, sc, rc *Container) {
 		t.FailNow()
 	}
 }
+
+func TestFiller_Progress(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var feed Feed
+
+	for i := 0; i < 100; i++ {
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+		}))
+	}
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	var total int
+
+	assertNil(t, sc.Walk(r, func(cipher.SHA256, int) (bool, error) {
+		total++
+		return true, nil
+	}))
+
+	var requested int
+	var fp FillingProgress
+
+	requested, fp, err = testFillLimit(t, sc, rc, r, -1)
+	assertNil(t, err)
+
+	if fp.Objects != total || fp.Total != total {
+		t.Errorf("wrong objects or total: %d, %d, want %d", fp.Objects,
+			fp.Total, total)
+	}
+
+	if fp.Received != requested || fp.Bytes == 0 {
+		t.Error("wrong received:", fp.Received, fp.Bytes, requested)
+	}
+
+	// the same with objects in DB
+
+	assertNil(t, feed.Posts.AppendValues(up, Post{Head: "one more"}))
+	assertNil(t, r.Refs[0].SetValue(up, &feed))
+	assertNil(t, sc.Save(up, r))
+
+	requested, fp, err = testFillLimit(t, sc, rc, r, -1)
+	assertNil(t, err)
+
+	if fp.Total != fp.Objects {
+		t.Error("wrong total:", fp.Total, fp.Objects)
+	}
+
+	if fp.Received != requested {
+		t.Error("wrong received:", fp.Received, requested)
+	}
+
+}
+
+func TestFiller_Delta(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var feed Feed
+
+	for i := 0; i < 200; i++ {
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+		}))
+	}
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	_, _, err = testFillLimit(t, sc, rc, r, -1)
+	assertNil(t, err)
+
+	var keys = make(map[cipher.SHA256]struct{}) // keys of the first Root
+
+	assertNil(t, sc.Walk(r, func(key cipher.SHA256, _ int) (bool, error) {
+		keys[key] = struct{}{}
+		return true, nil
+	}))
+
+	// next Root
+
+	assertNil(t, feed.Posts.AppendValues(up, Post{Head: "one more"}))
+	assertNil(t, r.Refs[0].SetValue(up, &feed))
+	assertNil(t, sc.Save(up, r))
+
+	var fresh int // objects the rc doesn't have
+
+	assertNil(t, sc.Walk(r, func(key cipher.SHA256, _ int) (bool, error) {
+		if _, ok := keys[key]; ok == false {
+			fresh++
+		}
+		keys[key] = struct{}{}
+		return true, nil
+	}))
+
+	var prev *registry.Root
+	prev, err = rc.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+
+	var (
+		rq = make(chan cipher.SHA256, 10)
+		f  = rc.Fill(r, rq, 10)
+
+		requested int
+		wg        sync.WaitGroup
+	)
+
+	f.Delta(prev)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for key := range rq {
+			requested++
+
+			var val, _, err = sc.Get(key, 0)
+			assertNil(t, err)
+
+			_, err = rc.SetWanted(key, val)
+			assertNil(t, err)
+		}
+	}()
+
+	assertNil(t, f.Run())
+
+	close(rq)
+	wg.Wait()
+
+	var fp = f.Progress()
+
+	if fp.SkippedSubtrees == 0 {
+		t.Error("nothing skipped")
+	}
+
+	// the same objects of both Root objects (except the Root)
+	var same = len(keys) - fresh - 1
+
+	if fp.SkippedObjects < fp.SkippedSubtrees || fp.SkippedObjects > same {
+		t.Errorf("wrong number of skipped objects: %d, subtrees %d, same %d",
+			fp.SkippedObjects, fp.SkippedSubtrees, same)
+	}
+
+	if requested != fresh-1 { // except the Root
+		t.Errorf("wrong number of requested objects: %d, want %d",
+			requested, fresh-1)
+	}
+
+	if fp.Received != requested {
+		t.Error("wrong received:", fp.Received, requested)
+	}
+
+	// rc of objects of both Root objects
+
+	for key := range keys {
+		var src, rrc int
+		_, src, err = sc.Get(key, 0)
+		assertNil(t, err)
+		_, rrc, err = rc.Get(key, 0)
+		assertNil(t, err)
+		if src != rrc {
+			t.Error("wrong rc", rrc, src, key.Hex()[:7])
+		}
+	}
+
+}
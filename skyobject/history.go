@@ -0,0 +1,161 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// Historical Root objects are Root objects of a head
+// received from peers by request, verified but not
+// filled. The Index keeps only full Root objects, and
+// the historical Root objects are kept apart, in the
+// History of IdxDB, with signatures. A historical
+// Root removed when it's filled and added to the
+// Index, or when its head removed
+
+// AddHistory verifies given Root and saves it as
+// historical. The Root should be signed by owner of
+// its feed. If there is a historical Root with the
+// same nonce and seq, then the AddHistory does
+// nothing. It returns data.ErrNoSuchFeed if feed
+// of the Root doesn't exist
+func (i *Index) AddHistory(r *registry.Root) (err error) {
+
+	var sr data.SignedRoot
+	if sr, err = signedRoot(r); err != nil {
+		return
+	}
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[r.Pub]; ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	return i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		var hs data.History
+		if hs, err = feeds.History(r.Pub); err != nil {
+			return
+		}
+		return hs.Set(r.Nonce, r.Seq, &sr)
+	})
+
+}
+
+// DelHistory removes historical Root with given
+// feed, nonce and seq. It returns data.ErrNoSuchFeed
+// if the feed doesn't exist
+func (i *Index) DelHistory(pk cipher.PubKey, nonce, seq uint64) (err error) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[pk]; ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	return i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		var hs data.History
+		if hs, err = feeds.History(pk); err != nil {
+			return
+		}
+		return hs.Del(nonce, seq)
+	})
+
+}
+
+// History returns historical Root objects of given
+// head ordered by seq. The Root objects are not full
+// and can't be used. The History returns
+// data.ErrNoSuchFeed if given feed doesn't exist
+func (i *Index) History(
+	pk cipher.PubKey, //    : feed
+	nonce uint64, //        : head
+) (
+	rs []*registry.Root, // : historical Root objects
+	err error, //           : an error
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[pk]; ok == false {
+		return nil, data.ErrNoSuchFeed
+	}
+
+	err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+
+		var hs data.History
+		if hs, err = feeds.History(pk); err != nil {
+			return
+		}
+
+		return hs.Iterate(
+			func(hn, _ uint64, sr *data.SignedRoot) (err error) {
+
+				if hn != nonce {
+					return
+				}
+
+				var r *registry.Root
+				if r, err = decodeSignedRoot(*sr); err != nil {
+					return
+				}
+
+				rs = append(rs, r)
+				return
+			})
+
+	})
+
+	return
+}
+
+// IsHistorical returns true if Root with given
+// feed, nonce and seq is historical
+func (i *Index) IsHistorical(pk cipher.PubKey, nonce, seq uint64) (yep bool) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[pk]; ok == false {
+		return
+	}
+
+	i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		var hs data.History
+		if hs, err = feeds.History(pk); err != nil {
+			return
+		}
+		yep, err = hs.Has(nonce, seq)
+		return
+	})
+
+	return
+}
+
+// delHeadHistory removes historical Root objects
+// of given head; it's part of a transaction
+func delHeadHistory(
+	feeds data.Feeds, // :
+	pk cipher.PubKey, // :
+	nonce uint64, //     :
+) (
+	err error, //        :
+) {
+
+	var hs data.History
+	if hs, err = feeds.History(pk); err != nil {
+		return
+	}
+
+	return hs.Iterate(func(hn, seq uint64, _ *data.SignedRoot) (err error) {
+		if hn == nonce {
+			err = hs.Del(hn, seq)
+		}
+		return
+	})
+}
@@ -0,0 +1,96 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestIndex_history(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var saved []*registry.Root
+
+	for i := 0; i < 3; i++ {
+		var r = &registry.Root{Pub: pk, Nonce: 1}
+		assertNil(t, sc.Save(up, r))
+		var cp = *r
+		cp.IsFull = false
+		saved = append(saved, &cp)
+	}
+
+	// not verified
+
+	var forged = *saved[0]
+	forged.Sig = cipher.Sig{}
+
+	if err = rc.AddHistory(&forged); err == nil {
+		t.Error("missing error")
+	}
+
+	// add
+
+	for i := len(saved) - 1; i >= 0; i-- {
+		assertNil(t, rc.AddHistory(saved[i]))
+	}
+
+	var rs []*registry.Root
+	rs, err = rc.History(pk, 1)
+	assertNil(t, err)
+
+	if len(rs) != len(saved) {
+		t.Fatal("wrong number of historical Root objects:", len(rs))
+	}
+
+	for i, r := range rs {
+		if r.Hash != saved[i].Hash || r.Sig != saved[i].Sig ||
+			r.Seq != uint64(i) || r.IsFull == true {
+
+			t.Error("wrong historical Root:", i, r.Short())
+		}
+	}
+
+	// the Index is not changed
+
+	if _, err = rc.LastRoot(pk, 1); err == nil {
+		t.Error("historical Root in the Index")
+	}
+
+	// filled Root is not historical
+
+	if _, _, err = testFillLimit(t, sc, rc, rs[1], -1); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, rc.IsHistorical(pk, 1, 1) == false, "filled is historical")
+	assertTrue(t, rc.IsHistorical(pk, 1, 0) == true, "not historical")
+
+	// delete
+
+	assertNil(t, rc.DelHistory(pk, 1, 0))
+	assertTrue(t, rc.IsHistorical(pk, 1, 0) == false, "not deleted")
+
+	// delete head
+
+	assertNil(t, rc.DelHead(pk, 1))
+
+	rs, err = rc.History(pk, 1)
+	assertNil(t, err)
+
+	if len(rs) != 0 {
+		t.Error("historical Root objects of deleted head:", len(rs))
+	}
+
+}
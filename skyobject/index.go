@@ -14,6 +14,7 @@ import (
 // keep latest and tracked Root objects only
 type indexHeads struct {
 	h       map[uint64]*data.Root // last Root
+	eq      map[uint64]struct{}   // equivocated heads
 	activen uint64                // head with latest root (nonce)
 	activet int64                 // timestamp of the last root
 }
@@ -22,6 +23,7 @@ type indexHeads struct {
 func newIndexHeads() (hs *indexHeads) {
 	hs = new(indexHeads)
 	hs.h = make(map[uint64]*data.Root)
+	hs.eq = make(map[uint64]struct{})
 	return
 }
 
@@ -120,6 +122,22 @@ func (i *Index) load(c *Container) (err error) {
 				return
 			}
 
+			// equivocated heads
+
+			var es data.Equivocations
+			if es, err = feeds.Equivocations(pk); err != nil {
+				return
+			}
+
+			err = es.Iterate(func(e *data.Equivocation) (_ error) {
+				feedMap.eq[e.Nonce] = struct{}{}
+				return
+			})
+
+			if err != nil {
+				return
+			}
+
 			i.feeds[pk] = feedMap
 
 			return
@@ -295,7 +313,9 @@ func (i *Index) PreviewRoot(
 // root. The method changes nothing in DB, it
 // only checks the Root. The method set IsFull
 // field of the Root to true if DB already have
-// this Root
+// this Root. If DB has another Root with the
+// same seq, then the method saves evidence of
+// the equivocation and returns EquivocationError
 func (i *Index) ReceivedRoot(
 	pk cipher.PubKey,
 	sig cipher.Sig,
@@ -313,7 +333,12 @@ func (i *Index) ReceivedRoot(
 		return
 	}
 
-	if _, err = i.findRoot(r.Pub, r.Nonce, r.Seq); err == nil {
+	var dr *data.Root
+
+	if dr, err = i.findRoot(r.Pub, r.Nonce, r.Seq); err == nil {
+		if dr.Hash != r.Hash {
+			return nil, i.equivocation(dr, r)
+		}
 		r.IsFull = true
 		return
 	} else if err == data.ErrNoSuchHead || err == data.ErrNotFound {
@@ -348,6 +373,9 @@ func (i *Index) addRoot(r *registry.Root) (alreadyHave bool, err error) {
 
 	switch ir, err = i.findRoot(r.Pub, r.Nonce, r.Seq); {
 	case err == nil:
+		if ir.Hash != r.Hash {
+			return false, i.equivocation(ir, r)
+		}
 		ir.Access = time.Now().UnixNano()
 		alreadyHave = true
 		return
@@ -385,23 +413,39 @@ func (i *Index) addRoot(r *registry.Root) (alreadyHave bool, err error) {
 		dr.Sig = r.Sig
 		dr.Time = r.Time
 
-		return rs.Set(dr)
+		if err = rs.Set(dr); err != nil {
+			return
+		}
+
+		// the Root is not historical
+		var hr data.History
+		if hr, err = feeds.History(r.Pub); err != nil {
+			return
+		}
+
+		if err = hr.Del(r.Nonce, r.Seq); err != nil {
+			return
+		}
+
+		// the Root and older Root objects
+		// of the head are not pending
+		return delOutdatedPending(feeds, r.Pub, r.Nonce, r.Seq)
 	})
 
 	if err != nil {
 		return
 	}
 
-	if ir != nil && r.Seq < ir.Seq {
+	// add to the Index
+
+	var hs = i.feeds[r.Pub]
+
+	if last := hs.h[r.Nonce]; last != nil && r.Seq < last.Seq {
 		// don't add to the Index the fucking, old,
 		// outdated, never need, nobody need Root
 		return
 	}
 
-	// add to the Index
-
-	var hs = i.feeds[r.Pub]
-
 	// replace the last
 
 	hs.h[r.Nonce] = dr
@@ -445,7 +489,9 @@ func (i *Index) addSavedRoot(r *registry.Root, dr *data.Root) {
 // it returns alreadyHave reply instead. E.g. if the Container
 // already have this Root, then the alreadyHave reply will be
 // true. The method never save the Root inside CXDS. E.g. the
-// method adds the Root to index (that is necessary)
+// method adds the Root to index (that is necessary). If the
+// Container has another Root with the same seq, then the
+// method returns EquivocationError (see ReceivedRoot)
 func (i *Index) AddRoot(r *registry.Root) (alreadyHave bool, err error) {
 
 	i.mx.Lock()
@@ -648,7 +694,7 @@ func (i *Index) DelFeed(pk cipher.PubKey) (err error) {
 
 	// without lock
 	for _, hash := range rhs {
-		if err = i.delRootRelatedValues(hash); err != nil {
+		if err = i.delRootRelatedValues(hash, nil); err != nil {
 			return
 		}
 	}
@@ -698,6 +744,14 @@ func (i *Index) delHead(
 			return
 		}
 
+		if err = delHeadPending(feed, pk, nonce); err != nil {
+			return
+		}
+
+		if err = delHeadHistory(feed, pk, nonce); err != nil {
+			return
+		}
+
 		return hs.Del(nonce) // remove the head
 	})
 
@@ -742,7 +796,7 @@ func (i *Index) DelHead(pk cipher.PubKey, nonce uint64) (err error) {
 	// without lock
 
 	for _, hash := range rhs {
-		if err = i.delRootRelatedValues(hash); err != nil {
+		if err = i.delRootRelatedValues(hash, nil); err != nil {
 			return
 		}
 	}
@@ -865,6 +919,7 @@ func (i *Index) delRootLock(
 
 func (i *Index) delPackWalkFunc(
 	r *registry.Root, //           : Root
+	zero func(cipher.SHA256), //   : objects with zero rc (can be nil)
 ) (
 	pack registry.Pack, //         : special Pack for deleting
 	walkFunc registry.WalkFunc, // : walk deleting
@@ -901,6 +956,10 @@ func (i *Index) delPackWalkFunc(
 			dpack.last = hash
 			dpack.val = val
 
+			if zero != nil {
+				zero(hash)
+			}
+
 			deepper = true // and go deepper
 		}
 
@@ -916,7 +975,12 @@ func (i *Index) delPackWalkFunc(
 
 // delRootRelatedValues decrements all values related to
 // given Root, including the Root itself and its Registry
-func (i *Index) delRootRelatedValues(rootHash cipher.SHA256) (err error) {
+func (i *Index) delRootRelatedValues(
+	rootHash cipher.SHA256, //   : hash of the Root
+	zero func(cipher.SHA256), // : objects with zero rc (can be nil)
+) (
+	err error, //                : an error
+) {
 
 	var r *registry.Root
 	if r, err = i.c.rootByHash(rootHash); err != nil {
@@ -928,7 +992,7 @@ func (i *Index) delRootRelatedValues(rootHash cipher.SHA256) (err error) {
 		walkFunc registry.WalkFunc
 	)
 
-	if pack, walkFunc, err = i.delPackWalkFunc(r); err != nil {
+	if pack, walkFunc, err = i.delPackWalkFunc(r, zero); err != nil {
 		return
 	}
 
@@ -946,7 +1010,7 @@ func (i *Index) DelRoot(pk cipher.PubKey, nonce, seq uint64) (err error) {
 	}
 
 	// without lock
-	return i.delRootRelatedValues(rootHash)
+	return i.delRootRelatedValues(rootHash, nil)
 }
 
 // Feeds returns list of feeds. For performance
@@ -1131,3 +1195,99 @@ func (i *Index) Root(
 
 	return
 }
+
+// Roots returns Root objects of given head with seq numbers
+// in range [from, to] ascending order, but no more then the
+// limit (if the limit is positive). Missing Root objects are
+// skipped. The Roots returns Root objects with signatures
+func (i *Index) Roots(
+	feed cipher.PubKey, //  : feed
+	nonce uint64, //        : head
+	from uint64, //         : first seq
+	to uint64, //           : last seq
+	limit int, //           : max number of Root objects
+) (
+	rs []*registry.Root, // : the Root objects
+	err error, //           : an error
+) {
+
+	var drs []*data.Root
+
+	if drs, err = i.dataRoots(feed, nonce, from, to, limit); err != nil {
+		return
+	}
+
+	rs = make([]*registry.Root, 0, len(drs))
+
+	for _, dr := range drs {
+
+		var r *registry.Root
+		if r, err = i.c.rootByHash(dr.Hash); err != nil {
+			return nil, err
+		}
+
+		r.IsFull = true
+		r.Sig = dr.Sig
+
+		rs = append(rs, r)
+	}
+
+	return
+}
+
+func (i *Index) dataRoots(
+	pk cipher.PubKey,
+	nonce uint64,
+	from uint64,
+	to uint64,
+	limit int,
+) (
+	drs []*data.Root,
+	err error,
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs, ok = i.feeds[pk]
+
+	if ok == false {
+		return nil, data.ErrNoSuchFeed
+	}
+
+	var last *data.Root
+	if last, ok = hs.h[nonce]; ok == false {
+		return nil, data.ErrNoSuchHead
+	}
+
+	if last == nil || from > to || from > last.Seq {
+		return // blank head or nothing in the range
+	}
+
+	err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		var heads data.Heads
+		if heads, err = feeds.Heads(pk); err != nil {
+			return
+		}
+		var roots data.Roots
+		if roots, err = heads.Roots(nonce); err != nil {
+			return
+		}
+		return roots.Ascend(func(dr *data.Root) (err error) {
+			if dr.Seq < from {
+				return
+			}
+			if dr.Seq > to {
+				return data.ErrStopIteration
+			}
+			var cp = *dr // the dr can be reused by the Ascend
+			drs = append(drs, &cp)
+			if limit > 0 && len(drs) == limit {
+				return data.ErrStopIteration
+			}
+			return
+		})
+	})
+
+	return
+}
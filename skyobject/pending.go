@@ -0,0 +1,246 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// If filling of a Root breaks, then the Root becomes
+// pending. Objects of the Root already received are
+// kept in CXDS, even if their rc turns zero, and keys
+// of the objects are saved in IdxDB with the Root. A
+// next filling of the Root (or newer Root of the same
+// head) finds the objects in CXDS and requests only
+// missing objects. A pending Root removed when it
+// or newer Root of the same head added to the Index
+
+// under lock
+func (i *Index) addPending(
+	r *registry.Root, //      : the Root
+	keys []cipher.SHA256, // : objects received
+) (
+	err error, //             : an error
+) {
+
+	if _, ok := i.feeds[r.Pub]; ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	var dr = new(data.Root)
+
+	dr.Seq = r.Seq
+	dr.Prev = r.Prev
+	dr.Hash = r.Hash
+	dr.Sig = r.Sig
+	dr.Time = r.Time
+
+	return i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		var ps data.Pending
+		if ps, err = feeds.Pending(r.Pub); err != nil {
+			return
+		}
+		return ps.Set(r.Nonce, dr, keys)
+	})
+
+}
+
+// AddPending used by the Filler to mark given Root
+// as pending, and given objects as provisional. The
+// method returns data.ErrNoSuchFeed if feed of the
+// Root doesn't exist
+func (i *Index) AddPending(
+	r *registry.Root, //      : the Root
+	keys []cipher.SHA256, // : objects received
+) (
+	err error, //             : an error
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	return i.addPending(r, keys)
+}
+
+// DelPending removes pending Root with given feed,
+// nonce and seq. Filling of the Root can't be
+// continued after. The method doesn't remove
+// objects of the Root from CXDS. It returns
+// data.ErrNoSuchFeed if the feed doesn't exist
+func (i *Index) DelPending(pk cipher.PubKey, nonce, seq uint64) (err error) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[pk]; ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	return i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		var ps data.Pending
+		if ps, err = feeds.Pending(pk); err != nil {
+			return
+		}
+		return ps.Del(nonce, seq)
+	})
+
+}
+
+// delOutdatedPending removes pending Root objects
+// of given head with seq less or equal to given;
+// it's part of a transaction
+func delOutdatedPending(
+	feeds data.Feeds, // :
+	pk cipher.PubKey, // :
+	nonce uint64, //     :
+	seq uint64, //       :
+) (
+	err error, //        :
+) {
+
+	var ps data.Pending
+	if ps, err = feeds.Pending(pk); err != nil {
+		return
+	}
+
+	return ps.Iterate(func(pn uint64, dr *data.Root) (err error) {
+		if pn == nonce && dr.Seq <= seq {
+			err = ps.Del(pn, dr.Seq)
+		}
+		return
+	})
+}
+
+// delHeadPending removes pending Root objects
+// of given head; it's part of a transaction
+func delHeadPending(
+	feeds data.Feeds, // :
+	pk cipher.PubKey, // :
+	nonce uint64, //     :
+) (
+	err error, //        :
+) {
+
+	var ps data.Pending
+	if ps, err = feeds.Pending(pk); err != nil {
+		return
+	}
+
+	return ps.Iterate(func(pn uint64, dr *data.Root) (err error) {
+		if pn == nonce {
+			err = ps.Del(pn, dr.Seq)
+		}
+		return
+	})
+}
+
+// Pending returns pending Root objects of given
+// feed. The Root objects are not full and can't
+// be used. The Pending returns data.ErrNoSuchFeed
+// if given feed doesn't exist
+func (i *Index) Pending(pk cipher.PubKey) (rs []*registry.Root, err error) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[pk]; ok == false {
+		return nil, data.ErrNoSuchFeed
+	}
+
+	var drs []*data.Root
+
+	err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		var ps data.Pending
+		if ps, err = feeds.Pending(pk); err != nil {
+			return
+		}
+		return ps.Iterate(func(_ uint64, dr *data.Root) (_ error) {
+			var cp = *dr // the dr reused by the Iterate
+			drs = append(drs, &cp)
+			return
+		})
+	})
+
+	if err != nil {
+		return
+	}
+
+	rs = make([]*registry.Root, 0, len(drs))
+
+	for _, dr := range drs {
+
+		var r *registry.Root
+		if r, err = i.c.rootByHash(dr.Hash); err != nil {
+			return nil, err
+		}
+
+		r.Sig = dr.Sig
+
+		rs = append(rs, r)
+	}
+
+	return
+}
+
+// IsPending returns true if Root with given
+// feed, nonce and seq is pending
+func (i *Index) IsPending(pk cipher.PubKey, nonce, seq uint64) (yep bool) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[pk]; ok == false {
+		return
+	}
+
+	i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		var ps data.Pending
+		if ps, err = feeds.Pending(pk); err != nil {
+			return
+		}
+		yep, err = ps.Has(nonce, seq)
+		return
+	})
+
+	return
+}
+
+// ProvisionalObjects returns keys of objects
+// of all pending Root objects. Such objects
+// can have zero rc, but they should not be
+// removed from CXDS to continue filling later
+func (i *Index) ProvisionalObjects() (
+	keys map[cipher.SHA256]struct{},
+	err error,
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	keys = make(map[cipher.SHA256]struct{})
+
+	err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		return feeds.Iterate(func(pk cipher.PubKey) (err error) {
+
+			var ps data.Pending
+			if ps, err = feeds.Pending(pk); err != nil {
+				return
+			}
+
+			return ps.Iterate(func(nonce uint64, dr *data.Root) (err error) {
+
+				keys[dr.Hash] = struct{}{} // the Root is provisional too
+
+				return ps.Keys(nonce, dr.Seq,
+					func(key cipher.SHA256) (_ error) {
+						keys[key] = struct{}{}
+						return
+					})
+			})
+
+		})
+	})
+
+	return
+}
@@ -0,0 +1,158 @@
+package skyobject
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// fill given Root using the sc as source of
+// objects, the fill fails after limit objects
+// received (if the limit is not negative); it
+// returns number of requested objects
+// and progress of the filling
+func testFillLimit(
+	t *testing.T,
+	sc, rc *Container,
+	r *registry.Root,
+	limit int,
+) (
+	requested int,
+	fp FillingProgress,
+	err error,
+) {
+
+	var (
+		rq = make(chan cipher.SHA256, 10)
+		f  = rc.Fill(r, rq, 10)
+
+		wg sync.WaitGroup
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for key := range rq {
+
+			if requested == limit {
+				f.Fail(errors.New("limit reached"))
+				continue
+			}
+
+			requested++
+
+			var val, _, err = sc.Get(key, 0)
+			assertNil(t, err)
+
+			_, err = rc.SetWanted(key, val)
+			assertNil(t, err)
+		}
+
+	}()
+
+	err = f.Run()
+	fp = f.Progress()
+
+	close(rq)
+	wg.Wait()
+
+	return
+}
+
+func Test_fillingResume(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var feed = Feed{Head: "head"}
+
+	for i := 0; i < 100; i++ {
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+		}))
+	}
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	var hs []cipher.SHA256
+
+	assertNil(t, sc.Walk(r, func(key cipher.SHA256, _ int) (bool, error) {
+		hs = append(hs, key)
+		return true, nil
+	}))
+
+	var total = len(hs)
+
+	// break the filling
+
+	var requested int
+	if requested, _, err = testFillLimit(t, sc, rc, r, total/2); err == nil {
+		t.Fatal("missing error")
+	}
+
+	assertTrue(t, rc.IsPending(pk, r.Nonce, r.Seq), "not pending")
+
+	var rs []*registry.Root
+	rs, err = rc.Pending(pk)
+	assertNil(t, err)
+
+	if len(rs) != 1 || rs[0].Hash != r.Hash || rs[0].Sig != r.Sig {
+		t.Fatal("wrong pending Root objects:", rs)
+	}
+
+	var keys map[cipher.SHA256]struct{}
+	keys, err = rc.ProvisionalObjects()
+	assertNil(t, err)
+
+	if len(keys) < requested {
+		t.Error("missing provisional objects:", len(keys), requested)
+	}
+
+	// continue
+
+	var resumed int
+	resumed, _, err = testFillLimit(t, sc, rc, rs[0], -1)
+	assertNil(t, err)
+
+	if resumed+requested != total-1 { // except the Root
+		t.Errorf("objects requested twice: %d + %d, want %d", requested,
+			resumed, total-1)
+	}
+
+	assertTrue(t, rc.IsPending(pk, r.Nonce, r.Seq) == false, "still pending")
+
+	// the sc contains objects of the Root only once
+
+	for _, key := range hs {
+		var src, rrc int
+		_, src, err = sc.Get(key, 0)
+		assertNil(t, err)
+		_, rrc, err = rc.Get(key, 0)
+		assertNil(t, err)
+		if src != rrc {
+			t.Error("wrong rc", rrc, src, key.Hex()[:7])
+		}
+	}
+
+}
@@ -0,0 +1,181 @@
+package skyobject
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A FillPriority represents elements of Root.Refs
+// that should be filled first. The prioritized
+// elements are requested first, but the rest of
+// the Root is filled at the same time. Indices
+// out of the Root.Refs ignored.
+//
+// The ElementsFrom and ElementsTo limits every
+// registry.Refs of the prioritized elements. Only
+// elements of a Refs in the range are prioritized,
+// the rest are filled with the rest of the Root.
+// For example, to get first page of a large Refs
+// of an element. If the range is empty, then all
+// elements of the Refs are prioritized
+type FillPriority struct {
+	Refs []int // indices of Root.Refs
+	From int   // range of Root.Refs [From, To)
+	To   int   // (the range can be empty)
+
+	ElementsFrom int // range of elements of Refs
+	ElementsTo   int // [ElementsFrom, ElementsTo)
+}
+
+// indices returns sorted unique indices of the
+// FillPriority that are less than given length
+func (f *FillPriority) indices(length int) (is []int) {
+
+	var set = make(map[int]struct{})
+
+	for _, i := range f.Refs {
+		if i >= 0 && i < length {
+			set[i] = struct{}{}
+		}
+	}
+
+	for i := f.From; i < f.To && i < length; i++ {
+		if i >= 0 {
+			set[i] = struct{}{}
+		}
+	}
+
+	is = make([]int, 0, len(set))
+
+	for i := range set {
+		is = append(is, i)
+	}
+
+	sort.Ints(is)
+	return
+}
+
+// An OnPriorityFilledFunc represents callback that
+// called when prioritized element of Root.Refs is
+// filled. All prioritized objects of the element
+// are in DB, but the Root is not full yet
+type OnPriorityFilledFunc func(i int)
+
+// Prioritize sets priority of the filling. Objects
+// of prioritized elements are requested using given
+// prq channel, thus the channel can be read before
+// the rq channel of the Filler to request them ahead
+// of the rest. If the prq is nil, then the rq used.
+// Given callback (that can be nil) called every time
+// a prioritized element of Root.Refs filled. The
+// Prioritize should be called before the Run
+func (f *Filler) Prioritize(
+	fp FillPriority, //               : the priority
+	prq chan<- cipher.SHA256, //      : request prioritized objects
+	onFilled OnPriorityFilledFunc, // : callback
+) {
+
+	if prq == nil {
+		prq = f.rq
+	}
+
+	f.prq = prq
+	f.prior = fp.indices(len(f.r.Refs))
+	f.elementsFrom, f.elementsTo = fp.ElementsFrom, fp.ElementsTo
+	f.onPriorityFilled = onFilled
+}
+
+// order returns indices of Root.Refs in order
+// of filling: prioritized first, then the rest
+func (f *Filler) order() (prior, rest []int) {
+
+	prior = f.prior
+
+	var set = make(map[int]struct{}, len(prior))
+
+	for _, i := range prior {
+		set[i] = struct{}{}
+	}
+
+	rest = make([]int, 0, len(f.r.Refs)-len(prior))
+
+	for i := range f.r.Refs {
+		if _, ok := set[i]; ok == false {
+			rest = append(rest, i)
+		}
+	}
+
+	return
+}
+
+// a subtree is registry.PrioritySplitter of
+// a prioritized element of Root.Refs, it tracks
+// goroutines of the element to know when the
+// element is filled
+type subtree struct {
+	*Filler
+
+	await  sync.WaitGroup
+	failmx sync.Mutex
+	failed bool
+}
+
+// Pre request an object of the prioritized element
+// using the prq channel, see registry.Splitter
+func (s *subtree) Pre(key cipher.SHA256) (rc int, err error) {
+	return s.preRequest(key, s.prq)
+}
+
+// Get an object of the prioritized element using the
+// prq channel to request it, see registry.Splitter
+func (s *subtree) Get(key cipher.SHA256) (val []byte, rc int, err error) {
+	return s.getRequest(key, s.prq)
+}
+
+// Range implements registry.PrioritySplitter interface
+func (s *subtree) Range() (from, to int, rest registry.Splitter) {
+	return s.elementsFrom, s.elementsTo, s.Filler
+}
+
+// Fail the splitting
+func (s *subtree) Fail(err error) {
+	s.failmx.Lock()
+	s.failed = true
+	s.failmx.Unlock()
+
+	s.Filler.Fail(err)
+}
+
+// Go performs a task of the element
+func (s *subtree) Go(fn func()) {
+	s.await.Add(1)
+	s.Filler.Go(func() {
+		defer s.await.Done()
+		fn()
+	})
+}
+
+// wait for the element and call the
+// onPriorityFilled if it's filled
+func (s *subtree) notify(i int) {
+	defer s.Filler.await.Done()
+
+	s.await.Wait()
+
+	s.failmx.Lock()
+	defer s.failmx.Unlock()
+
+	if s.failed == true || s.onPriorityFilled == nil {
+		return
+	}
+
+	select {
+	case <-s.closeq:
+	default:
+		s.onPriorityFilled(i)
+	}
+}
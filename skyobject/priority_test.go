@@ -0,0 +1,264 @@
+package skyobject
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestFillPriority_indices(t *testing.T) {
+
+	for _, tc := range []struct {
+		fp     FillPriority
+		length int
+		want   []int
+	}{
+		{FillPriority{}, 10, []int{}},
+		{FillPriority{Refs: []int{3, 1, 3}}, 10, []int{1, 3}},
+		{FillPriority{From: 2, To: 4}, 10, []int{2, 3}},
+		{FillPriority{Refs: []int{5}, From: 0, To: 2}, 10, []int{0, 1, 5}},
+		{FillPriority{Refs: []int{-1, 10}, From: 8, To: 20}, 10, []int{8, 9}},
+	} {
+		if got := tc.fp.indices(tc.length); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("wrong indices of %v: %v, want %v", tc.fp, got, tc.want)
+		}
+	}
+
+}
+
+func TestFiller_Prioritize(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+
+	for i := 0; i < 4; i++ {
+
+		var feed Feed
+
+		for j := 0; j < 20; j++ {
+			assertNil(t, feed.Posts.AppendValues(up, Post{
+				Head: fmt.Sprintf("Head #%d-%d", i, j),
+			}))
+		}
+
+		r.Refs = append(r.Refs,
+			createDynamic(up, testRegistry, "test.Feed", &feed))
+	}
+
+	assertNil(t, sc.Save(up, r))
+	r.IsFull = false // the Save sets it
+
+	// objects of the prioritized elements
+
+	var prior = make(map[cipher.SHA256]struct{})
+
+	var sp *Pack
+	sp, err = sc.Pack(r, testRegistry)
+	assertNil(t, err)
+
+	for _, i := range []int{0, 1, 3} {
+		assertNil(t, r.Refs[i].Walk(sp,
+			func(key cipher.SHA256, _ int) (bool, error) {
+				prior[key] = struct{}{}
+				return true, nil
+			}))
+	}
+
+	var (
+		rq  = make(chan cipher.SHA256, 10)
+		prq = make(chan cipher.SHA256, 10)
+		f   = rc.Fill(r, rq, 10)
+
+		filled []int
+		mx     sync.Mutex
+		wg     sync.WaitGroup
+	)
+
+	var pack *Pack
+	pack, err = rc.Pack(r, testRegistry)
+	assertNil(t, err)
+
+	var fp = FillPriority{Refs: []int{3}, From: 0, To: 2}
+
+	f.Prioritize(fp, prq, func(i int) {
+		mx.Lock()
+		filled = append(filled, i)
+		mx.Unlock()
+
+		// all objects of the element should be available
+		err := r.Refs[i].Walk(pack, func(cipher.SHA256, int) (bool, error) {
+			return true, nil
+		})
+
+		if err != nil {
+			t.Errorf("element %d is not filled: %v", i, err)
+		}
+
+		if r.IsFull == true {
+			t.Error("full Root before prioritized elements")
+		}
+	})
+
+	var requested = make(map[cipher.SHA256]bool) // key -> prioritized
+
+	var serve = func(rq <-chan cipher.SHA256, prioritized bool) {
+		defer wg.Done()
+
+		for key := range rq {
+			mx.Lock()
+			requested[key] = prioritized
+			mx.Unlock()
+
+			var val, _, err = sc.Get(key, 0)
+			assertNil(t, err)
+
+			_, err = rc.SetWanted(key, val)
+			assertNil(t, err)
+		}
+	}
+
+	wg.Add(2)
+	go serve(rq, false)
+	go serve(prq, true)
+
+	assertNil(t, f.Run())
+
+	close(rq)
+	close(prq)
+	wg.Wait()
+
+	for key, prioritized := range requested {
+		if _, ok := prior[key]; ok != prioritized {
+			t.Error("wrong queue of object:", key.Hex()[:7], prioritized)
+		}
+	}
+
+	sort.Ints(filled) // filled at the same time
+
+	if want := []int{0, 1, 3}; !reflect.DeepEqual(filled, want) {
+		t.Errorf("wrong prioritized elements: %v, want %v", filled, want)
+	}
+
+	assertTrue(t, r.IsFull, "not full")
+
+	assertNil(t, r.Walk(pack, func(cipher.SHA256, int) (bool, error) {
+		return true, nil
+	}))
+
+}
+
+func TestFiller_PrioritizeElements(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	assertNil(t, up.SetDegree(2)) // deep Refs tree
+
+	var (
+		feed Feed
+		r    = &registry.Root{Pub: pk, Nonce: 1}
+	)
+
+	for j := 0; j < 40; j++ {
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", j),
+		}))
+	}
+
+	r.Refs = append(r.Refs,
+		createDynamic(up, testRegistry, "test.Feed", &feed))
+
+	assertNil(t, sc.Save(up, r))
+	r.IsFull = false // the Save sets it
+
+	var (
+		rq = make(chan cipher.SHA256, 10)
+		f  = rc.Fill(r, rq, 10)
+
+		called = make(chan int, 1)
+		wg     sync.WaitGroup
+	)
+
+	var pack *Pack
+	pack, err = rc.Pack(r, testRegistry)
+	assertNil(t, err)
+
+	var fp = FillPriority{Refs: []int{0}, ElementsFrom: 17, ElementsTo: 23}
+
+	f.Prioritize(fp, nil, func(i int) {
+		called <- i
+
+		var feed Feed
+		if err := r.Refs[i].Value(pack, &feed); err != nil {
+			t.Error("can't get prioritized element:", err)
+			return
+		}
+
+		for j := fp.ElementsFrom; j < fp.ElementsTo; j++ {
+			var post Post
+			if _, err := feed.Posts.ValueByIndex(pack, j, &post); err != nil {
+				t.Errorf("prioritized post %d is not filled: %v", j, err)
+			} else if want := fmt.Sprintf("Head #%d", j); post.Head != want {
+				t.Errorf("wrong post %d: %q, want %q", j, post.Head, want)
+			}
+		}
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for key := range rq {
+			var val, _, err = sc.Get(key, 0)
+			assertNil(t, err)
+
+			_, err = rc.SetWanted(key, val)
+			assertNil(t, err)
+		}
+	}()
+
+	assertNil(t, f.Run())
+
+	close(rq)
+	wg.Wait()
+
+	select {
+	case i := <-called:
+		if i != 0 {
+			t.Error("wrong prioritized element:", i)
+		}
+	default:
+		t.Error("OnPriorityFilled not called")
+	}
+
+	assertTrue(t, r.IsFull, "not full")
+
+	assertNil(t, r.Walk(pack, func(cipher.SHA256, int) (bool, error) {
+		return true, nil
+	}))
+
+}
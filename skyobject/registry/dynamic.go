@@ -151,6 +151,14 @@ func (d *Dynamic) Walk(
 
 // Split used by the node package to fill the Dynamic.
 func (d *Dynamic) Split(s Splitter) {
+	d.SplitDelta(s, Dynamic{})
+}
+
+// SplitDelta is the same as the Split, but it compares
+// the Dynamic with given Dynamic of previous Root of the
+// same head. Subtrees with the same hashes are skipped.
+// See Previous and Skip methods of the Splitter
+func (d *Dynamic) SplitDelta(s Splitter, prev Dynamic) {
 
 	if d.IsValid() == false {
 		s.Fail(ErrInvalidDynamicReference)
@@ -168,6 +176,10 @@ func (d *Dynamic) Split(s Splitter) {
 		return
 	}
 
-	splitSchemaHash(s, sch, d.Hash)
+	if prev.Schema != d.Schema {
+		prev = Dynamic{} // different types, can't compare
+	}
+
+	splitSchemaHash(s, sch, d.Hash, prev.Hash)
 
 }
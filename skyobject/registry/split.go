@@ -48,6 +48,32 @@ type Splitter interface {
 	// Fail the splitting
 	Fail(err error)
 
+	// Expect used to estimate total number of objects,
+	// the Split calls it with length of every Refs it
+	// loads, and then with negative number of elements
+	// it reaches or skips
+	Expect(n int)
+
+	//
+	// delta
+	//
+
+	// Previous returns Pack of previous full Root of
+	// the same head, if the Root should be compared
+	// with the previous one, or nil. Subtrees with the
+	// same hashes are not walked (see Skip)
+	Previous() (pack Pack)
+
+	// Skip used instead of the Get for an object that
+	// is the same in the previous Root. The Skip
+	// increments rc of the object like the Get, but
+	// doesn't load it. It returns false if the object
+	// can't be skipped and should be loaded. The objects
+	// is number of objects of the subtree known without
+	// loading it (the object itself and elements of a
+	// Refs), it's used for statistic only
+	Skip(key cipher.SHA256, objects int) (ok bool)
+
 	//
 	// goroutines limit and waiting
 	//
@@ -58,24 +84,46 @@ type Splitter interface {
 	Go(func())
 }
 
+// value of previous Root or nil
+func splitPrevious(s Splitter, hash cipher.SHA256) (val []byte) {
+
+	if hash == (cipher.SHA256{}) {
+		return
+	}
+
+	var pack Pack
+	if pack = s.Previous(); pack == nil {
+		return // no delta
+	}
+
+	val, _ = pack.Get(hash) // nil if not found
+	return
+}
+
 func splitSchemaHashAsync(
 	s Splitter, //         : splitter
 	sch Schema, //         : schema of the object
 	hash cipher.SHA256, // : hash of the object
+	prev cipher.SHA256, // : hash of object of previous Root
 ) {
-	s.Go(func() { splitSchemaHash(s, sch, hash) })
+	s.Go(func() { splitSchemaHash(s, sch, hash, prev) })
 }
 
 func splitSchemaHash(
 	s Splitter, //         : splitter
 	sch Schema, //         : schema of the object
 	hash cipher.SHA256, // : hash of the object
+	prev cipher.SHA256, // : hash of object of previous Root
 ) {
 
 	if hash == (cipher.SHA256{}) {
 		return // nothing to split
 	}
 
+	if hash == prev && s.Skip(hash, 1) == true {
+		return // the same subtree in previous Root
+	}
+
 	var (
 		rc  int
 		val []byte
@@ -87,13 +135,13 @@ func splitSchemaHash(
 		return
 	}
 
-	if rc > 1 {
-		return
+	if rc > 0 {
+		return // entire subtree is already in DB or is filling
 	}
 
 	// go deepper
 
-	splitSchemaData(s, sch, val)
+	splitSchemaData(s, sch, val, splitPrevious(s, prev))
 
 }
 
@@ -101,14 +149,16 @@ func splitSchemaDataAsync(
 	s Splitter, // :
 	sch Schema, // :
 	val []byte, // :
+	pv []byte, //  :
 ) {
-	s.Go(func() { splitSchemaData(s, sch, val) })
+	s.Go(func() { splitSchemaData(s, sch, val, pv) })
 }
 
 func splitSchemaData(
 	s Splitter, // :
 	sch Schema, // : schema of the object
 	val []byte, // : encoded object
+	pv []byte, //  : encoded object of previous Root or nil
 ) {
 
 	if sch.HasReferences() == false {
@@ -117,17 +167,17 @@ func splitSchemaData(
 
 	// the object represents Ref, Refs or Dynamic
 	if sch.IsReference() == true {
-		splitSchemaReference(s, sch, val)
+		splitSchemaReference(s, sch, val, pv)
 		return
 	}
 
 	switch sch.Kind() {
 	case reflect.Array:
-		splitArray(s, sch, val)
+		splitArray(s, sch, val, pv)
 	case reflect.Slice:
-		splitSlice(s, sch, val)
+		splitSlice(s, sch, val, pv)
 	case reflect.Struct:
-		splitStruct(s, sch, val)
+		splitStruct(s, sch, val, pv)
 	default:
 		s.Fail(fmt.Errorf("invalid Schema to walk through: %s", sch))
 	}
@@ -138,10 +188,15 @@ func splitSchemaReference(
 	s Splitter, // :
 	sch Schema, // : schema of the object
 	val []byte, // : encoded reference
+	pv []byte, //  : encoded reference of previous Root or nil
 ) {
 
 	var err error
 
+	// the pv is used to find the same subtrees, and if it
+	// can't be decoded, then the reference is splitted
+	// as is (without comparison)
+
 	switch rt := sch.ReferenceType(); rt {
 
 	case ReferenceTypeSingle: // Ref
@@ -152,13 +207,17 @@ func splitSchemaReference(
 			return
 		}
 
-		var ref Ref
+		var ref, prev Ref
 		if err = encoder.DeserializeRaw(val, &ref); err != nil {
 			s.Fail(err)
 			return
 		}
 
-		ref.Split(s, el)
+		if pv != nil {
+			encoder.DeserializeRaw(pv, &prev)
+		}
+
+		splitSchemaHashAsync(s, el, ref.Hash, prev.Hash)
 
 	case ReferenceTypeSlice: // Refs
 
@@ -174,17 +233,28 @@ func splitSchemaReference(
 			return
 		}
 
-		refs.Split(s, el)
+		var prev *Refs
+		if pv != nil {
+			if prev = new(Refs); encoder.DeserializeRaw(pv, prev) != nil {
+				prev = nil
+			}
+		}
+
+		refs.split(s, el, prev)
 
 	case ReferenceTypeDynamic: // Dynamic
 
-		var dr Dynamic
+		var dr, prev Dynamic
 		if err = encoder.DeserializeRaw(val, &dr); err != nil {
 			s.Fail(err)
 			return
 		}
 
-		dr.Split(s)
+		if pv != nil {
+			encoder.DeserializeRaw(pv, &prev)
+		}
+
+		dr.SplitDelta(s, prev)
 
 	default:
 
@@ -198,6 +268,7 @@ func splitArray(
 	s Splitter, // : pack to get
 	sch Schema, // : schema of the array
 	val []byte, // : encoded array
+	pv []byte, //  : encoded array of previous Root or nil
 ) {
 
 	var el Schema // Schema of the element
@@ -206,7 +277,12 @@ func splitArray(
 		return
 	}
 
-	splitArraySlice(s, el, sch.Len(), val)
+	var pln int
+	if pv != nil {
+		pln = sch.Len()
+	}
+
+	splitArraySlice(s, el, sch.Len(), val, pln, pv)
 
 }
 
@@ -214,6 +290,7 @@ func splitSlice(
 	s Splitter, // : pack to get
 	sch Schema, // : schema of the slice
 	val []byte, // : encoded slice
+	pv []byte, //  : encoded slice of previous Root or nil
 ) {
 
 	var (
@@ -232,7 +309,16 @@ func splitSlice(
 		return
 	}
 
-	splitArraySlice(s, el, ln, val[4:])
+	var pln int
+	if pv != nil {
+		if pln, err = getLength(pv); err != nil {
+			pln, pv = 0, nil
+		} else {
+			pv = pv[4:]
+		}
+	}
+
+	splitArraySlice(s, el, ln, val[4:], pln, pv)
 }
 
 func splitArraySlice(
@@ -240,6 +326,8 @@ func splitArraySlice(
 	el Schema, //  : shcema of an element
 	ln int, //     : length of the array or slice (> 0)
 	val []byte, // : encoded array or slice starting from first element
+	pln int, //    : length of array or slice of previous Root
+	pv []byte, //  : encoded array or slice of previous Root or nil
 ) {
 
 	// doesn't need to walk through the zero-length
@@ -252,6 +340,8 @@ func splitArraySlice(
 	var (
 		shift, m int
 		err      error
+
+		pshift int
 	)
 
 	for i := 0; i < ln; i++ {
@@ -268,8 +358,17 @@ func splitArraySlice(
 			return
 		}
 
+		// element of previous Root
+		var pe []byte
+		if i < pln && pv != nil {
+			if pe, err = splitElement(el, pv, pshift); err != nil {
+				pe, pv, err = nil, nil, nil // can't compare
+			}
+			pshift += len(pe)
+		}
+
 		// split
-		splitSchemaDataAsync(s, el, val[shift:shift+m])
+		splitSchemaDataAsync(s, el, val[shift:shift+m], pe)
 
 		shift += m
 
@@ -283,11 +382,14 @@ func splitStruct(
 	s Splitter, // : pack to get
 	sch Schema, // : schema of the struct
 	val []byte, // : encoded struct
+	pv []byte, //  : encoded struct of previous Root or nil
 ) {
 
 	var (
 		shift, z int
 		err      error
+
+		pshift int
 	)
 
 	for i, fl := range sch.Fields() {
@@ -308,10 +410,47 @@ func splitStruct(
 			return
 		}
 
-		splitSchemaDataAsync(s, fl.Schema(), val[shift:shift+z])
+		// field of previous Root
+		var pf []byte
+		if pv != nil {
+			if pf, err = splitElement(fl.Schema(), pv, pshift); err != nil {
+				pf, pv, err = nil, nil, nil // can't compare
+			}
+			pshift += len(pf)
+		}
+
+		splitSchemaDataAsync(s, fl.Schema(), val[shift:shift+z], pf)
 
 		shift += z
 
 	}
 
 }
+
+// element or field of previous Root starting
+// from given shift; the pv can be broken
+func splitElement(
+	sch Schema, // : schema of the element
+	pv []byte, //  : encoded elements
+	shift int, //  : start of the element
+) (
+	pe []byte, //  : the element
+	err error, //  : an error
+) {
+
+	if shift > len(pv) {
+		return nil, ErrInvalidEncodedSchema
+	}
+
+	var z int
+	if z, err = sch.Size(pv[shift:]); err != nil {
+		return
+	}
+
+	if shift+z > len(pv) {
+		return nil, ErrInvalidEncodedSchema
+	}
+
+	pe = pv[shift : shift+z]
+	return
+}
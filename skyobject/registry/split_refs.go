@@ -52,12 +52,67 @@ func (r *Refs) splitHash(
 	return (rc == 0) // laod if hard rc == 0
 }
 
+// A PrioritySplitter is a Splitter that splits
+// prioritized elements of every Refs it reaches.
+// Elements of a Refs in range [from, to) splitted
+// by the PrioritySplitter, and the rest elements
+// by the rest Splitter at the same time. If the
+// range is empty, then all elements splitted by
+// the PrioritySplitter
+type PrioritySplitter interface {
+	Splitter
+
+	// Range returns range of prioritized elements
+	// and Splitter for the rest elements
+	Range() (from, to int, rest Splitter)
+}
+
+// range of prioritized elements of a Refs
+type splitRange struct {
+	from, to int       // [from, to)
+	rest     *fakePack // for elements out of the range
+}
+
+// range of given Splitter or nil
+func newSplitRange(s Splitter) (sr *splitRange) {
+
+	var ps, ok = s.(PrioritySplitter)
+
+	if ok == false {
+		return
+	}
+
+	var from, to, rest = ps.Range()
+
+	if from < 0 {
+		from = 0
+	}
+
+	if to <= from {
+		return // all elements are prioritized
+	}
+
+	return &splitRange{from, to, &fakePack{rest}}
+}
+
+// is the range intersects with
+// elements [start, start+length)
+func (s *splitRange) intersects(start, length int) bool {
+	return start < s.to && s.from < start+length
+}
+
 // Split used by the node package to fill the Dynamic.
 // Unlike the Walk the Split desigend for fresh Refs
 // received by network the the Split never reset the
 // Refs if it loads it and never updates hashes of
 // the Refs if they are not actual
 func (r *Refs) Split(s Splitter, el Schema) {
+	r.split(s, el, nil)
+}
+
+// split the Refs comparing it with Refs of
+// previous Root (that can be nil)
+func (r *Refs) split(s Splitter, el Schema, prev *Refs) {
 
 	var fp = fakePack{s} // fake Pack
 
@@ -67,6 +122,12 @@ func (r *Refs) Split(s Splitter, el Schema) {
 
 	// first of all, check the Refs.Hash
 
+	if prev != nil && prev.Hash == r.Hash &&
+		s.Skip(r.Hash, splitPreviousObjects(s, r.Hash)) == true {
+
+		return // the same Refs in previous Root
+	}
+
 	if r.splitHash(&fp, r.Hash) == false {
 		return
 	}
@@ -77,30 +138,103 @@ func (r *Refs) Split(s Splitter, el Schema) {
 		return
 	}
 
-	r.splitNode(&fp, el, r.refsNode, r.depth)
+	s.Expect(r.length)
+
+	var pn *refsNode
+	if prev = r.splitPrevious(s, prev); prev != nil {
+		pn = prev.refsNode
+	}
+
+	r.splitNode(&fp, newSplitRange(s), el, r.refsNode, r.depth, 0, prev, pn)
 
 }
 
+// number of objects of Refs of previous Root
+// known without loading the tree: the Refs
+// itself and its elements
+func splitPreviousObjects(s Splitter, hash cipher.SHA256) (objects int) {
+
+	objects = 1
+
+	var pack Pack
+	if pack = s.Previous(); pack == nil {
+		return
+	}
+
+	var er encodedRefs
+	if get(pack, hash, &er) == nil {
+		objects += int(er.Length)
+	}
+
+	return
+}
+
+// load Refs of previous Root to compare
+// with, it returns nil if the Refs can't
+// be compared
+func (r *Refs) splitPrevious(s Splitter, prev *Refs) (loaded *Refs) {
+
+	if prev == nil || prev.Hash == (cipher.SHA256{}) {
+		return
+	}
+
+	var pack Pack
+	if pack = s.Previous(); pack == nil {
+		return
+	}
+
+	if err := prev.initialize(pack); err != nil {
+		return
+	}
+
+	if prev.depth != r.depth {
+		return // different trees
+	}
+
+	return prev
+}
+
 func (r *Refs) splitNodeAsync(
-	fp *fakePack, // : fake pack to load
-	sch Schema, //   : schema of elements
-	rn *refsNode, // : the node
-	depth int, //    : depth of the node
+	fp *fakePack, //   : fake pack to load
+	sr *splitRange, // : prioritized elements or nil
+	sch Schema, //     : schema of elements
+	rn *refsNode, //   : the node
+	depth int, //      : depth of the node
+	start int, //      : index of first element of the node
+	prev *Refs, //     : Refs of previous Root or nil
+	pn *refsNode, //   : node of previous Root or nil
 ) {
-	fp.s.Go(func() { r.splitNode(fp, sch, rn, depth) })
+	fp.s.Go(func() { r.splitNode(fp, sr, sch, rn, depth, start, prev, pn) })
 }
 
 func (r *Refs) splitNode(
-	fp *fakePack, // : fake pack to load
-	sch Schema, //   : schema of elements
-	rn *refsNode, // : the node
-	depth int, //    : depth of the node
+	fp *fakePack, //   : fake pack to load
+	sr *splitRange, // : prioritized elements or nil
+	sch Schema, //     : schema of elements
+	rn *refsNode, //   : the node
+	depth int, //      : depth of the node
+	start int, //      : index of first element of the node
+	prev *Refs, //     : Refs of previous Root or nil
+	pn *refsNode, //   : node of previous Root or nil
 ) {
 
 	if depth == 0 {
 
-		for _, leaf := range rn.leafs {
-			splitSchemaHashAsync(fp.s, sch, leaf.Hash)
+		fp.s.Expect(-len(rn.leafs))
+
+		for i, leaf := range rn.leafs {
+
+			var ph cipher.SHA256
+			if pn != nil && i < len(pn.leafs) {
+				ph = pn.leafs[i].Hash
+			}
+
+			var s = fp.s
+			if sr != nil && sr.intersects(start+i, 1) == false {
+				s = sr.rest.s // not prioritized
+			}
+
+			splitSchemaHashAsync(s, sch, leaf.Hash, ph)
 		}
 
 		return
@@ -108,10 +242,30 @@ func (r *Refs) splitNode(
 
 	// else if depth > 0 -> { branches }
 
-	var toSplit []*refsNode // data-race protection
+	var (
+		toSplit []*refsNode // data-race protection
+		prevs   []*refsNode // branches of previous Root
+		starts  []int       // indices of first elements
+	)
+
+	for i, br := range rn.branches {
+
+		var pbr *refsNode
+		if pn != nil && i < len(pn.branches) {
+			pbr = pn.branches[i]
+		}
+
+		if pbr != nil && pbr.hash == br.hash &&
+			fp.s.Skip(br.hash, 1+pbr.length) == true {
+
+			prev.skipNode(fp.s, fp.s.Previous(), pbr, depth-1)
+			start += pbr.length
+			continue // the same branch in previous Root
+		}
 
-	for _, br := range rn.branches {
 		if r.splitHash(fp, br.hash) == false {
+			r.skipNode(fp.s, fp, br, depth-1)
+			start += br.length
 			continue
 		}
 
@@ -120,13 +274,52 @@ func (r *Refs) splitNode(
 			return
 		}
 
+		if pbr != nil {
+			if prev.loadNodeIfNeed(fp.s.Previous(), pbr, depth-1) != nil {
+				pbr = nil // can't compare
+			}
+		}
+
 		toSplit = append(toSplit, br)
+		prevs = append(prevs, pbr)
+		starts = append(starts, start)
+
+		start += br.length
 	}
 
 	// data-race protection: load first, then split
 
-	for _, br := range toSplit {
-		r.splitNodeAsync(fp, sch, br, depth-1)
+	for i, br := range toSplit {
+
+		if sr != nil && sr.intersects(starts[i], br.length) == false {
+			// entire branch is not prioritized
+			r.splitNodeAsync(sr.rest, nil, sch, br, depth-1, starts[i],
+				prev, prevs[i])
+			continue
+		}
+
+		r.splitNodeAsync(fp, sr, sch, br, depth-1, starts[i], prev, prevs[i])
+	}
+
+}
+
+// the branch is already in DB and will not be splitted,
+// but elements of the branch are expected, thus we have
+// to load the branch to get its length
+func (r *Refs) skipNode(
+	s Splitter, //   : the splitter
+	pack Pack, //    : pack to load
+	br *refsNode, // : the branch
+	depth int, //    : depth of the branch
+) {
+
+	if br.hash == (cipher.SHA256{}) {
+		return // blank
+	}
+
+	if err := r.loadNodeIfNeed(pack, br, depth); err != nil {
+		return // the failure reported by splitHash
 	}
 
+	s.Expect(-br.length)
 }
@@ -0,0 +1,279 @@
+package skyobject
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// A Retention represents retention policy of Root
+// objects of a feed. The policy applied to every
+// head of the feed. A Root removed if it is not one
+// of last KeepLast Root objects of its head or if it
+// saved (or received) earlier then KeepFor ago. Last
+// Root of a head is never removed. Objects the removed
+// Root objects no longer reference are removed too.
+// Zero value of a field means no limit, and zero
+// Retention keeps all Root objects
+type Retention struct {
+	KeepLast int           // keep last n Root objects of a head
+	KeepFor  time.Duration // keep Root objects saved during
+}
+
+// IsZero returns true if the Retention keeps all
+func (r Retention) IsZero() bool {
+	return r.KeepLast == 0 && r.KeepFor == 0
+}
+
+// Validate the Retention
+func (r Retention) Validate() (err error) {
+	if r.KeepLast < 0 {
+		return errors.New("(skyobject.Retention.Validate) negative KeepLast")
+	}
+	if r.KeepFor < 0 {
+		return errors.New("(skyobject.Retention.Validate) negative KeepFor")
+	}
+	return
+}
+
+// is the Root (k-th of n Root objects of
+// a head) out of the Retention
+func (r Retention) isOld(dr *data.Root, k, n int, now int64) bool {
+
+	if k == n-1 {
+		return false // the last Root
+	}
+
+	if r.KeepLast > 0 && n-k > r.KeepLast {
+		return true
+	}
+
+	return r.KeepFor > 0 && now-dr.Create > int64(r.KeepFor)
+}
+
+// initialize retention policies and start
+// background goroutine if it's necessary
+func (c *Container) initRetention(conf *Config) {
+
+	c.rtn = make(map[cipher.PubKey]Retention, len(conf.FeedRetention))
+
+	for pk, rt := range conf.FeedRetention {
+		c.rtn[pk] = rt
+	}
+
+	c.closeq = make(chan struct{})
+
+	if conf.RetentionInterval > 0 {
+		c.await.Add(1)
+		go c.retentionLoop(conf.RetentionInterval)
+	}
+
+}
+
+// stop the background goroutine
+func (c *Container) closeRetention() {
+	close(c.closeq)
+	c.await.Wait()
+}
+
+func (c *Container) retentionLoop(interval time.Duration) {
+	defer c.await.Done()
+
+	var tk = time.NewTicker(interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			if err := c.ApplyRetention(); err != nil {
+				log.Print(Prefix, "[ERR] retention: ", err)
+			}
+		case <-c.closeq:
+			return
+		}
+	}
+
+}
+
+// SetRetention sets retention policy of given feed,
+// overriding default one (see Retention field of the
+// Config). Zero Retention keeps all Root objects of
+// the feed. The feed can be added to the Container
+// later. The policy is not saved in DB and lost after
+// restart. Use FeedRetention field of the Config to
+// keep it
+func (c *Container) SetRetention(pk cipher.PubKey, rt Retention) (err error) {
+
+	if err = rt.Validate(); err != nil {
+		return
+	}
+
+	c.rmx.Lock()
+	defer c.rmx.Unlock()
+
+	c.rtn[pk] = rt
+	return
+}
+
+// Retention returns retention policy of given feed
+func (c *Container) Retention(pk cipher.PubKey) (rt Retention) {
+
+	c.rmx.Lock()
+	defer c.rmx.Unlock()
+
+	var ok bool
+	if rt, ok = c.rtn[pk]; ok == false {
+		rt = c.conf.Retention // default
+	}
+
+	return
+}
+
+// ApplyRetention removes Root objects that are out of
+// retention policies of their feeds (see Retention)
+// and objects the Root objects no longer reference.
+// The Container calls the method in background using
+// RetentionInterval of the Config. Objects of pending
+// and filling Root objects are kept
+func (c *Container) ApplyRetention() (err error) {
+
+	var zero []cipher.SHA256 // objects with zero rc
+
+	for _, pk := range c.Feeds() {
+
+		var rt = c.Retention(pk)
+
+		if rt.IsZero() == true {
+			continue
+		}
+
+		if zero, err = c.applyRetention(pk, rt, zero); err != nil {
+			return
+		}
+
+	}
+
+	if len(zero) == 0 {
+		return
+	}
+
+	var keep map[cipher.SHA256]struct{}
+	if keep, err = c.ProvisionalObjects(); err != nil {
+		return
+	}
+
+	return c.Cache.delOwnerless(zero, keep)
+}
+
+// remove Root objects of given feed
+func (c *Container) applyRetention(
+	pk cipher.PubKey, //          : feed
+	rt Retention, //              : policy
+	zero []cipher.SHA256, //      : objects with zero rc
+) (
+	_ []cipher.SHA256, //         : appended objects with zero rc
+	err error, //                 : an error
+) {
+
+	var heads []uint64
+	if heads, err = c.Heads(pk); err == data.ErrNoSuchFeed {
+		return zero, nil // removed
+	} else if err != nil {
+		return zero, err
+	}
+
+	var (
+		now     = time.Now().UnixNano()
+		addZero = func(key cipher.SHA256) { zero = append(zero, key) }
+	)
+
+	for _, nonce := range heads {
+
+		var drs []*data.Root
+
+		drs, err = c.dataRoots(pk, nonce, 0, math.MaxUint64, 0)
+
+		switch err {
+		case nil:
+		case data.ErrNoSuchFeed, data.ErrNoSuchHead:
+			err = nil
+			continue // removed
+		default:
+			return zero, err
+		}
+
+		for k, dr := range drs {
+
+			if rt.isOld(dr, k, len(drs), now) == false {
+				continue
+			}
+
+			var rootHash cipher.SHA256
+
+			switch rootHash, err = c.delRootLock(pk, nonce, dr.Seq); err {
+			case nil:
+			case data.ErrNotFound, data.ErrNoSuchFeed, data.ErrNoSuchHead:
+				err = nil
+				continue // removed
+			default:
+				return zero, err
+			}
+
+			if err = c.delRootRelatedValues(rootHash, addZero); err != nil {
+				return zero, err
+			}
+
+		}
+
+	}
+
+	return zero, nil
+}
+
+// remove objects with zero rc from CXDS, the
+// keep is objects that should not be removed
+func (c *Cache) delOwnerless(
+	keys []cipher.SHA256, //             : objects to remove
+	keep map[cipher.SHA256]struct{}, // : provisional objects
+) (
+	err error, //                        : an error
+) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var rc uint32
+
+	for _, key := range keys {
+
+		if _, ok := keep[key]; ok == true {
+			continue // provisional
+		}
+
+		if _, ok := c.is[key]; ok == true {
+			continue // used
+		}
+
+		switch _, rc, err = c.db().Get(key, 0); {
+		case err == data.ErrNotFound:
+			err = nil
+			continue // already removed
+		case err != nil:
+			return
+		case rc > 0:
+			continue // referenced again
+		}
+
+		if err = c.db().Del(key); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
@@ -0,0 +1,114 @@
+package skyobject
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_ApplyRetention(t *testing.T) {
+
+	var conf = getTestConfig()
+	conf.RetentionInterval = 0 // manually
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var (
+		feed   Feed
+		r      = &registry.Root{Pub: pk, Nonce: 1}
+		hashes []cipher.SHA256 // Root objects
+		refs   []cipher.SHA256 // Feed objects
+	)
+
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	for i := 0; i < 5; i++ {
+		var post = Post{Head: fmt.Sprint("head ", i)}
+		assertNil(t, feed.Posts.AppendValues(up, post))
+		assertNil(t, r.Refs[0].SetValue(up, &feed))
+		assertNil(t, c.Save(up, r))
+
+		hashes = append(hashes, r.Hash)
+		refs = append(refs, r.Refs[0].Hash)
+	}
+
+	// zero keeps all
+
+	assertNil(t, c.ApplyRetention())
+
+	var cr *ChainReport
+	cr, err = c.VerifyChain(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, cr.Roots == 5, "Root objects removed")
+
+	var removed = func(t *testing.T, keys ...cipher.SHA256) {
+		t.Helper()
+		for _, key := range keys {
+			_, _, err := c.DB().CXDS().Get(key, 0)
+			assertTrue(t, err == data.ErrNotFound, "not removed from CXDS")
+		}
+	}
+
+	t.Run("keep last", func(t *testing.T) {
+
+		assertTrue(t, c.SetRetention(pk, Retention{KeepLast: -1}) != nil,
+			"missing error")
+
+		assertNil(t, c.SetRetention(pk, Retention{KeepLast: 2}))
+		assertTrue(t, c.Retention(pk) == Retention{KeepLast: 2},
+			"wrong retention")
+
+		assertNil(t, c.ApplyRetention())
+
+		var cr, err = c.VerifyChain(pk, 1)
+		assertNil(t, err)
+
+		assertTrue(t, cr.Roots == 2 && cr.First == 3 && cr.Last == 4,
+			"wrong Root objects kept")
+		assertTrue(t, len(cr.Missing) == 0, "objects of kept Root removed")
+		assertTrue(t, cr.Truncated == true, "not truncated")
+		assertTrue(t, cr.IsValid() == true, "invalid truncated chain")
+
+		removed(t, hashes[:3]...)
+		removed(t, refs[:3]...)
+	})
+
+	t.Run("keep for", func(t *testing.T) {
+
+		assertNil(t, c.SetRetention(pk, Retention{KeepFor: time.Hour}))
+		assertNil(t, c.ApplyRetention())
+
+		var cr, err = c.VerifyChain(pk, 1)
+		assertNil(t, err)
+		assertTrue(t, cr.Roots == 2, "new Root objects removed")
+
+		assertNil(t, c.SetRetention(pk, Retention{KeepFor: time.Nanosecond}))
+		assertNil(t, c.ApplyRetention())
+
+		cr, err = c.VerifyChain(pk, 1)
+		assertNil(t, err)
+
+		assertTrue(t, cr.Roots == 1 && cr.Last == 4, "last Root removed")
+		assertTrue(t, len(cr.Missing) == 0, "objects of last Root removed")
+
+		removed(t, hashes[3], refs[3])
+	})
+
+}
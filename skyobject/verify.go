@@ -0,0 +1,260 @@
+package skyobject
+
+import (
+	"errors"
+	"math"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A ChainGap represents range of seq numbers
+// of Root objects missing in a chain
+type ChainGap struct {
+	From uint64 // first missing seq
+	To   uint64 // last missing seq
+}
+
+// A ChainFork represents Root that doesn't point
+// to previous Root of the chain. The Prev is hash
+// the Root points to, and the Expected is hash of
+// the previous Root (blank for first Root)
+type ChainFork struct {
+	Seq      uint64
+	Prev     cipher.SHA256
+	Expected cipher.SHA256
+}
+
+// A ChainError represents Root that can't be
+// verified: broken signature, encoding, etc
+type ChainError struct {
+	Seq uint64
+	Err string
+}
+
+// A ChainMissing represents Root with
+// objects the DB doesn't have
+type ChainMissing struct {
+	Seq  uint64
+	Keys []cipher.SHA256
+}
+
+// A ChainReport is result of the VerifyChain. If the
+// chain doesn't start from seq 0, then the Truncated
+// is true. Root objects below the First can be removed
+// by Retention or never received, thus they are not
+// reported as a gap
+type ChainReport struct {
+	Feed  cipher.PubKey
+	Nonce uint64
+
+	Roots int    // number of Root objects checked
+	First uint64 // seq of first Root
+	Last  uint64 // seq of last Root
+
+	Truncated bool // Root objects [0, First) missing
+
+	Gaps    []ChainGap     // missing Root objects after the First
+	Forks   []ChainFork    // broken Prev links
+	Invalid []ChainError   // invalid Root objects
+	Missing []ChainMissing // Root objects with missing objects
+}
+
+// IsValid returns true if the chain is continuous
+// (starting from the First), correctly linked, signed
+// and all objects of the Root objects are in DB. A
+// truncated chain can be valid
+func (c *ChainReport) IsValid() bool {
+	return len(c.Gaps) == 0 &&
+		len(c.Forks) == 0 &&
+		len(c.Invalid) == 0 &&
+		len(c.Missing) == 0
+}
+
+// VerifyChain walks through Root objects of given head
+// in IdxDB ascending order. It re-checks hash and signature
+// of every Root against the feed, checks that every Prev
+// hash is hash of previous Root and looks for objects of
+// the Root objects the DB doesn't have. The VerifyChain
+// changes nothing and returns report of the verification.
+// The error is data.ErrNoSuchFeed, data.ErrNoSuchHead or
+// a DB failure. Blank head produces empty report
+func (c *Container) VerifyChain(
+	pk cipher.PubKey, //     : feed
+	nonce uint64, //         : head
+) (
+	cr *ChainReport, //      : the report
+	err error, //            : an error
+) {
+
+	var drs []*data.Root
+
+	if drs, err = c.dataRoots(pk, nonce, 0, math.MaxUint64, 0); err != nil {
+		return
+	}
+
+	cr = &ChainReport{Feed: pk, Nonce: nonce, Roots: len(drs)}
+
+	if len(drs) == 0 {
+		return // blank head
+	}
+
+	cr.First, cr.Last = drs[0].Seq, drs[len(drs)-1].Seq
+
+	var prev *data.Root
+
+	for _, dr := range drs {
+
+		switch {
+
+		case prev == nil && dr.Seq > 0:
+			cr.Truncated = true
+
+		case prev == nil && dr.Prev != (cipher.SHA256{}):
+			cr.Forks = append(cr.Forks, ChainFork{Seq: dr.Seq, Prev: dr.Prev})
+
+		case prev == nil:
+
+		case dr.Seq > prev.Seq+1:
+			cr.Gaps = append(cr.Gaps, ChainGap{prev.Seq + 1, dr.Seq - 1})
+
+		case dr.Prev != prev.Hash:
+			cr.Forks = append(cr.Forks, ChainFork{dr.Seq, dr.Prev, prev.Hash})
+
+		}
+
+		if err = c.verifyRoot(cr, dr); err != nil {
+			return nil, err
+		}
+
+		prev = dr
+	}
+
+	return
+}
+
+// verify Root adding results to given report;
+// it returns DB failures only
+func (c *Container) verifyRoot(cr *ChainReport, dr *data.Root) (err error) {
+
+	var invalid = func(err error) {
+		cr.Invalid = append(cr.Invalid, ChainError{dr.Seq, err.Error()})
+	}
+
+	var val []byte
+
+	switch val, _, err = c.Get(dr.Hash, 0); {
+	case err == data.ErrNotFound:
+		cr.Missing = append(cr.Missing, ChainMissing{dr.Seq,
+			[]cipher.SHA256{dr.Hash}})
+		return nil
+	case err != nil:
+		return
+	}
+
+	if cipher.SumSHA256(val) != dr.Hash {
+		invalid(errors.New("hash of Root doesn't match"))
+		return
+	}
+
+	if err = cipher.VerifySignature(cr.Feed, dr.Sig, dr.Hash); err != nil {
+		invalid(err)
+		return nil
+	}
+
+	var r *registry.Root
+	if r, err = registry.DecodeRoot(val); err != nil {
+		invalid(err)
+		return nil
+	}
+
+	r.Hash = dr.Hash
+
+	switch {
+	case r.Pub != cr.Feed:
+		invalid(errors.New("Root of another feed: " + r.Pub.Hex()))
+		return
+	case r.Nonce != cr.Nonce || r.Seq != dr.Seq:
+		invalid(errors.New("Root of another head or seq: " + r.Short()))
+		return
+	case r.Prev != dr.Prev:
+		invalid(errors.New("Prev of Root doesn't match IdxDB"))
+		return
+	}
+
+	var (
+		keys   []cipher.SHA256
+		broken error
+	)
+
+	if keys, broken, err = c.missingObjects(r); err != nil {
+		return // DB failure
+	}
+
+	if broken != nil {
+		invalid(broken)
+		return
+	}
+
+	if len(keys) > 0 {
+		cr.Missing = append(cr.Missing, ChainMissing{dr.Seq, keys})
+	}
+
+	return
+}
+
+// keys of objects of given Root the DB doesn't have,
+// subtrees of missing objects are not checked; the
+// broken is error of decoding of the Root tree, and
+// the err is a DB failure
+func (c *Container) missingObjects(
+	r *registry.Root, //      : the Root
+) (
+	keys []cipher.SHA256, // : missing
+	broken error, //          : invalid Root tree
+	err error, //             : DB failure
+) {
+
+	if _, _, err = c.Get(cipher.SHA256(r.Reg), 0); err == data.ErrNotFound {
+		return []cipher.SHA256{cipher.SHA256(r.Reg)}, nil, nil
+	} else if err != nil {
+		return
+	}
+
+	var pack *Pack
+	if pack, broken = c.Pack(r, nil); broken != nil {
+		return // can't decode the Registry
+	}
+
+	broken = r.Walk(pack, func(
+		hash cipher.SHA256, // :
+		_ int, //              :
+	) (
+		deepper bool, //       :
+		werr error, //         :
+	) {
+
+		if hash == (cipher.SHA256{}) {
+			return
+		}
+
+		switch _, _, err = c.Get(hash, 0); {
+		case err == data.ErrNotFound:
+			err = nil
+			keys = append(keys, hash)
+			return false, nil // skip the subtree
+		case err != nil:
+			return false, err // DB failure (see below)
+		}
+
+		return true, nil
+	})
+
+	if err != nil {
+		return nil, nil, err // DB failure stops the Walk
+	}
+
+	return
+}
@@ -0,0 +1,123 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_VerifyChain(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var (
+		feed Feed
+		r    = &registry.Root{Pub: pk, Nonce: 1}
+	)
+
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	for i := 0; i < 3; i++ {
+		assertNil(t, feed.Posts.AppendValues(up, Post{Head: "head"}))
+		assertNil(t, r.Refs[0].SetValue(up, &feed))
+		assertNil(t, sc.Save(up, r))
+	}
+
+	var cr *ChainReport
+
+	cr, err = sc.VerifyChain(pk, 1)
+	assertNil(t, err)
+
+	assertTrue(t, cr.IsValid() == true, "invalid chain")
+	assertTrue(t, cr.Roots == 3 && cr.First == 0 && cr.Last == 2,
+		"wrong report")
+
+	t.Run("missing", func(t *testing.T) {
+
+		// the Root and its Registry only
+
+		var val, _, err = sc.Get(r.Hash, 0)
+		assertNil(t, err)
+		_, err = rc.Set(r.Hash, val, 1)
+		assertNil(t, err)
+
+		val, _, err = sc.Get(cipher.SHA256(r.Reg), 0)
+		assertNil(t, err)
+		_, err = rc.Set(cipher.SHA256(r.Reg), val, 1)
+		assertNil(t, err)
+
+		var cp = *r
+		cp.IsFull = true
+		_, err = rc.AddRoot(&cp)
+		assertNil(t, err)
+
+		var cr *ChainReport
+		cr, err = rc.VerifyChain(pk, 1)
+		assertNil(t, err)
+
+		assertTrue(t, cr.Truncated == true && len(cr.Gaps) == 0,
+			"not truncated")
+		assertTrue(t, len(cr.Missing) == 1 && cr.Missing[0].Seq == 2,
+			"missing objects not reported")
+		assertTrue(t, len(cr.Missing[0].Keys) == 1 &&
+			cr.Missing[0].Keys[0] == r.Refs[0].Hash,
+			"wrong missing objects")
+	})
+
+	t.Run("gap", func(t *testing.T) {
+
+		assertNil(t, sc.DelRoot(pk, 1, 1))
+
+		var cr, err = sc.VerifyChain(pk, 1)
+		assertNil(t, err)
+
+		assertTrue(t, len(cr.Gaps) == 1 && cr.Gaps[0] == ChainGap{1, 1},
+			"missing gap")
+		assertTrue(t, len(cr.Forks) == 0, "unexpected fork")
+		assertTrue(t, len(cr.Invalid) == 0, "unexpected invalid Root")
+	})
+
+	t.Run("fork", func(t *testing.T) {
+
+		// signed Root that doesn't point to the previous one
+
+		var fr = *r
+
+		fr.Seq = 3
+		fr.Prev = cipher.SumSHA256([]byte("another chain"))
+
+		var val = fr.Encode()
+
+		fr.Hash = cipher.SumSHA256(val)
+		fr.Sig = cipher.SignHash(fr.Hash, sk)
+		fr.IsFull = true
+
+		var _, err = sc.Set(fr.Hash, val, 1)
+		assertNil(t, err)
+		_, err = sc.AddRoot(&fr)
+		assertNil(t, err)
+
+		var cr *ChainReport
+		cr, err = sc.VerifyChain(pk, 1)
+		assertNil(t, err)
+
+		assertTrue(t, len(cr.Forks) == 1, "missing fork")
+		assertTrue(t, cr.Forks[0] == ChainFork{3, fr.Prev, r.Hash},
+			"wrong fork")
+		assertTrue(t, len(cr.Invalid) == 0, "unexpected invalid Root")
+	})
+
+}